@@ -6,7 +6,8 @@ require periph.io/x/conn/v3 v3.7.0
 
 require (
 	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8
 	periph.io/x/host/v3 v3.8.2
 )
 
-require golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+require github.com/jonboulle/clockwork v0.3.0 // indirect
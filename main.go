@@ -1,13 +1,20 @@
 package main
 
 import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
 	log "github.com/sirupsen/logrus"
 	"periph.io/x/conn/v3/driver/driverreg"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
 	"periph.io/x/conn/v3/spi/spireg"
 	"periph.io/x/host/v3"
-	"periph.io/x/host/v3/rpi"
 
 	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
 	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
 )
 
@@ -19,6 +26,13 @@ var (
 )
 
 func main() {
+	resetPin := flag.String("reset-pin", "GPIO27", "GPIO wired to the SX1302 reset")
+	powerEnPin := flag.String("power-en-pin", "", "GPIO wired to the concentrator power enable (optional)")
+	sx1261ResetPin := flag.String("sx1261-reset-pin", "", "GPIO wired to the SX1261 reset (optional)")
+	irqPin := flag.String("irq-pin", "GPIO17", "GPIO wired to the SX1302 interrupt (optional)")
+	firmwareDir := flag.String("firmware-dir", "", "directory holding firmware files (*.var) of the reference HAL overriding the embedded firmware (optional)")
+	flag.Parse()
+
 	var boardConf model.BoardConf
 	boardConf.LoRaWanPublic = true
 	boardConf.ClkSrc = 0
@@ -51,13 +65,54 @@ func main() {
 	}
 	defer port.Close()
 
-	lora := sx1302.NewSX1302Device(
+	pins := commands.Pins{
+		Reset:       lookupPin(*resetPin),
+		PowerEnable: lookupPin(*powerEnPin),
+		SX1261Reset: lookupPin(*sx1261ResetPin),
+		IRQ:         lookupPin(*irqPin),
+	}
+
+	opts := []sx1302.SX1302Config{
 		sx1302.WithBoardConfig(&boardConf),
 		sx1302.WithRfRxConfig(0, &rfConf),
-		sx1302.WithSPIPort(port, rpi.P1_13, rpi.P1_11),
-	)
+		sx1302.WithSPIPort(port, pins),
+	}
+
+	if *firmwareDir != "" {
+		firmware, err := commands.LoadFirmwareDir(*firmwareDir)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"firmware_dir": *firmwareDir,
+			}).Fatal(err)
+		}
+		opts = append(opts, sx1302.WithFirmware(firmware))
+	}
+
+	lora := sx1302.NewSX1302Device(opts...)
 
 	if err := lora.Start(); err != nil {
 		log.Fatal(err)
 	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	if err := lora.Stop(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// lookupPin returns the GPIO pin with the given name, or nil if no name was given
+func lookupPin(name string) gpio.PinIO {
+	if name == "" {
+		return nil
+	}
+
+	pin := gpioreg.ByName(name)
+	if pin == nil {
+		log.WithField("pin", name).Fatal("unknown gpio pin")
+	}
+
+	return pin
 }
@@ -0,0 +1,120 @@
+package sx1302
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// loraLowDatarateSymbol is the symbol duration from which on the low datarate optimisation is used
+const loraLowDatarateSymbol = 16 * time.Millisecond
+
+// TimeOnAir returns the time on air of pkt. FSK packets carry the sync word of the FSK channel, syncWordSize is its
+// size in bytes. A Preamble of 0 is replaced by the default preamble like Send does.
+func TimeOnAir(pkt model.PktTx, syncWordSize uint8) (time.Duration, error) {
+	switch pkt.Modulation {
+	case model.ModLora:
+		preamble := pkt.Preamble
+		if preamble == 0 {
+			preamble = loraPreambleStd
+		}
+		preamble = max(preamble, loraPreambleMin)
+
+		return loraTimeOnAir(model.Bandwith(pkt.Bandwidth), model.DataRate(pkt.Datarate), pkt.Coderate, preamble,
+			!pkt.NoHeader, !pkt.NoCrc, pkt.Size)
+
+	case model.ModFsk:
+		preamble := pkt.Preamble
+		if preamble == 0 {
+			preamble = fskPreambleStd
+		}
+		preamble = max(preamble, fskPreambleMin)
+
+		return fskTimeOnAir(model.DataRate(pkt.Datarate), preamble, syncWordSize, !pkt.NoHeader, !pkt.NoCrc, pkt.Size)
+	}
+
+	return 0, fmt.Errorf("unsupported modulation 0x%02X", pkt.Modulation)
+}
+
+// RxTimeOnAir returns the time on air of the received pkt. The preamble is assumed to have the default length and
+// LoRa packets to have an explicit header, as the demodulators do not report them. FSK packets are received with the
+// variable length of the FSK channel and its sync word of syncWordSize bytes.
+func RxTimeOnAir(pkt model.PktRx, syncWordSize uint8) (time.Duration, error) {
+	crc := pkt.Status != model.StatNoCrc
+
+	switch pkt.Modulation {
+	case model.ModLora:
+		return loraTimeOnAir(model.Bandwith(pkt.Bandwidth), model.DataRate(pkt.Datarate), pkt.Coderate, loraPreambleStd,
+			true, crc, pkt.Size)
+
+	case model.ModFsk:
+		return fskTimeOnAir(model.DataRate(pkt.Datarate), fskPreambleStd, syncWordSize, true, crc, pkt.Size)
+	}
+
+	return 0, fmt.Errorf("unsupported modulation 0x%02X", pkt.Modulation)
+}
+
+// loraTimeOnAir returns the time on air of a LoRa packet, preamble in symbols and size in bytes
+func loraTimeOnAir(bw model.Bandwith, sf model.DataRate, cr uint8, preamble uint16, header bool, crc bool, size uint16) (time.Duration, error) {
+	bwHz := int64(bw.Hz())
+	if bwHz == 0 {
+		return 0, fmt.Errorf("invalid lora bandwidth 0x%02X", uint8(bw))
+	}
+
+	if sf < model.DrLoraSf5 || sf > model.DrLoraSf12 {
+		return 0, fmt.Errorf("invalid lora datarate %d", sf)
+	}
+
+	if cr < model.CrLora45 || cr > model.CrLora48 {
+		return 0, fmt.Errorf("invalid lora coding rate %d", cr)
+	}
+
+	// durations are counted in quarter symbols to keep the 4.25 symbols of the sync word exact
+	symbol := time.Duration(int64(1)<<sf) * time.Second / time.Duration(bwHz)
+
+	var de int64
+	if symbol >= loraLowDatarateSymbol {
+		de = 1
+	}
+
+	// SF5 and SF6 use two more sync symbols and no extra payload bits
+	quarters := 4*int64(preamble) + 17 + 4*8
+	bits := 8*int64(size) - 4*int64(sf) + 8
+	if sf <= model.DrLoraSf6 {
+		quarters += 4 * 2
+		bits -= 8
+	}
+	if crc {
+		bits += 16
+	}
+	if header {
+		bits += 20
+	}
+
+	if bits > 0 {
+		perBlock := 4 * (int64(sf) - 2*de)
+		blocks := (bits + perBlock - 1) / perBlock
+		quarters += 4 * blocks * (int64(cr) + 4)
+	}
+
+	return time.Duration(quarters) * time.Duration(int64(1)<<sf) * time.Second / time.Duration(4*bwHz), nil
+}
+
+// fskTimeOnAir returns the time on air of a FSK packet, preamble, sync word and size in bytes. Packets with header
+// start with a length byte, packets with CRC end with 2 CRC bytes.
+func fskTimeOnAir(datarate model.DataRate, preamble uint16, syncWordSize uint8, header bool, crc bool, size uint16) (time.Duration, error) {
+	if datarate < model.DrFskMin || datarate > model.DrFskMax {
+		return 0, fmt.Errorf("invalid fsk datarate %s, must be between %s and %s", datarate, model.DrFskMin, model.DrFskMax)
+	}
+
+	bytes := int64(preamble) + int64(syncWordSize) + int64(size)
+	if header {
+		bytes++
+	}
+	if crc {
+		bytes += 2
+	}
+
+	return time.Duration(8*bytes) * time.Second / time.Duration(datarate), nil
+}
@@ -0,0 +1,125 @@
+package sx1302_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// loraPkt returns a LoRa packet to transmit
+func loraPkt(bw model.Bandwith, sf model.DataRate, cr uint8, preamble uint16, implicit bool, noCrc bool, size uint16) model.PktTx {
+	return model.PktTx{
+		Modulation: model.ModLora,
+		Bandwidth:  uint8(bw),
+		Datarate:   uint32(sf),
+		Coderate:   cr,
+		Preamble:   preamble,
+		NoHeader:   implicit,
+		NoCrc:      noCrc,
+		Size:       size,
+	}
+}
+
+// fskPkt returns a FSK packet to transmit
+func fskPkt(datarate model.DataRate, preamble uint16, noHeader bool, noCrc bool, size uint16) model.PktTx {
+	return model.PktTx{
+		Modulation: model.ModFsk,
+		Datarate:   uint32(datarate),
+		Preamble:   preamble,
+		NoHeader:   noHeader,
+		NoCrc:      noCrc,
+		Size:       size,
+	}
+}
+
+// The expected durations follow the time-on-air formulas of the SX1261/2 datasheet implemented by the Semtech LoRa
+// calculator: SF5 and SF6 have 6.25 sync symbols and no extra payload bits, the low datarate optimisation is used from
+// a symbol duration of 16.384ms on. The SF7 and SF12 packets of 64 bytes are the 51 byte LoRaWAN payloads of the
+// calculator, 118.016ms and 2793.472ms.
+func TestTimeOnAir(t *testing.T) {
+	ms := func(f float64) time.Duration { return time.Duration(f * float64(time.Millisecond)) }
+
+	tests := []struct {
+		name         string
+		pkt          model.PktTx
+		syncWordSize uint8
+		want         time.Duration
+	}{
+		{"SF7 125kHz", loraPkt(model.Bw125kHz, model.DrLoraSf7, model.CrLora45, 8, false, false, 64), 0, ms(118.016)},
+		{"SF12 125kHz LDRO", loraPkt(model.Bw125kHz, model.DrLoraSf12, model.CrLora45, 8, false, false, 64), 0, ms(2793.472)},
+		{"SF11 125kHz LDRO", loraPkt(model.Bw125kHz, model.DrLoraSf11, model.CrLora45, 8, false, false, 20), 0, ms(741.376)},
+		{"SF10 125kHz", loraPkt(model.Bw125kHz, model.DrLoraSf10, model.CrLora45, 8, false, false, 20), 0, ms(370.688)},
+		{"SF12 250kHz LDRO", loraPkt(model.Bw250kHz, model.DrLoraSf12, model.CrLora45, 8, false, false, 20), 0, ms(659.456)},
+		{"SF12 500kHz", loraPkt(model.Bw500kHz, model.DrLoraSf12, model.CrLora45, 8, false, false, 20), 0, ms(329.728)},
+		{"SF8 250kHz CR4/6", loraPkt(model.Bw250kHz, model.DrLoraSf8, model.CrLora46, 12, false, false, 30), 0, ms(73.984)},
+		{"SF5 500kHz", loraPkt(model.Bw500kHz, model.DrLoraSf5, model.CrLora45, 8, false, false, 10), 0, ms(3.024)},
+		{"SF6 implicit header CR4/8", loraPkt(model.Bw125kHz, model.DrLoraSf6, model.CrLora48, 8, true, true, 10), 0, ms(23.68)},
+		{"SF5 implicit header empty", loraPkt(model.Bw125kHz, model.DrLoraSf5, model.CrLora45, 8, true, true, 0), 0, ms(5.696)},
+		{"SF9 implicit header", loraPkt(model.Bw125kHz, model.DrLoraSf9, model.CrLora45, 8, true, true, 12), 0, ms(123.904)},
+		{"SF12 implicit header empty", loraPkt(model.Bw125kHz, model.DrLoraSf12, model.CrLora45, 8, true, true, 0), 0, ms(663.552)},
+		{"default lora preamble", loraPkt(model.Bw125kHz, model.DrLoraSf7, model.CrLora45, 0, false, false, 64), 0, ms(118.016)},
+		{"FSK 50kbps", fskPkt(50000, 5, false, false, 20), 3, ms(4.96)},
+		{"FSK 50kbps no header no crc", fskPkt(50000, 3, true, true, 20), 2, ms(4)},
+		{"FSK 1.2kbps", fskPkt(1200, 5, false, false, 255), 3, 1773333333 * time.Nanosecond},
+		{"default fsk preamble", fskPkt(50000, 0, false, false, 20), 3, ms(4.96)},
+	}
+
+	for _, tt := range tests {
+		got, err := sx1302.TimeOnAir(tt.pkt, tt.syncWordSize)
+		if err != nil {
+			t.Errorf("%s: TimeOnAir() failed: %v", tt.name, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%s: TimeOnAir() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRxTimeOnAir(t *testing.T) {
+	lora := model.PktRx{
+		Modulation: model.ModLora,
+		Bandwidth:  uint8(model.Bw125kHz),
+		Datarate:   uint32(model.DrLoraSf12),
+		Coderate:   model.CrLora45,
+		Status:     model.StatCrcOk,
+		Size:       64,
+	}
+
+	if got, err := sx1302.RxTimeOnAir(lora, 0); err != nil || got != 2793472*time.Microsecond {
+		t.Errorf("RxTimeOnAir() of a SF12 packet = %v, %v, want 2.793472s", got, err)
+	}
+
+	fsk := model.PktRx{
+		Modulation: model.ModFsk,
+		Datarate:   50000,
+		Status:     model.StatNoCrc,
+		Size:       20,
+	}
+
+	if got, err := sx1302.RxTimeOnAir(fsk, 3); err != nil || got != 4640*time.Microsecond {
+		t.Errorf("RxTimeOnAir() of a FSK packet without CRC = %v, %v, want 4.64ms", got, err)
+	}
+}
+
+func TestTimeOnAirInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		pkt  model.PktTx
+	}{
+		{"lora bandwidth", loraPkt(0, model.DrLoraSf7, model.CrLora45, 8, false, false, 10)},
+		{"lora datarate", loraPkt(model.Bw125kHz, 13, model.CrLora45, 8, false, false, 10)},
+		{"lora coding rate", loraPkt(model.Bw125kHz, model.DrLoraSf7, model.CrUndefined, 8, false, false, 10)},
+		{"fsk datarate", fskPkt(100, 5, false, false, 10)},
+		{"modulation", model.PktTx{Modulation: model.ModUndefined}},
+	}
+
+	for _, tt := range tests {
+		if got, err := sx1302.TimeOnAir(tt.pkt, 3); err == nil {
+			t.Errorf("TimeOnAir() with an invalid %s = %v, want an error", tt.name, got)
+		}
+	}
+}
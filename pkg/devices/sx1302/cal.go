@@ -0,0 +1,67 @@
+package sx1302
+
+import (
+	"fmt"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx125x"
+)
+
+// needsCalibration returns whether an enabled rf chain has a SX1255 or SX1257 radio, whose I/Q offsets are calibrated
+// during the start
+func (d *Dev) needsCalibration() bool {
+	for _, rf := range d.context.RfChainCfg {
+		if rf.Enable && (rf.Type == model.RadioTypeSX1255 || rf.Type == model.RadioTypeSX1257) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// calibrateRadios runs the calibration firmware on the AGC MCU to calibrate the I/Q offsets of the SX1255 and SX1257
+// radios. The RX offsets are written to the radio front-end, the TX offsets to the entries of the TX gain LUTs.
+func (d *Dev) calibrateRadios() error {
+	if !d.needsCalibration() {
+		return nil
+	}
+
+	var radios [model.MaxRfChains]commands.CalRadio
+	for i, rf := range d.context.RfChainCfg {
+		if rf.Enable && rf.Type != model.RadioTypeSX1250 {
+			radios[i] = commands.CalRadio{Enable: true, TxEnable: rf.TxEnable, Type: rf.Type}
+		}
+	}
+
+	if err := d.regs.LoadFirmware(commands.MCUAgc, d.firmware.Cal); err != nil {
+		return fmt.Errorf("failed to load the calibration firmware: %w", err)
+	}
+
+	results, err := d.regs.CalStart(d.firmware.Cal.Version, radios)
+	if err != nil {
+		return fmt.Errorf("failed to calibrate the radios: %w", err)
+	}
+
+	for i, radio := range radios {
+		if !radio.Enable {
+			continue
+		}
+
+		regs := rfChainRegs[i]
+		if err := d.writeRegs([]regWrite{
+			{regs.dcOffsetI, int32(results[i].Rx.I)},
+			{regs.dcOffsetQ, int32(results[i].Rx.Q)},
+		}); err != nil {
+			return fmt.Errorf("failed to write the rx offsets of rf chain %d: %w", i, err)
+		}
+
+		if radio.TxEnable {
+			if err := sx125x.ApplyTxOffsets(&d.context.TxGainLUT[i], results[i].Tx); err != nil {
+				return fmt.Errorf("rf chain %d: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package sx1302_test
+
+import (
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// newCalDev returns a device with a SX1257 on rf chain 0 whose TX path is calibrated for the entries of lut
+func newCalDev(t *testing.T, lut *model.TxGainLUT) (*sx1302.Dev, *sx1302test.Sim, commands.FirmwareSet) {
+	t.Helper()
+
+	sim := sx1302test.NewSim()
+	sim.OnRadio = (&sx1302test.SX125x{}).Handle
+
+	firmware := testFirmware()
+	sim.CalFirmware = firmware.Cal.Image
+	sim.AGCVersion = commands.AGCFirmwareVersionSX125x
+
+	rf := model.NewRxRfConf()
+	rf.Type = model.RadioTypeSX1257
+	rf.TxEnable = true
+
+	d := sx1302.NewSX1302Device(
+		sx1302.WithBoardConfig(model.NewBoardConfig()),
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithTxGainLUT(0, lut),
+		sx1302.WithSPIPort(sim, commands.Pins{Reset: sim.ResetPin()}),
+		sx1302.WithFirmware(firmware),
+	)
+
+	return d, sim, firmware
+}
+
+func TestStartCalibratesSX125x(t *testing.T) {
+	lut := &model.TxGainLUT{LUT: []model.TXGain{
+		{RfPower: 0, PaGain: 0, DacGain: 3, MixGain: 8},
+		{RfPower: 10, PaGain: 1, DacGain: 3, MixGain: 8},
+		{RfPower: 14, PaGain: 1, DacGain: 3, MixGain: 12},
+	}}
+	d, sim, firmware := newCalDev(t, lut)
+
+	// the firmware finds offsets depending on the mixer gain
+	result := commands.CalResult{Rx: commands.IQOffset{I: -12, Q: 5}}
+	for gain := range result.Tx {
+		result.Tx[gain] = commands.IQOffset{I: int8(gain) - 40, Q: 3 - int8(gain)}
+	}
+	sim.CalResults[0] = result
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop()
+
+	// rx and tx of radio A calibrated with the dac gain 3
+	if got := sim.CalCommand(); got != 0x15 {
+		t.Errorf("calibration command = 0x%02X, want 0x15", got)
+	}
+
+	gotI, gotQ := sim.Reg(commands.RegRadioFeDcOffsetIRadioADcOffsetI), sim.Reg(commands.RegRadioFeDcOffsetQRadioADcOffsetQ)
+	if gotI != int32(result.Rx.I) || gotQ != int32(result.Rx.Q) {
+		t.Errorf("rx offsets = (%d, %d), want (%d, %d)", gotI, gotQ, result.Rx.I, result.Rx.Q)
+	}
+
+	for i, gain := range d.TxGainLUT(0).LUT {
+		if want := result.Tx[gain.MixGain]; gain.OffsetI != want.I || gain.OffsetQ != want.Q {
+			t.Errorf("tx gain lut entry %d offsets = (%d, %d), want (%d, %d)", i, gain.OffsetI, gain.OffsetQ, want.I, want.Q)
+		}
+	}
+
+	// the AGC firmware replaced the calibration firmware
+	if got := sim.Mem(commands.AGCMemAddr, commands.MCUMemSize); string(got) != string(firmware.AGCSX125x.Image) {
+		t.Error("the agc runs the calibration firmware after Start")
+	}
+}
+
+func TestStartCalibrationStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures uint8
+		ok       bool
+	}{
+		{"no register access", 0x01, false},
+		{"no access to radio A", 0x02, false},
+		{"rx not calibrated", 0x08, true},
+		{"tx not calibrated", 0x20, true},
+	}
+
+	for _, tt := range tests {
+		d, sim, _ := newCalDev(t, &model.TxGainLUT{LUT: []model.TXGain{{DacGain: 3, MixGain: 8}}})
+		sim.CalFailures = tt.failures
+
+		err := d.Start()
+		if err == nil {
+			d.Stop()
+		}
+
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: Start() = %v, want success %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestStartWithoutCalibrationFirmware(t *testing.T) {
+	sim := sx1302test.NewSim()
+	rf := model.NewRxRfConf()
+	rf.Type = model.RadioTypeSX1257
+
+	firmware := testFirmware()
+	firmware.Cal = commands.Firmware{}
+
+	d := sx1302.NewSX1302Device(
+		sx1302.WithBoardConfig(model.NewBoardConfig()),
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithSPIPort(sim, commands.Pins{Reset: sim.ResetPin()}),
+		sx1302.WithFirmware(firmware),
+	)
+
+	if err := d.Start(); err == nil {
+		d.Stop()
+		t.Fatal("Start() succeeded without a calibration firmware for a SX1257")
+	}
+}
@@ -0,0 +1,278 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// Handshake of the calibration firmware, following sx1302_cal_start of the reference HAL. The firmware publishes its
+// version and waits for the calibration command in mailbox 0, then calibrates the radios on its own and reports the
+// outcome in its status. The host reads back the offsets found one at a time: it writes the rf chain and the index of
+// the result to the mailboxes 0 and 1 and notifies calNotifyRead, the firmware answers with the I and Q offsets in the
+// mailboxes 0 and 1 and echoes the rf chain and the index, marked with calReadValid, in the mailboxes 2 and 3.
+const (
+	calStatusStarted uint8 = 0x01
+	calNotifyStart   uint8 = 0x01
+	calNotifyRead    uint8 = 0x02
+	calReadValid     uint8 = 0x80
+
+	// calTimeout is the longest time the calibration of both radios takes
+	calTimeout = 3 * time.Second
+)
+
+// Bits of the calibration command
+const (
+	calCmdRxA     uint8 = 1 << 0
+	calCmdRxB     uint8 = 1 << 1
+	calCmdTxA     uint8 = 1 << 2
+	calCmdTxB     uint8 = 1 << 3
+	calCmdDacGain uint8 = 1 << 4
+	calCmdSX1255A uint8 = 1 << 5
+	calCmdSX1255B uint8 = 1 << 6
+)
+
+// Bits of the status reported at the end of the calibration
+const (
+	calStatusRegAccess uint8 = 1 << 0
+	calStatusRadioA    uint8 = 1 << 1
+	calStatusRadioB    uint8 = 1 << 2
+	calStatusRxA       uint8 = 1 << 3
+	calStatusRxB       uint8 = 1 << 4
+	calStatusTxA       uint8 = 1 << 5
+	calStatusTxB       uint8 = 1 << 6
+	calStatusFinished  uint8 = 1 << 7
+)
+
+const (
+	// CalTxDacGain is the DAC gain the TX offsets are calibrated with
+	CalTxDacGain uint8 = 3
+
+	// CalMixGains is the number of mixer gains the TX offsets are calibrated for
+	CalMixGains = 16
+
+	// calResultRx is the index of the RX offsets, the TX offsets of mixer gain g follow at calResultTx + g
+	calResultRx uint8 = 0
+	calResultTx uint8 = 1
+)
+
+// CalRadio configures the calibration of the radio of a rf chain
+type CalRadio struct {
+	// Enable calibrates the RX path of the radio
+	Enable bool
+
+	// TxEnable also calibrates the TX path of the radio
+	TxEnable bool
+
+	// Type of the radio, SX1255 or SX1257
+	Type model.RadioType
+}
+
+// IQOffset is an offset correction of the I and Q paths of a radio
+type IQOffset struct {
+	I int8
+	Q int8
+}
+
+// CalResult are the offsets found by the calibration firmware for the radio of a rf chain
+type CalResult struct {
+	// Rx cancels the DC residual of the RX path
+	Rx IQOffset
+
+	// Tx cancels the carrier leakage of the TX path for each mixer gain, with the DAC gain CalTxDacGain
+	Tx [CalMixGains]IQOffset
+}
+
+// CalStatusError is returned if the calibration firmware could not calibrate a radio
+type CalStatusError struct {
+	RfChain int
+	Status  uint8
+	Reason  string
+}
+
+func (e *CalStatusError) Error() string {
+	if e.RfChain < 0 {
+		return fmt.Sprintf("sx1302 lowlevel: calibration failed with status 0x%02X: %s", e.Status, e.Reason)
+	}
+
+	return fmt.Sprintf("sx1302 lowlevel: calibration of rf chain %d failed with status 0x%02X: %s", e.RfChain, e.Status, e.Reason)
+}
+
+// calCommand returns the calibration command of radios
+func calCommand(radios [model.MaxRfChains]CalRadio) (uint8, error) {
+	cmd := calCmdDacGain
+	for i, radio := range radios {
+		if !radio.Enable {
+			continue
+		}
+
+		switch radio.Type {
+		case model.RadioTypeSX1255:
+			cmd |= calCmdSX1255A << i
+		case model.RadioTypeSX1257:
+		default:
+			return 0, wrapf("rf chain %d: radio type %s cannot be calibrated", i, radio.Type)
+		}
+
+		cmd |= calCmdRxA << i
+		if radio.TxEnable {
+			cmd |= calCmdTxA << i
+		}
+	}
+
+	return cmd, nil
+}
+
+// CalStart runs the calibration firmware loaded into the AGC MCU: it waits for the firmware to start, checks its
+// version, starts the calibration of radios and waits for its end. The offsets found for the enabled radios are read
+// back, the TX offsets only for radios with TxEnable set.
+func (r *Registers) CalStart(version uint8, radios [model.MaxRfChains]CalRadio) ([model.MaxRfChains]CalResult, error) {
+	var results [model.MaxRfChains]CalResult
+
+	cmd, err := calCommand(radios)
+	if err != nil {
+		return results, err
+	}
+
+	if err := r.waitMCUStatus(MCUAgc, calStatusStarted); err != nil {
+		return results, err
+	}
+
+	got, err := r.agcMailboxRead(0)
+	if err != nil {
+		return results, err
+	}
+
+	if got != version {
+		return results, &FirmwareVersionError{MCU: MCUAgc, Expected: version, Got: got}
+	}
+
+	if err := r.agcMailboxWrite(0, cmd); err != nil {
+		return results, err
+	}
+
+	if err := r.agcMailboxWrite(agcMailboxNotify, calNotifyStart); err != nil {
+		return results, err
+	}
+
+	status, err := r.waitCalFinished()
+	if err != nil {
+		return results, err
+	}
+
+	if err := checkCalStatus(status, radios); err != nil {
+		return results, err
+	}
+
+	for i, radio := range radios {
+		if !radio.Enable {
+			continue
+		}
+
+		if results[i].Rx, err = r.calRead(uint8(i), calResultRx); err != nil {
+			return results, err
+		}
+
+		if !radio.TxEnable {
+			continue
+		}
+
+		for gain := range results[i].Tx {
+			if results[i].Tx[gain], err = r.calRead(uint8(i), calResultTx+uint8(gain)); err != nil {
+				return results, err
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// waitCalFinished polls the status of the calibration firmware until it reports the end of the calibration or
+// calTimeout expired
+func (r *Registers) waitCalFinished() (uint8, error) {
+	deadline := time.Now().Add(calTimeout)
+	for {
+		got, err := r.RegRead(mcus[MCUAgc].status)
+		if err != nil {
+			return 0, err
+		}
+
+		if status := uint8(got); status&calStatusFinished != 0 {
+			return status, nil
+		} else if time.Now().After(deadline) {
+			return 0, &MCUTimeoutError{MCU: MCUAgc, Expected: calStatusFinished, Last: status}
+		}
+
+		time.Sleep(mcuPollInterval)
+	}
+}
+
+// checkCalStatus checks that the calibration reported by status reached every radio. Like the reference HAL, a radio
+// whose offsets could not be calibrated is only warned about, it runs with the offsets found.
+func checkCalStatus(status uint8, radios [model.MaxRfChains]CalRadio) error {
+	if status&calStatusRegAccess == 0 {
+		return &CalStatusError{RfChain: -1, Status: status, Reason: "the firmware could not access the registers"}
+	}
+
+	for i, radio := range radios {
+		if !radio.Enable {
+			continue
+		}
+
+		if status&(calStatusRadioA<<i) == 0 {
+			return &CalStatusError{RfChain: i, Status: status, Reason: "the firmware could not access the radio"}
+		}
+
+		if status&(calStatusRxA<<i) == 0 {
+			log.WithFields(log.Fields{"rf_chain": i, "status": fmt.Sprintf("0x%02X", status)}).Warn("Rx calibration failed")
+		}
+
+		if radio.TxEnable && status&(calStatusTxA<<i) == 0 {
+			log.WithFields(log.Fields{"rf_chain": i, "status": fmt.Sprintf("0x%02X", status)}).Warn("Tx calibration failed")
+		}
+	}
+
+	return nil
+}
+
+// calRead reads back the result index of the calibration of rfChain
+func (r *Registers) calRead(rfChain uint8, index uint8) (IQOffset, error) {
+	if err := r.agcMailboxWrite(0, rfChain); err != nil {
+		return IQOffset{}, err
+	}
+
+	if err := r.agcMailboxWrite(1, index); err != nil {
+		return IQOffset{}, err
+	}
+
+	if err := r.agcMailboxWrite(agcMailboxNotify, calNotifyRead); err != nil {
+		return IQOffset{}, err
+	}
+
+	// the firmware echoes the request once the offsets are in the mailboxes
+	deadline := time.Now().Add(MCUTimeout)
+	for {
+		echo, err := r.RegReadBatch([]RegID{
+			RegAgcMcuMcuMailBoxRdDataByte0McuMailBoxRdData,
+			RegAgcMcuMcuMailBoxRdDataByte1McuMailBoxRdData,
+			RegAgcMcuMcuMailBoxRdDataByte2McuMailBoxRdData,
+			RegAgcMcuMcuMailBoxRdDataByte3McuMailBoxRdData,
+		})
+		if err != nil {
+			return IQOffset{}, err
+		}
+
+		if uint8(echo[2]) == rfChain && uint8(echo[3]) == index|calReadValid {
+			return IQOffset{I: int8(echo[0]), Q: int8(echo[1])}, nil
+		}
+
+		if time.Now().After(deadline) {
+			return IQOffset{}, wrapf("timeout reading back calibration result %d of rf chain %d", index, rfChain)
+		}
+
+		time.Sleep(mcuPollInterval)
+	}
+}
@@ -0,0 +1,633 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// Firmware versions supported by this driver
+const (
+	AGCFirmwareVersionSX1250 uint8 = 10
+	AGCFirmwareVersionSX125x uint8 = 6
+	ARBFirmwareVersion       uint8 = 2
+	CalFirmwareVersion       uint8 = 1
+)
+
+// Names of the firmware files as published with the Semtech reference HAL
+const (
+	AGCFirmwareFileSX1250 = "agc_fw_sx1250.var"
+	AGCFirmwareFileSX125x = "agc_fw_sx1257.var"
+	ARBFirmwareFile       = "arb_fw.var"
+	CalFirmwareFile       = "cal_fw.var"
+)
+
+const (
+	// mcuPollInterval is the interval the status of a MCU is polled at during the handshake
+	mcuPollInterval = time.Millisecond
+
+	// MCUTimeout is the longest time to wait for a MCU to acknowledge a step of the handshake
+	MCUTimeout = time.Second
+)
+
+// Handshake of the AGC firmware, following sx1302_agc_start of the reference HAL. The host writes the parameters of a
+// step to the mailboxes 0 to 2 and notifies the firmware through mailbox 3, the firmware acknowledges by changing its
+// status and echoing the applied parameters. The steps after the radio gains are acknowledged with the status
+// following their notification, the LBT step with agcStatusConfigured.
+const (
+	agcStatusStarted    uint8 = 0x01
+	agcStatusRadioADone uint8 = 0x02
+	agcStatusRadioBDone uint8 = 0x03
+	agcStatusConfigured uint8 = 0x0F
+
+	agcNotifyRadioA       uint8 = 0x80
+	agcNotifyRadioB       uint8 = 0x20
+	agcNotifyAnaGain      uint8 = 0x03
+	agcNotifyAnaThresh    uint8 = 0x04
+	agcNotifyDecAttn      uint8 = 0x05
+	agcNotifyDecThresh    uint8 = 0x06
+	agcNotifyChanAttn     uint8 = 0x07
+	agcNotifyChanThresh   uint8 = 0x08
+	agcNotifyPAConfig     uint8 = 0x09
+	agcNotifyPAStartDelay uint8 = 0x0A
+	agcNotifyLBT          uint8 = 0x0B
+	agcNotifyDone         uint8 = 0x0F
+
+	agcMailboxCount  uint8 = 4
+	agcMailboxNotify uint8 = 3
+
+	// agcPAStartDelay is the delay between the PA enable and the start of a TX, in steps of 100us
+	agcPAStartDelay uint8 = 8
+)
+
+// Handshake of the ARB firmware through its debug configuration and status registers
+const (
+	arbStatusStarted     uint8 = 0x01
+	arbStatusRunning     uint8 = 0x00
+	arbDebugCfgResume    uint8 = 1
+	arbDebugCfgFilter    uint8 = 2
+	arbDebugCfgDoubleDem uint8 = 3
+	arbDebugStsVersion   uint8 = 0
+
+	// arbDoubleDetectFilter is the number of symbols two detections of the same packet may be apart to be filtered
+	arbDoubleDetectFilter uint8 = 3
+
+	// arbDoubleDemodNone, arbDoubleDemodHighCap and arbDoubleDemodAllSf enable the double demodulation per spreading
+	// factor, one bit per SF from SF5 in the LSB to SF12
+	arbDoubleDemodNone    uint8 = 0x00
+	arbDoubleDemodHighCap uint8 = 0x3F
+	arbDoubleDemodAllSf   uint8 = 0xFF
+)
+
+// MCU identifies one of the two micro-controllers of the SX1302
+type MCU int
+
+const (
+	// MCUAgc is the automatic gain control MCU
+	MCUAgc MCU = iota
+
+	// MCUArb is the arbiter MCU allocating the demodulators
+	MCUArb
+)
+
+func (m MCU) String() string {
+	switch m {
+	case MCUAgc:
+		return "AGC"
+	case MCUArb:
+		return "ARB"
+	}
+
+	return "Unknown"
+}
+
+// Firmware is a firmware image of one of the MCUs
+type Firmware struct {
+	// Image is the content of the MCU memory, MCUMemSize bytes
+	Image []byte
+
+	// Version is the version reported by the firmware during the handshake
+	Version uint8
+}
+
+// FirmwareSet holds the firmware images of both MCUs for all supported radios
+type FirmwareSet struct {
+	AGCSX1250 Firmware
+	AGCSX125x Firmware
+	ARB       Firmware
+
+	// Cal is the calibration firmware run by the AGC MCU to calibrate the I/Q offsets of SX1255 and SX1257 radios
+	Cal Firmware
+}
+
+// AGC returns the AGC firmware matching radioType
+func (s FirmwareSet) AGC(radioType model.RadioType) (Firmware, error) {
+	switch radioType {
+	case model.RadioTypeSX1250:
+		return s.AGCSX1250, nil
+	case model.RadioTypeSX1255, model.RadioTypeSX1257:
+		return s.AGCSX125x, nil
+	}
+
+	return Firmware{}, wrapf("no agc firmware for radio type %s", radioType)
+}
+
+// FirmwareVerifyError is returned if the firmware read back from the MCU memory differs from the uploaded image
+type FirmwareVerifyError struct {
+	MCU    MCU
+	Offset int
+	Wrote  byte
+	Read   byte
+}
+
+func (e *FirmwareVerifyError) Error() string {
+	return fmt.Sprintf("sx1302 lowlevel: %s firmware verification failed at offset 0x%04X, wrote 0x%02X, read 0x%02X", e.MCU, e.Offset, e.Wrote, e.Read)
+}
+
+// FirmwareParityError is returned if the MCU reports a parity error of its memory after the upload
+type FirmwareParityError struct {
+	MCU MCU
+}
+
+func (e *FirmwareParityError) Error() string {
+	return fmt.Sprintf("sx1302 lowlevel: %s firmware parity check failed", e.MCU)
+}
+
+// FirmwareVersionError is returned if the firmware reports a different version than expected
+type FirmwareVersionError struct {
+	MCU      MCU
+	Expected uint8
+	Got      uint8
+}
+
+func (e *FirmwareVersionError) Error() string {
+	return fmt.Sprintf("sx1302 lowlevel: %s firmware version mismatch, expected %d, got %d", e.MCU, e.Expected, e.Got)
+}
+
+// MCUTimeoutError is returned if a MCU did not reach the expected status of the handshake within MCUTimeout
+type MCUTimeoutError struct {
+	MCU      MCU
+	Expected uint8
+	Last     uint8
+}
+
+func (e *MCUTimeoutError) Error() string {
+	return fmt.Sprintf("sx1302 lowlevel: timeout waiting for %s status 0x%02X, last status 0x%02X", e.MCU, e.Expected, e.Last)
+}
+
+// MCUConfigError is returned if the AGC did not take over a setting written to its mailbox
+type MCUConfigError struct {
+	MCU      MCU
+	Setting  string
+	Expected uint8
+	Got      uint8
+}
+
+func (e *MCUConfigError) Error() string {
+	return fmt.Sprintf("sx1302 lowlevel: %s did not apply %s, expected %d, got %d", e.MCU, e.Setting, e.Expected, e.Got)
+}
+
+// AGCConfig configures the AGC firmware
+type AGCConfig struct {
+	// RadioType is the type of the radios controlled by the AGC
+	RadioType model.RadioType
+
+	// AnaGain forces the analog gain of the radios, 0 for automatic gain control
+	AnaGain uint8
+
+	// DecGain forces the attenuation of the decimator, 0 for automatic gain control
+	DecGain uint8
+
+	// FullDuplex enables simultaneous RX and TX on different radios
+	FullDuplex bool
+
+	// LBTEnable gives the SX1261 control over the TX of the radios
+	LBTEnable bool
+}
+
+// agcParams are the parameters of the automatic gain control and of the PA of a radio type
+type agcParams struct {
+	anaMin, anaMax                       uint8
+	anaThreshL, anaThreshH               uint8
+	decAttnMin, decAttnMax               uint8
+	decThreshL, decThreshH1, decThreshH2 uint8
+	chanAttnMin, chanAttnMax             uint8
+	chanThreshL, chanThreshH             uint8
+	deviceSel, hpMax, paDutyCycle        uint8
+}
+
+// agcParamsSX1250 and agcParamsSX125x are the parameters of the reference HAL for each radio type
+var (
+	agcParamsSX1250 = agcParams{
+		anaMin: 1, anaMax: 13,
+		anaThreshL: 3, anaThreshH: 12,
+		decAttnMin: 4, decAttnMax: 15,
+		decThreshL: 40, decThreshH1: 80, decThreshH2: 90,
+		chanAttnMin: 4, chanAttnMax: 14,
+		chanThreshL: 52, chanThreshH: 132,
+		deviceSel: 0, hpMax: 7, paDutyCycle: 4,
+	}
+
+	agcParamsSX125x = agcParams{
+		anaMin: 0, anaMax: 9,
+		anaThreshL: 16, anaThreshH: 35,
+		decAttnMin: 7, decAttnMax: 11,
+		decThreshL: 45, decThreshH1: 100, decThreshH2: 115,
+		chanAttnMin: 4, chanAttnMax: 14,
+		chanThreshL: 52, chanThreshH: 132,
+	}
+)
+
+// agcStep is a step of the AGC handshake
+type agcStep struct {
+	name   string
+	params []uint8
+	notify uint8
+	status uint8
+}
+
+// mcuRegs are the registers controlling one of the MCUs
+type mcuRegs struct {
+	memAddr     uint16
+	clear       RegID
+	hostProg    RegID
+	parityError RegID
+	status      RegID
+}
+
+// mcus lists the registers of every MCU
+var mcus = map[MCU]mcuRegs{
+	MCUAgc: {
+		memAddr:     AGCMemAddr,
+		clear:       RegAgcMcuCtrlMcuClear,
+		hostProg:    RegAgcMcuCtrlHostProg,
+		parityError: RegAgcMcuCtrlParityError,
+		status:      RegAgcMcuMcuAgcStatusMcuAgcStatus,
+	},
+	MCUArb: {
+		memAddr:     ARBMemAddr,
+		clear:       RegArbMcuCtrlMcuClear,
+		hostProg:    RegArbMcuCtrlHostProg,
+		parityError: RegArbMcuCtrlParityError,
+		status:      RegArbMcuMcuArbStatusMcuArbStatus,
+	},
+}
+
+// LoadFirmware takes control over mcu, uploads fw to its memory, reads it back and compares it, then releases the MCU
+// to run the firmware
+func (r *Registers) LoadFirmware(mcu MCU, fw Firmware) error {
+	regs, ok := mcus[mcu]
+	if !ok {
+		return wrapf("unknown mcu %d", mcu)
+	}
+
+	if len(fw.Image) != MCUMemSize {
+		return wrapf("%s firmware is %d bytes, expected %d bytes", mcu, len(fw.Image), MCUMemSize)
+	}
+
+	if err := r.RegWrite(regs.clear, 1); err != nil {
+		return err
+	}
+
+	if err := r.RegWrite(regs.hostProg, 1); err != nil {
+		return err
+	}
+
+	// the MCU memory is only reachable from page 0
+	if err := r.RegWrite(RegCommonPagePage, 0); err != nil {
+		return err
+	}
+
+	if err := r.com.DevWriteBurst(model.SpiMuxTargetSX1302, regs.memAddr, fw.Image); err != nil {
+		return wrapf("failed to write %s firmware: %v", mcu, err)
+	}
+
+	check := make([]byte, MCUMemSize)
+	if err := r.com.DevReadBurst(model.SpiMuxTargetSX1302, regs.memAddr, check); err != nil {
+		return wrapf("failed to read back %s firmware: %v", mcu, err)
+	}
+
+	if !bytes.Equal(fw.Image, check) {
+		for i := range check {
+			if check[i] != fw.Image[i] {
+				return &FirmwareVerifyError{MCU: mcu, Offset: i, Wrote: fw.Image[i], Read: check[i]}
+			}
+		}
+	}
+
+	if err := r.RegWrite(regs.hostProg, 0); err != nil {
+		return err
+	}
+
+	if err := r.RegWrite(regs.clear, 0); err != nil {
+		return err
+	}
+
+	parity, err := r.RegRead(regs.parityError)
+	if err != nil {
+		return err
+	}
+
+	if parity != 0 {
+		return &FirmwareParityError{MCU: mcu}
+	}
+
+	return nil
+}
+
+// AGCStart runs the handshake with the AGC firmware: it waits for the firmware to start, checks its version and hands
+// over the gain settings of both radios, the AGC and PA parameters of the radio type and the LBT setting through the
+// mailboxes.
+func (r *Registers) AGCStart(version uint8, conf AGCConfig) error {
+	if err := r.waitMCUStatus(MCUAgc, agcStatusStarted); err != nil {
+		return err
+	}
+
+	got, err := r.agcMailboxRead(0)
+	if err != nil {
+		return err
+	}
+
+	if got != version {
+		return &FirmwareVersionError{MCU: MCUAgc, Expected: version, Got: got}
+	}
+
+	for _, step := range agcSteps(conf) {
+		if err := r.agcStep(step); err != nil {
+			return err
+		}
+	}
+
+	// the firmware starts controlling the gains once notified that everything is configured
+	return r.agcMailboxWrite(agcMailboxNotify, agcNotifyDone)
+}
+
+// agcSteps returns the steps of the AGC handshake configuring conf
+func agcSteps(conf AGCConfig) []agcStep {
+	p, sx1250 := agcParamsSX125x, conf.RadioType == model.RadioTypeSX1250
+	if sx1250 {
+		p = agcParamsSX1250
+	}
+
+	// the SX1250 firmware has no full-duplex setting
+	gains := []uint8{conf.AnaGain, conf.DecGain}
+	if !sx1250 {
+		gains = append(gains, boolToUint8(conf.FullDuplex))
+	}
+
+	steps := []agcStep{
+		{"radio A gains", gains, agcNotifyRadioA, agcStatusRadioADone},
+		{"radio B gains", gains, agcNotifyRadioB, agcStatusRadioBDone},
+		{"analog gain range", []uint8{p.anaMin, p.anaMax}, agcNotifyAnaGain, agcNotifyAnaGain + 1},
+		{"analog gain thresholds", []uint8{p.anaThreshL, p.anaThreshH}, agcNotifyAnaThresh, agcNotifyAnaThresh + 1},
+		{"decimator attenuation range", []uint8{p.decAttnMin, p.decAttnMax}, agcNotifyDecAttn, agcNotifyDecAttn + 1},
+		{"decimator thresholds", []uint8{p.decThreshL, p.decThreshH1, p.decThreshH2}, agcNotifyDecThresh, agcNotifyDecThresh + 1},
+		{"channel attenuation range", []uint8{p.chanAttnMin, p.chanAttnMax}, agcNotifyChanAttn, agcNotifyChanAttn + 1},
+		{"channel thresholds", []uint8{p.chanThreshL, p.chanThreshH}, agcNotifyChanThresh, agcNotifyChanThresh + 1},
+	}
+
+	if sx1250 {
+		steps = append(steps, agcStep{"pa configuration", []uint8{p.deviceSel, p.hpMax, p.paDutyCycle}, agcNotifyPAConfig, agcNotifyPAConfig + 1})
+	}
+
+	return append(steps,
+		agcStep{"pa start delay", []uint8{agcPAStartDelay}, agcNotifyPAStartDelay, agcNotifyPAStartDelay + 1},
+		agcStep{"lbt", []uint8{boolToUint8(conf.LBTEnable)}, agcNotifyLBT, agcStatusConfigured},
+	)
+}
+
+// agcStep writes the parameters of step to the mailboxes, notifies the firmware and checks the parameters it echoes
+func (r *Registers) agcStep(step agcStep) error {
+	for i, param := range step.params {
+		if err := r.agcMailboxWrite(uint8(i), param); err != nil {
+			return err
+		}
+	}
+
+	if err := r.agcMailboxWrite(agcMailboxNotify, step.notify); err != nil {
+		return err
+	}
+
+	if err := r.waitMCUStatus(MCUAgc, step.status); err != nil {
+		return err
+	}
+
+	for i, param := range step.params {
+		got, err := r.agcMailboxRead(uint8(i))
+		if err != nil {
+			return err
+		}
+
+		if got != param {
+			return &MCUConfigError{MCU: MCUAgc, Setting: fmt.Sprintf("%s (mailbox %d)", step.name, i), Expected: param, Got: got}
+		}
+	}
+
+	return nil
+}
+
+// boolToUint8 returns 1 if b is set, 0 otherwise
+func boolToUint8(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// ARBStart runs the handshake with the ARB firmware: it waits for the firmware to start, checks its version,
+// configures the demodulator allocation and lets the firmware resume. ftime enables the double demodulation of the
+// fine timestamping, nil disables it.
+func (r *Registers) ARBStart(version uint8, ftime *model.FineTimeStampConf) error {
+	if err := r.waitMCUStatus(MCUArb, arbStatusStarted); err != nil {
+		return err
+	}
+
+	got, err := r.RegRead(RegArbMcuArbDebugSts0ArbDebugSts0 + RegID(arbDebugStsVersion))
+	if err != nil {
+		return err
+	}
+
+	if uint8(got) != version {
+		return &FirmwareVersionError{MCU: MCUArb, Expected: version, Got: uint8(got)}
+	}
+
+	// demodulate packets twice with the best timing and the fine timing for the spreading factors fine timestamped
+	doubleDemod, err := arbDoubleDemod(ftime)
+	if err != nil {
+		return err
+	}
+
+	if err := r.RegWrite(RegArbMcuArbDebugCfg0ArbDebugCfg0+RegID(arbDebugCfgDoubleDem), int32(doubleDemod)); err != nil {
+		return err
+	}
+
+	if err := r.RegWrite(RegArbMcuArbDebugCfg0ArbDebugCfg0+RegID(arbDebugCfgFilter), int32(arbDoubleDetectFilter)); err != nil {
+		return err
+	}
+
+	if err := r.RegWrite(RegArbMcuArbDebugCfg0ArbDebugCfg0+RegID(arbDebugCfgResume), 1); err != nil {
+		return err
+	}
+
+	return r.waitMCUStatus(MCUArb, arbStatusRunning)
+}
+
+// arbDoubleDemod returns the spreading factors demodulated twice for the fine timestamping configuration ftime
+func arbDoubleDemod(ftime *model.FineTimeStampConf) (uint8, error) {
+	if ftime == nil || !ftime.Enable {
+		return arbDoubleDemodNone, nil
+	}
+
+	switch ftime.Mode {
+	case model.FineTsModeHighCap:
+		return arbDoubleDemodHighCap, nil
+	case model.FineTsModeAllSf:
+		return arbDoubleDemodAllSf, nil
+	}
+
+	return 0, wrapf("invalid fine timestamping mode %d", ftime.Mode)
+}
+
+// agcMailboxWrite writes value to the AGC mailbox
+func (r *Registers) agcMailboxWrite(mailbox uint8, value uint8) error {
+	if mailbox >= agcMailboxCount {
+		return wrapf("invalid agc mailbox %d", mailbox)
+	}
+
+	return r.RegWrite(RegAgcMcuMcuMailBoxWrDataByte0McuMailBoxWrData+RegID(mailbox), int32(value))
+}
+
+// agcMailboxRead reads the AGC mailbox
+func (r *Registers) agcMailboxRead(mailbox uint8) (uint8, error) {
+	if mailbox >= agcMailboxCount {
+		return 0, wrapf("invalid agc mailbox %d", mailbox)
+	}
+
+	value, err := r.RegRead(RegAgcMcuMcuMailBoxRdDataByte0McuMailBoxRdData + RegID(mailbox))
+	return uint8(value), err
+}
+
+// waitMCUStatus polls the status of mcu until it equals status or MCUTimeout expired
+func (r *Registers) waitMCUStatus(mcu MCU, status uint8) error {
+	deadline := time.Now().Add(MCUTimeout)
+	for {
+		got, err := r.RegRead(mcus[mcu].status)
+		if err != nil {
+			return err
+		}
+
+		if uint8(got) == status {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return &MCUTimeoutError{MCU: mcu, Expected: status, Last: uint8(got)}
+		}
+
+		time.Sleep(mcuPollInterval)
+	}
+}
+
+// varByte matches the hexadecimal bytes of the C array in a firmware file
+var varByte = regexp.MustCompile(`0[xX][0-9a-fA-F]{1,2}\b`)
+
+// ParseFirmwareVar parses a firmware image from the C array of a .var file as published with the reference HAL
+func ParseFirmwareVar(rd io.Reader, version uint8) (Firmware, error) {
+	fw := Firmware{Version: version}
+
+	scanner := bufio.NewScanner(rd)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// skip the array declaration, which holds the array size
+		if i := strings.IndexByte(line, '{'); i >= 0 {
+			line = line[i+1:]
+		}
+
+		for _, match := range varByte.FindAllString(line, -1) {
+			b, err := strconv.ParseUint(match[2:], 16, 8)
+			if err != nil {
+				return Firmware{}, wrapf("invalid firmware byte %q: %v", match, err)
+			}
+			fw.Image = append(fw.Image, byte(b))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Firmware{}, err
+	}
+
+	if len(fw.Image) != MCUMemSize {
+		return Firmware{}, wrapf("firmware holds %d bytes, expected %d bytes", len(fw.Image), MCUMemSize)
+	}
+
+	return fw, nil
+}
+
+// embeddedFirmware holds the firmware files of the reference HAL copied into the firmware directory of this package
+//
+//go:embed firmware
+var embeddedFirmware embed.FS
+
+// EmbeddedFirmware returns the firmware files of the reference HAL built into the binary. The files are taken from the
+// firmware directory of this package at build time.
+func EmbeddedFirmware() (FirmwareSet, error) {
+	fsys, err := fs.Sub(embeddedFirmware, "firmware")
+	if err != nil {
+		return FirmwareSet{}, err
+	}
+
+	set, err := LoadFirmwareFS(fsys)
+	if err != nil {
+		return FirmwareSet{}, wrapf("no embedded firmware, copy the .var files of the reference HAL into the firmware directory of the commands package and rebuild: %w", err)
+	}
+
+	return set, nil
+}
+
+// LoadFirmwareDir loads the firmware files of the reference HAL from dir
+func LoadFirmwareDir(dir string) (FirmwareSet, error) {
+	return LoadFirmwareFS(os.DirFS(dir))
+}
+
+// LoadFirmwareFS loads the firmware files of the reference HAL from the root of fsys
+func LoadFirmwareFS(fsys fs.FS) (FirmwareSet, error) {
+	var set FirmwareSet
+	files := []struct {
+		name    string
+		version uint8
+		fw      *Firmware
+	}{
+		{AGCFirmwareFileSX1250, AGCFirmwareVersionSX1250, &set.AGCSX1250},
+		{AGCFirmwareFileSX125x, AGCFirmwareVersionSX125x, &set.AGCSX125x},
+		{ARBFirmwareFile, ARBFirmwareVersion, &set.ARB},
+		{CalFirmwareFile, CalFirmwareVersion, &set.Cal},
+	}
+
+	for _, file := range files {
+		f, err := fsys.Open(file.name)
+		if err != nil {
+			return FirmwareSet{}, err
+		}
+
+		fw, err := ParseFirmwareVar(f, file.version)
+		f.Close()
+		if err != nil {
+			return FirmwareSet{}, wrapf("failed to load %s: %v", file.name, err)
+		}
+
+		*file.fw = fw
+	}
+
+	return set, nil
+}
@@ -0,0 +1,120 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+)
+
+// firmwareVar renders image as the C array of a .var file of the reference HAL
+func firmwareVar(image []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "static uint8_t fw[%d] = {\n", len(image))
+	for i, v := range image {
+		fmt.Fprintf(&b, "0x%02X,", v)
+		if i%16 == 15 {
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("};\n")
+
+	return b.String()
+}
+
+// firmwareFS returns the firmware files of the reference HAL, every image filled with its own byte
+func firmwareFS() fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for i, name := range []string{commands.AGCFirmwareFileSX1250, commands.AGCFirmwareFileSX125x, commands.ARBFirmwareFile, commands.CalFirmwareFile} {
+		image := []byte(strings.Repeat(string(rune(0x10+i)), commands.MCUMemSize))
+		fsys[name] = &fstest.MapFile{Data: []byte(firmwareVar(image))}
+	}
+
+	return fsys
+}
+
+func TestLoadFirmwareFS(t *testing.T) {
+	set, err := commands.LoadFirmwareFS(firmwareFS())
+	if err != nil {
+		t.Fatalf("LoadFirmwareFS() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		fw      commands.Firmware
+		fill    byte
+		version uint8
+	}{
+		{"agc sx1250", set.AGCSX1250, 0x10, commands.AGCFirmwareVersionSX1250},
+		{"agc sx125x", set.AGCSX125x, 0x11, commands.AGCFirmwareVersionSX125x},
+		{"arb", set.ARB, 0x12, commands.ARBFirmwareVersion},
+		{"cal", set.Cal, 0x13, commands.CalFirmwareVersion},
+	}
+
+	for _, tt := range tests {
+		if len(tt.fw.Image) != commands.MCUMemSize || tt.fw.Image[0] != tt.fill || tt.fw.Image[commands.MCUMemSize-1] != tt.fill {
+			t.Errorf("%s firmware image is not the one of its file", tt.name)
+		}
+
+		if tt.fw.Version != tt.version {
+			t.Errorf("%s firmware version = %d, want %d", tt.name, tt.fw.Version, tt.version)
+		}
+	}
+}
+
+func TestLoadFirmwareFSMissingFile(t *testing.T) {
+	fsys := firmwareFS()
+	delete(fsys, commands.ARBFirmwareFile)
+
+	if _, err := commands.LoadFirmwareFS(fsys); err == nil {
+		t.Error("LoadFirmwareFS() succeeded without the arb firmware")
+	}
+}
+
+func TestParseFirmwareVarTruncated(t *testing.T) {
+	if _, err := commands.ParseFirmwareVar(strings.NewReader(firmwareVar([]byte{0x01, 0x02})), 1); err == nil {
+		t.Error("ParseFirmwareVar() accepted a 2 byte image")
+	}
+}
+
+func TestEmbeddedFirmware(t *testing.T) {
+	set, err := commands.EmbeddedFirmware()
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skipf("the firmware files of the reference HAL are not in the firmware directory: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("EmbeddedFirmware() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		fw      commands.Firmware
+		version uint8
+	}{
+		{commands.AGCFirmwareFileSX1250, set.AGCSX1250, commands.AGCFirmwareVersionSX1250},
+		{commands.AGCFirmwareFileSX125x, set.AGCSX125x, commands.AGCFirmwareVersionSX125x},
+		{commands.ARBFirmwareFile, set.ARB, commands.ARBFirmwareVersion},
+		{commands.CalFirmwareFile, set.Cal, commands.CalFirmwareVersion},
+	}
+
+	for i, tt := range tests {
+		if tt.fw.Version != tt.version {
+			t.Errorf("%s: version %d, want %d", tt.name, tt.fw.Version, tt.version)
+		}
+
+		if len(tt.fw.Image) != commands.MCUMemSize || bytes.Count(tt.fw.Image, []byte{0}) == len(tt.fw.Image) {
+			t.Errorf("%s: image of %d bytes is empty, want %d bytes of firmware", tt.name, len(tt.fw.Image), commands.MCUMemSize)
+		}
+
+		for _, other := range tests[:i] {
+			if bytes.Equal(tt.fw.Image, other.fw.Image) {
+				t.Errorf("%s holds the image of %s", tt.name, other.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Command regmapgen generates the register map of the commands package from the register table of the reference HAL,
+// libloragw/src/loragw_reg.c. Each entry of the table has the form
+//
+//	{page,addr,offs,sign,leng,rdon,chck,dflt}, // NAME
+//
+// and becomes the register RegName of the map, NAME converted to camel case. Run it through go generate with the
+// environment variable SX1302_HAL pointing to a checkout of the reference HAL.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// entryRe matches an entry of the register table of the reference HAL
+var entryRe = regexp.MustCompile(`^\s*\{\s*(-?\d+)\s*,\s*(0x[0-9A-Fa-f]+)\s*,\s*(\d+)\s*,\s*([01])\s*,\s*(\d+)\s*,\s*([01])\s*,\s*([01])\s*,\s*(-?(?:0x[0-9A-Fa-f]+|\d+))\s*\}\s*,?\s*//\s*(\w+)`)
+
+// register is an entry of the register table
+type register struct {
+	name     string
+	page     int
+	addr     uint16
+	offs     int
+	sign     bool
+	leng     int
+	readOnly bool
+	check    bool
+
+	// dflt is the default value as written in the table
+	dflt string
+}
+
+// parse returns the registers of the table read from r, in the order of the table
+func parse(r io.Reader) ([]register, error) {
+	var regs []register
+	names := make(map[string]bool)
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		m := entryRe.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+
+		page, _ := strconv.Atoi(m[1])
+		addr, err := strconv.ParseUint(m[2], 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid address %s", line, m[2])
+		}
+
+		offs, _ := strconv.Atoi(m[3])
+		leng, _ := strconv.Atoi(m[5])
+		if leng == 0 || leng > 32 || offs > 7 {
+			return nil, fmt.Errorf("line %d: invalid field of %d bits at offset %d", line, leng, offs)
+		}
+
+		if _, err := strconv.ParseInt(m[8], 0, 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid default value %s", line, m[8])
+		}
+
+		name := strings.TrimPrefix(m[9], "SX1302_REG_")
+		if names[name] {
+			return nil, fmt.Errorf("line %d: duplicate register %s", line, name)
+		}
+		names[name] = true
+
+		regs = append(regs, register{
+			name:     name,
+			page:     page,
+			addr:     uint16(addr),
+			offs:     offs,
+			sign:     m[4] == "1",
+			leng:     leng,
+			readOnly: m[6] == "1",
+			check:    m[7] == "1",
+			dflt:     m[8],
+		})
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(regs) == 0 {
+		return nil, fmt.Errorf("no register table found")
+	}
+
+	return regs, nil
+}
+
+// goName returns the name of the RegID of the register NAME
+func goName(name string) string {
+	var b strings.Builder
+	b.WriteString("Reg")
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + strings.ToLower(part[1:]))
+	}
+
+	return b.String()
+}
+
+// generate returns the source of the register map of regs
+func generate(regs []register) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by regmapgen from loragw_reg.c of the reference HAL. DO NOT EDIT.\n\n")
+	b.WriteString("package commands\n\n")
+
+	b.WriteString("// Registers of the SX1302, named after the SX1302 register map\nconst (\n")
+	for i, reg := range regs {
+		if i == 0 {
+			fmt.Fprintf(&b, "\t%s RegID = iota\n", goName(reg.name))
+			continue
+		}
+		fmt.Fprintf(&b, "\t%s\n", goName(reg.name))
+	}
+	b.WriteString("\n\t// regCount is the total number of registers in the register map\n\tregCount\n)\n\n")
+
+	b.WriteString("// registers is the SX1302 register map indexed by RegID\nvar registers = [regCount]Register{\n")
+	for _, reg := range regs {
+		page := strconv.Itoa(reg.page)
+		if reg.page < 0 {
+			page = "PageAll"
+		}
+
+		fmt.Fprintf(&b, "\t%s: {Name: %q, Page: %s, Addr: 0x%04X, Offs: %d, ", goName(reg.name), reg.name, page, reg.addr, reg.offs)
+		if reg.sign {
+			b.WriteString("Sign: true, ")
+		}
+		fmt.Fprintf(&b, "Leng: %d", reg.leng)
+		if reg.readOnly {
+			b.WriteString(", ReadOnly: true")
+		}
+		if reg.check {
+			b.WriteString(", Check: true")
+		}
+		if v, _ := strconv.ParseInt(reg.dflt, 0, 64); v != 0 {
+			fmt.Fprintf(&b, ", Default: %s", reg.dflt)
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n")
+
+	return format.Source(b.Bytes())
+}
+
+func main() {
+	in := flag.String("in", "", "register table of the reference HAL, libloragw/src/loragw_reg.c")
+	out := flag.String("out", "registers.go", "generated register map")
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to open the register table")
+	}
+	defer f.Close()
+
+	regs, err := parse(f)
+	if err != nil {
+		log.WithError(err).Fatalf("Failed to parse %s", *in)
+	}
+
+	src, err := generate(regs)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to format the register map")
+	}
+
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.WithError(err).Fatal("Failed to write the register map")
+	}
+
+	log.WithField("registers", len(regs)).Infof("Generated %s", *out)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+)
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/loragw_reg.c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	regs, err := parse(f)
+	if err != nil {
+		t.Fatalf("parse() failed: %v", err)
+	}
+
+	want := []register{
+		{name: "COMMON_PAGE_PAGE", page: -1, addr: 0x5600, leng: 2, check: true, dflt: "0"},
+		{name: "COMMON_CTRL0_CLK32_RIF_CTRL", addr: 0x5601, offs: 4, leng: 1, check: true, dflt: "0"},
+		{name: "RX_TOP_FREQ_0_MSB_IF_FREQ_0", addr: 0x5101, sign: true, leng: 5, check: true, dflt: "0"},
+		{name: "RX_TOP_CORRELATOR_SF_EN_CORR_SF_EN", addr: 0x5118, leng: 8, check: true, dflt: "0xFF"},
+		{name: "RX_TOP_RX_BUFFER_NB_BYTES_MSB_RX_BUFFER_NB_BYTES", addr: 0x5150, leng: 8, readOnly: true, dflt: "0"},
+		{name: "RADIO_FE_RSSI_BB_FILTER_ALPHA_RADIO_A_RSSI_BB_FILTER_ALPHA", addr: 0x5703, leng: 4, check: true, dflt: "6"},
+	}
+
+	if !reflect.DeepEqual(regs, want) {
+		t.Errorf("parse() = %+v, want %+v", regs, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"{0,0x5600,0,0,0,0,1,0}, // EMPTY_FIELD",
+		"{0,0x5600,0,0,2,0,1,0}, // TWICE\n{0,0x5601,0,0,2,0,1,0}, // TWICE",
+	}
+
+	for _, table := range tests {
+		if regs, err := parse(strings.NewReader(table)); err == nil {
+			t.Errorf("parse(%q) = %+v, want an error", table, regs)
+		}
+	}
+}
+
+// TestGenerateRegisterMap checks that the register map of the commands package is in the format of the generator,
+// so regenerating it from the table of the reference HAL only changes its entries
+func TestGenerateRegisterMap(t *testing.T) {
+	var regs []register
+	for _, reg := range commands.RegisterMap() {
+		dflt := fmt.Sprint(reg.Default)
+		if reg.Default >= 16 {
+			dflt = fmt.Sprintf("0x%X", reg.Default)
+		}
+
+		regs = append(regs, register{
+			name:     reg.Name,
+			page:     int(reg.Page),
+			addr:     reg.Addr,
+			offs:     int(reg.Offs),
+			sign:     reg.Sign,
+			leng:     int(reg.Leng),
+			readOnly: reg.ReadOnly,
+			check:    reg.Check,
+			dflt:     dflt,
+		})
+	}
+
+	got, err := generate(regs)
+	if err != nil {
+		t.Fatalf("generate() failed: %v", err)
+	}
+
+	want, err := os.ReadFile("../../registers.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the generated map starts with its header
+	_, got, _ = bytes.Cut(got, []byte("\n\n"))
+	if _, generated, ok := bytes.Cut(want, []byte("DO NOT EDIT.\n\n")); ok {
+		want = generated
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Error("generate() of the register map differs from registers.go")
+	}
+}
+
+func TestGoName(t *testing.T) {
+	if got := goName("RX_TOP_FREQ_0_MSB_IF_FREQ_0"); got != "RegRxTopFreq0MsbIfFreq0" {
+		t.Errorf("goName() = %s, want RegRxTopFreq0MsbIfFreq0", got)
+	}
+}
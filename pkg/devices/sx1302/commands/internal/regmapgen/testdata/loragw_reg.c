@@ -0,0 +1,11 @@
+/* excerpt of a register table in the format of libloragw/src/loragw_reg.c */
+
+const struct lgw_reg_s loregs[LGW_TOTALREGS+1] = {
+    {-1,0x5600,0,0,2,0,1,0}, // COMMON_PAGE_PAGE
+    {0,0x5601,4,0,1,0,1,0}, // COMMON_CTRL0_CLK32_RIF_CTRL
+    {0,0x5101,0,1,5,0,1,0}, // RX_TOP_FREQ_0_MSB_IF_FREQ_0
+    {0,0x5118,0,0,8,0,1,0xFF}, // RX_TOP_CORRELATOR_SF_EN_CORR_SF_EN
+    {0,0x5150,0,0,8,1,0,0}, // RX_TOP_RX_BUFFER_NB_BYTES_MSB_RX_BUFFER_NB_BYTES
+    {0,0x5703,0,0,4,0,1,6}, // RADIO_FE_RSSI_BB_FILTER_ALPHA_RADIO_A_RSSI_BB_FILTER_ALPHA
+    {0,0x0000,0,0,0,0,0,0}
+};
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+const (
+	// irqWaitSlice is the longest time spent in a single WaitForEdge, bounding the latency of a cancellation
+	irqWaitSlice = 50 * time.Millisecond
+
+	// PollInterval is the interval the concentrator is polled at when no IRQ pin is wired
+	PollInterval = 10 * time.Millisecond
+)
+
+// ErrStopped is returned by WaitForData once the transport was closed
+var ErrStopped = errors.New("sx1302 lowlevel: transport closed")
+
+// Waiter is implemented by transports able to signal pending data of the concentrator
+type Waiter interface {
+	// WaitForData blocks until the concentrator signals pending data or timeout expired.
+	// It returns whether data is pending and fails if ctx is done or the transport was closed.
+	WaitForData(ctx context.Context, timeout time.Duration) (bool, error)
+}
+
+// WaitForData blocks on the falling edge of the IRQ pin until the concentrator signals pending data or timeout expired.
+// Without an IRQ pin it sleeps for PollInterval and reports pending data, so the caller falls back to timed polling.
+func (r *LowLevel) WaitForData(ctx context.Context, timeout time.Duration) (bool, error) {
+	if r.pins.IRQ == nil {
+		return sleep(ctx, r.stop, min(timeout, PollInterval))
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := stopped(ctx, r.stop); err != nil {
+			return false, err
+		}
+
+		// the IRQ is active low, a pending interrupt whose edge was already consumed is still signalled
+		if r.pins.IRQ.Read() == gpio.Low {
+			return true, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false, nil
+		}
+
+		if r.pins.IRQ.WaitForEdge(min(remaining, irqWaitSlice)) {
+			return true, stopped(ctx, r.stop)
+		}
+	}
+}
+
+// sleep waits for timeout and returns true, unless ctx is done or stop is closed first
+func sleep(ctx context.Context, stop <-chan struct{}, timeout time.Duration) (bool, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-stop:
+		return false, ErrStopped
+	case <-timer.C:
+		return true, nil
+	}
+}
+
+// stopped returns the reason of a cancellation through ctx or stop, or nil if neither happened
+func stopped(ctx context.Context, stop <-chan struct{}) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-stop:
+		return ErrStopped
+	default:
+		return nil
+	}
+}
+
+var _ Waiter = &LowLevel{}
@@ -2,74 +2,430 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"sync"
 
-	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3"
 	"periph.io/x/conn/v3/physic"
 	"periph.io/x/conn/v3/spi"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+const (
+	// readAccess is the R/W bit value of a read access
+	readAccess byte = 0x00
+
+	// writeAccess is the R/W bit value of a write access
+	writeAccess byte = 0x80
+
+	// maxAddress is the highest address reachable with the 15 bit address of the SPI protocol
+	maxAddress uint16 = 0x7FFF
+
+	// maxBurstChunk is the maximum number of data bytes moved by a single burst transaction
+	maxBurstChunk int = 1024
+
+	// writeHeaderSize is the number of bytes preceding the data of a write transaction
+	writeHeaderSize int = 3
+
+	// readHeaderSize is the number of bytes preceding the data of a read transaction, including the dummy byte
+	readHeaderSize int = 4
 )
 
-// LowLevel is a low-level handler of a MFRC522 RFID reader.
+// LowLevel is a low-level handler of a SX1302 LoRa concentrator attached to SPI.
 type LowLevel struct {
-	resetPin    gpio.PinOut
-	irqPin      gpio.PinIn
-	spiDev      spi.Conn
-	antennaGain int
-	stop        chan struct{}
+	pins     Pins
+	spiDev   spi.Conn
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// port is the SPI port opened by Dial, it is closed with the transport
+	port io.Closer
+
+	// maximum number of data bytes per burst transaction
+	writeChunk int
+	readChunk  int
+
+	// writes queued while in ComWriteModeBulk
+	writeMode model.COMWriteMode
+	pending   []pendingWrite
+}
+
+// pendingWrite is a write to consecutive addresses of a SPI mux target queued in bulk mode
+type pendingWrite struct {
+	muxTarget uint8
+	address   uint16
+	data      []byte
 }
 
-// NewLowLevelSPI creates and initializes the RFID card reader attached to SPI.
+// NewLowLevelSPI creates and initializes the SX1302 concentrator attached to SPI.
 //
 //	spiPort - the SPI device to use.
-//	resetPin - reset GPIO pin.
-//	irqPin - irq GPIO pin.
-func NewLowLevelSPI(spiPort spi.Port, resetPin gpio.PinOut, irqPin gpio.PinIn) (*LowLevel, error) {
-	if resetPin == nil {
-		return nil, wrapf("reset pin is not set")
-	}
-	spiDev, err := spiPort.Connect(10*physic.MegaHertz, spi.Mode0, 8)
+//	pins - the GPIO pins wired to the concentrator.
+func NewLowLevelSPI(spiPort spi.Port, pins Pins) (*LowLevel, error) {
+	spiDev, err := ConnectSPI(spiPort)
 	if err != nil {
 		return nil, err
 	}
-	if err := resetPin.Out(gpio.High); err != nil {
+
+	return NewLowLevelConn(spiDev, pins)
+}
+
+// ConnectSPI connects to the SX1302 on spiPort with the clock and mode it supports. A SPI port can only be connected
+// once, the connection is kept to create the transport of every start with NewLowLevelConn.
+func ConnectSPI(spiPort spi.Port) (spi.Conn, error) {
+	return spiPort.Connect(2*physic.MegaHertz, spi.Mode0, 8)
+}
+
+// NewLowLevelConn creates and initializes the SX1302 concentrator on the SPI connection spiDev. Closing the
+// transport releases the pins but leaves spiDev connected.
+//
+//	spiDev - the SPI connection to use.
+//	pins - the GPIO pins wired to the concentrator.
+func NewLowLevelConn(spiDev spi.Conn, pins Pins) (*LowLevel, error) {
+	if err := pins.init(); err != nil {
 		return nil, err
 	}
-	if irqPin != nil {
-		if err := irqPin.In(gpio.PullUp, gpio.FallingEdge); err != nil {
-			return nil, err
-		}
-	}
 
 	dev := &LowLevel{
-		spiDev:      spiDev,
-		irqPin:      irqPin,
-		resetPin:    resetPin,
-		antennaGain: 4,
-		stop:        make(chan struct{}, 1),
+		spiDev: spiDev,
+		pins:   pins,
+		stop:   make(chan struct{}),
 	}
+	dev.writeChunk, dev.readChunk = burstChunkSizes(spiDev)
 
 	return dev, nil
 }
 
-// Init initializes the RFID chip.
+// Init initializes the SX1302 chip by powering it up and resetting it.
 func (r *LowLevel) Init() error {
+	return r.Reset()
+}
+
+// Reset powers the concentrator and pulses the reset lines of the SX1302 and the SX1261.
+// Pending bulk writes are discarded.
+func (r *LowLevel) Reset() error {
+	r.pending = nil
+	return r.pins.reset()
+}
+
+// Close flushes pending writes and stops the driver, cancelling pending WaitForData calls, and releases the GPIO
+// pins. Close can be called more than once.
+func (r *LowLevel) Close() error {
+	err := r.Flush()
+	r.stopOnce.Do(func() { close(r.stop) })
+
+	if perr := r.pins.release(); err == nil {
+		err = perr
+	}
+
+	if r.port != nil {
+		if perr := r.port.Close(); err == nil {
+			err = perr
+		}
+		r.port = nil
+	}
+
+	return err
+}
+
+// SetWriteMode selects whether writes are sent immediately or queued until the next Flush or read.
+// Pending writes are flushed when switching back to ComWriteModeSingle.
+func (r *LowLevel) SetWriteMode(mode model.COMWriteMode) error {
+	switch mode {
+	case model.ComWriteModeSingle:
+		if err := r.Flush(); err != nil {
+			return err
+		}
+	case model.ComWriteModeBulk:
+	default:
+		return wrapf("unsupported write mode %s", mode)
+	}
+
+	r.writeMode = mode
 	return nil
 }
 
-// DevWrite sends data to a device.
-func (r *LowLevel) DevWrite(address int, data byte) error {
-	newData := []byte{(byte(address) << 1) & 0x7E, data}
-	return r.spiDev.Tx(newData, nil)
+// WriteMode returns the currently selected write mode
+func (r *LowLevel) WriteMode() model.COMWriteMode {
+	return r.writeMode
 }
 
-func wrapf(format string, a ...interface{}) error {
-	return fmt.Errorf("mfrc522 lowlevel: "+format, a...)
+// Flush sends all writes queued in bulk mode. Writes are packed into as few SPI transactions as the port accepts.
+func (r *LowLevel) Flush() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+
+	pending := r.pending
+	r.pending = nil
+
+	var packets []spi.Packet
+	size := 0
+	for _, p := range pending {
+		for offset := 0; offset < len(p.data); offset += r.writeChunk {
+			chunk := p.data[offset:min(offset+r.writeChunk, len(p.data))]
+
+			header, err := frameHeader(p.muxTarget, writeAccess, p.address+uint16(offset))
+			if err != nil {
+				return err
+			}
+
+			if size+writeHeaderSize+len(chunk) > r.writeChunk+writeHeaderSize {
+				if err := r.txPackets(packets); err != nil {
+					return err
+				}
+				packets, size = packets[:0], 0
+			}
+
+			packets = append(packets, spi.Packet{W: append(header, chunk...)})
+			size += writeHeaderSize + len(chunk)
+		}
+	}
+
+	return r.txPackets(packets)
 }
 
-func (r *LowLevel) writeCommandSequence(commands [][]byte) error {
-	for _, cmdData := range commands {
-		if err := r.DevWrite(int(cmdData[0]), cmdData[1]); err != nil {
+// DevWrite writes a single byte to the register at address of the given SPI mux target.
+func (r *LowLevel) DevWrite(muxTarget uint8, address uint16, data byte) error {
+	header, err := frameHeader(muxTarget, writeAccess, address)
+	if err != nil {
+		return err
+	}
+
+	if r.writeMode == model.ComWriteModeBulk {
+		r.queue(muxTarget, address, []byte{data})
+		return nil
+	}
+
+	return r.spiDev.Tx(append(header, data), nil)
+}
+
+// DevRead reads a single byte from the register at address of the given SPI mux target.
+func (r *LowLevel) DevRead(muxTarget uint8, address uint16) (byte, error) {
+	if err := r.Flush(); err != nil {
+		return 0, err
+	}
+
+	header, err := frameHeader(muxTarget, readAccess, address)
+	if err != nil {
+		return 0, err
+	}
+
+	// dummy byte followed by the byte clocked out by the chip
+	w := append(header, 0x00, 0x00)
+	read := make([]byte, len(w))
+	if err := r.spiDev.Tx(w, read); err != nil {
+		return 0, err
+	}
+
+	return read[len(read)-1], nil
+}
+
+// DevWriteBurst writes data to consecutive registers starting at address of the given SPI mux target.
+// Data exceeding the transfer size of the SPI port is split into multiple transactions.
+func (r *LowLevel) DevWriteBurst(muxTarget uint8, address uint16, data []byte) error {
+	if len(data) == 0 {
+		return wrapf("burst write of 0 bytes")
+	}
+
+	if err := checkRange(address, len(data)); err != nil {
+		return err
+	}
+
+	if r.writeMode == model.ComWriteModeBulk {
+		r.queue(muxTarget, address, data)
+		return nil
+	}
+
+	for offset := 0; offset < len(data); offset += r.writeChunk {
+		chunk := data[offset:min(offset+r.writeChunk, len(data))]
+
+		header, err := frameHeader(muxTarget, writeAccess, address+uint16(offset))
+		if err != nil {
+			return err
+		}
+
+		if err := r.spiDev.Tx(append(header, chunk...), nil); err != nil {
 			return err
 		}
 	}
+
 	return nil
 }
+
+// DevReadBurst fills buf from consecutive registers starting at address of the given SPI mux target.
+// Data exceeding the transfer size of the SPI port is split into multiple transactions.
+func (r *LowLevel) DevReadBurst(muxTarget uint8, address uint16, buf []byte) error {
+	return r.readBurst(muxTarget, address, buf, false)
+}
+
+// DevReadFifo fills buf from the FIFO at address of the given SPI mux target. Data exceeding the transfer size of the
+// SPI port is split into multiple transactions, each of them starting at address.
+func (r *LowLevel) DevReadFifo(muxTarget uint8, address uint16, buf []byte) error {
+	return r.readBurst(muxTarget, address, buf, true)
+}
+
+// readBurst fills buf starting at address, the transaction of each chunk starts at address if fifo is set or at the
+// register following the previous chunk otherwise
+func (r *LowLevel) readBurst(muxTarget uint8, address uint16, buf []byte, fifo bool) error {
+	if len(buf) == 0 {
+		return wrapf("burst read of 0 bytes")
+	}
+
+	if err := checkBurstRange(address, len(buf), fifo); err != nil {
+		return err
+	}
+
+	if err := r.Flush(); err != nil {
+		return err
+	}
+
+	w := make([]byte, readHeaderSize+min(r.readChunk, len(buf)))
+	read := make([]byte, len(w))
+	for offset := 0; offset < len(buf); offset += r.readChunk {
+		chunk := buf[offset:min(offset+r.readChunk, len(buf))]
+
+		header, err := frameHeader(muxTarget, readAccess, chunkAddress(address, offset, fifo))
+		if err != nil {
+			return err
+		}
+
+		// header and dummy byte, followed by the bytes clocked out by the chip
+		w = w[:readHeaderSize+len(chunk)]
+		clear(w)
+		copy(w, header)
+		if err := r.spiDev.Tx(w, read[:len(w)]); err != nil {
+			return err
+		}
+
+		copy(chunk, read[readHeaderSize:len(w)])
+	}
+
+	return nil
+}
+
+// DevTransfer sends w to the given SPI mux target, unframed, and fills r with the bytes clocked in while w was sent.
+// It is used for the radios behind the SPI mux which have their own command framing. Pending bulk writes are flushed
+// first.
+func (r *LowLevel) DevTransfer(muxTarget uint8, w []byte, read []byte) error {
+	if len(read) != 0 && len(read) != len(w) {
+		return wrapf("transfer reads %d bytes while writing %d bytes", len(read), len(w))
+	}
+
+	if err := r.Flush(); err != nil {
+		return err
+	}
+
+	frame := append([]byte{muxTarget}, w...)
+	if len(read) == 0 {
+		return r.spiDev.Tx(frame, nil)
+	}
+
+	in := make([]byte, len(frame))
+	if err := r.spiDev.Tx(frame, in); err != nil {
+		return err
+	}
+
+	copy(read, in[1:])
+	return nil
+}
+
+// BurstWrite writes data to the SX1302 memory starting at address
+func (r *LowLevel) BurstWrite(address uint16, data []byte) error {
+	return r.DevWriteBurst(model.SpiMuxTargetSX1302, address, data)
+}
+
+// BurstRead fills buf from the SX1302 memory starting at address
+func (r *LowLevel) BurstRead(address uint16, buf []byte) error {
+	return r.DevReadBurst(model.SpiMuxTargetSX1302, address, buf)
+}
+
+// queue appends a write to the bulk queue, merging it with the previous write if the addresses are consecutive
+func (r *LowLevel) queue(muxTarget uint8, address uint16, data []byte) {
+	if n := len(r.pending); n > 0 {
+		last := &r.pending[n-1]
+		if last.muxTarget == muxTarget && int(last.address)+len(last.data) == int(address) {
+			last.data = append(last.data, data...)
+			return
+		}
+	}
+
+	r.pending = append(r.pending, pendingWrite{
+		muxTarget: muxTarget,
+		address:   address,
+		data:      append([]byte(nil), data...),
+	})
+}
+
+// txPackets sends packets as a single SPI transaction with a chip-select toggle between the packets
+func (r *LowLevel) txPackets(packets []spi.Packet) error {
+	switch len(packets) {
+	case 0:
+		return nil
+	case 1:
+		return r.spiDev.Tx(packets[0].W, nil)
+	}
+
+	return r.spiDev.TxPackets(packets)
+}
+
+// burstChunkSizes returns the number of data bytes per write and read transaction accepted by spiDev
+func burstChunkSizes(spiDev spi.Conn) (int, int) {
+	limit := 0
+	if l, ok := spiDev.(conn.Limits); ok {
+		limit = l.MaxTxSize()
+	}
+
+	if limit <= readHeaderSize {
+		return maxBurstChunk, maxBurstChunk
+	}
+
+	return min(maxBurstChunk, limit-writeHeaderSize), min(maxBurstChunk, limit-readHeaderSize)
+}
+
+// checkRange verifies that size bytes starting at address are within the 15 bit address space
+func checkRange(address uint16, size int) error {
+	if int(address)+size-1 > int(maxAddress) {
+		return wrapf("burst of %d bytes at address 0x%04X exceeds 15 bit address space", size, address)
+	}
+
+	return nil
+}
+
+// checkBurstRange checks that a burst of size bytes at address, or a read of the FIFO at address if fifo is set,
+// stays within the address space
+func checkBurstRange(address uint16, size int, fifo bool) error {
+	if fifo {
+		size = 1
+	}
+
+	return checkRange(address, size)
+}
+
+// chunkAddress returns the address of the chunk at offset of a burst starting at address. The chunks of a read of the
+// FIFO at address all start at address.
+func chunkAddress(address uint16, offset int, fifo bool) uint16 {
+	if fifo {
+		return address
+	}
+
+	return address + uint16(offset)
+}
+
+// frameHeader builds the SPI mux target byte followed by the R/W bit and the 15 bit address
+func frameHeader(muxTarget uint8, access byte, address uint16) ([]byte, error) {
+	if address > maxAddress {
+		return nil, wrapf("address 0x%04X exceeds 15 bit address space", address)
+	}
+
+	return []byte{
+		muxTarget,
+		access | byte(address>>8)&0x7F,
+		byte(address),
+	}, nil
+}
+
+func wrapf(format string, a ...interface{}) error {
+	return fmt.Errorf("sx1302 lowlevel: "+format, a...)
+}
@@ -0,0 +1,285 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/conntest"
+	"periph.io/x/conn/v3/gpio/gpiotest"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spitest"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// newPlayback connects a LowLevel to a playback of ops
+func newPlayback(t *testing.T, ops ...conntest.IO) (*LowLevel, *spitest.Playback) {
+	t.Helper()
+
+	port := &spitest.Playback{Playback: conntest.Playback{Ops: ops, DontPanic: true}}
+	dev, err := NewLowLevelSPI(port, Pins{Reset: &gpiotest.Pin{N: "RESET"}})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	return dev, port
+}
+
+func TestFrameHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		muxTarget uint8
+		access    byte
+		address   uint16
+		want      []byte
+	}{
+		{"write sx1302", model.SpiMuxTargetSX1302, writeAccess, 0x5601, []byte{0x00, 0xD6, 0x01}},
+		{"read sx1302", model.SpiMuxTargetSX1302, readAccess, 0x5601, []byte{0x00, 0x56, 0x01}},
+		{"write radio a", model.SpiMuxTargetRadioA, writeAccess, 0x0000, []byte{0x01, 0x80, 0x00}},
+		{"read highest address", model.SpiMuxTargetSX1261, readAccess, maxAddress, []byte{0x03, 0x7F, 0xFF}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := frameHeader(tt.muxTarget, tt.access, tt.address)
+			if err != nil {
+				t.Fatalf("frameHeader() failed: %v", err)
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("frameHeader() = % X, want % X", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := frameHeader(model.SpiMuxTargetSX1302, readAccess, maxAddress+1); err == nil {
+		t.Error("frameHeader() accepted an address beyond 15 bits")
+	}
+}
+
+func TestLowLevelDevWrite(t *testing.T) {
+	dev, port := newPlayback(t, conntest.IO{W: []byte{0x00, 0xD6, 0x01, 0x2A}})
+
+	if err := dev.DevWrite(model.SpiMuxTargetSX1302, 0x5601, 0x2A); err != nil {
+		t.Fatalf("DevWrite() failed: %v", err)
+	}
+
+	if err := port.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLowLevelDevRead(t *testing.T) {
+	// the chip clocks the value out after the header and the dummy byte
+	dev, port := newPlayback(t, conntest.IO{
+		W: []byte{0x00, 0x56, 0x01, 0x00, 0x00},
+		R: []byte{0x00, 0x00, 0x00, 0x00, 0x10},
+	})
+
+	got, err := dev.DevRead(model.SpiMuxTargetSX1302, 0x5601)
+	if err != nil {
+		t.Fatalf("DevRead() failed: %v", err)
+	}
+
+	if got != 0x10 {
+		t.Errorf("DevRead() = 0x%02X, want 0x10", got)
+	}
+
+	if err := port.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLowLevelDevBurst(t *testing.T) {
+	dev, port := newPlayback(t,
+		conntest.IO{W: []byte{0x00, 0x80, 0x10, 0x01, 0x02, 0x03}},
+		conntest.IO{
+			W: []byte{0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00},
+			R: []byte{0x00, 0x00, 0x00, 0x00, 0x01, 0x02, 0x03},
+		},
+	)
+
+	if err := dev.DevWriteBurst(model.SpiMuxTargetSX1302, 0x0010, []byte{0x01, 0x02, 0x03}); err != nil {
+		t.Fatalf("DevWriteBurst() failed: %v", err)
+	}
+
+	buf := make([]byte, 3)
+	if err := dev.DevReadBurst(model.SpiMuxTargetSX1302, 0x0010, buf); err != nil {
+		t.Fatalf("DevReadBurst() failed: %v", err)
+	}
+
+	if want := []byte{0x01, 0x02, 0x03}; !bytes.Equal(buf, want) {
+		t.Errorf("DevReadBurst() = % X, want % X", buf, want)
+	}
+
+	if err := port.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLowLevelDevBurstRange(t *testing.T) {
+	dev, _ := newPlayback(t)
+
+	if err := dev.DevWriteBurst(model.SpiMuxTargetSX1302, maxAddress, []byte{0x01, 0x02}); err == nil {
+		t.Error("DevWriteBurst() accepted a burst beyond the 15 bit address space")
+	}
+
+	if err := dev.DevReadBurst(model.SpiMuxTargetSX1302, 0x0000, nil); err == nil {
+		t.Error("DevReadBurst() accepted an empty burst")
+	}
+}
+
+func TestLowLevelDevTransfer(t *testing.T) {
+	// radio frames are sent unframed behind the mux target byte
+	dev, port := newPlayback(t, conntest.IO{
+		W: []byte{0x01, 0xC0, 0x00},
+		R: []byte{0x00, 0xA2, 0x22},
+	})
+
+	read := make([]byte, 2)
+	if err := dev.DevTransfer(model.SpiMuxTargetRadioA, []byte{0xC0, 0x00}, read); err != nil {
+		t.Fatalf("DevTransfer() failed: %v", err)
+	}
+
+	if want := []byte{0xA2, 0x22}; !bytes.Equal(read, want) {
+		t.Errorf("DevTransfer() read % X, want % X", read, want)
+	}
+
+	if err := port.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+// chunkPort is a fake SPI port accepting transfers of at most limit bytes, it records the transfers
+type chunkPort struct {
+	limit int
+	txs   [][]byte
+	keep  bool
+}
+
+func (p *chunkPort) String() string                                            { return "chunk" }
+func (p *chunkPort) Close() error                                              { return nil }
+func (p *chunkPort) LimitSpeed(physic.Frequency) error                         { return nil }
+func (p *chunkPort) Duplex() conn.Duplex                                       { return conn.Full }
+func (p *chunkPort) MaxTxSize() int                                            { return p.limit }
+func (p *chunkPort) Connect(physic.Frequency, spi.Mode, int) (spi.Conn, error) { return p, nil }
+
+func (p *chunkPort) Tx(w, r []byte) error {
+	if len(w) > p.limit {
+		return fmt.Errorf("transfer of %d bytes exceeds %d bytes", len(w), p.limit)
+	}
+
+	if p.keep {
+		p.txs = append(p.txs, append([]byte(nil), w...))
+	}
+	return nil
+}
+
+func (p *chunkPort) TxPackets(packets []spi.Packet) error {
+	for _, packet := range packets {
+		if err := p.Tx(packet.W, packet.R); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestLowLevelBurstChunks(t *testing.T) {
+	port := &chunkPort{limit: 64, keep: true}
+	dev, err := NewLowLevelSPI(port, Pins{Reset: &gpiotest.Pin{N: "RESET"}})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	if err := dev.BurstWrite(0x1000, data); err != nil {
+		t.Fatalf("BurstWrite() failed: %v", err)
+	}
+
+	// every chunk continues at the address following the previous chunk
+	var got []byte
+	address := uint16(0x1000)
+	for _, tx := range port.txs {
+		if len(tx) > port.limit {
+			t.Errorf("transfer of %d bytes exceeds the limit of %d bytes", len(tx), port.limit)
+		}
+
+		if want := []byte{model.SpiMuxTargetSX1302, writeAccess | byte(address>>8), byte(address)}; !bytes.Equal(tx[:writeHeaderSize], want) {
+			t.Errorf("chunk header = % X, want % X", tx[:writeHeaderSize], want)
+		}
+
+		got = append(got, tx[writeHeaderSize:]...)
+		address += uint16(len(tx) - writeHeaderSize)
+	}
+
+	if len(port.txs) != 4 || !bytes.Equal(got, data) {
+		t.Errorf("BurstWrite() sent %d chunks with % X, want 4 chunks with % X", len(port.txs), got, data)
+	}
+}
+
+func TestLowLevelFifoChunks(t *testing.T) {
+	port := &chunkPort{limit: 64, keep: true}
+	dev, err := NewLowLevelSPI(port, Pins{Reset: &gpiotest.Pin{N: "RESET"}})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	if err := dev.DevReadFifo(model.SpiMuxTargetSX1302, RxBufferAddr, make([]byte, 200)); err != nil {
+		t.Fatalf("DevReadFifo() failed: %v", err)
+	}
+
+	// every chunk reads the FIFO address again
+	var read int
+	want := []byte{model.SpiMuxTargetSX1302, readAccess | byte(RxBufferAddr>>8), byte(RxBufferAddr & 0xFF)}
+	for _, tx := range port.txs {
+		if !bytes.Equal(tx[:writeHeaderSize], want) {
+			t.Errorf("chunk header = % X, want % X", tx[:writeHeaderSize], want)
+		}
+
+		read += len(tx) - readHeaderSize
+	}
+
+	if len(port.txs) != 4 || read != 200 {
+		t.Errorf("DevReadFifo() read %d bytes in %d chunks, want 200 bytes in 4 chunks", read, len(port.txs))
+	}
+
+	// only the FIFO address has to be within the address space
+	if err := dev.DevReadFifo(model.SpiMuxTargetSX1302, maxAddress, make([]byte, 200)); err != nil {
+		t.Errorf("DevReadFifo() at the last address failed: %v", err)
+	}
+}
+
+func benchmarkBurst(b *testing.B, limit int, size int, read bool) {
+	dev, err := NewLowLevelSPI(&chunkPort{limit: limit}, Pins{Reset: &gpiotest.Pin{N: "RESET"}})
+	if err != nil {
+		b.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	buf := make([]byte, size)
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if read {
+			err = dev.BurstRead(0x0000, buf)
+		} else {
+			err = dev.BurstWrite(0x0000, buf)
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// the firmware images of the MCUs are 8kB, periph ports commonly accept 4096 bytes per transfer
+func BenchmarkBurstWrite8k(b *testing.B)        { benchmarkBurst(b, 4096, 8192, false) }
+func BenchmarkBurstWrite8kLimit64(b *testing.B) { benchmarkBurst(b, 64, 8192, false) }
+func BenchmarkBurstRead8k(b *testing.B)         { benchmarkBurst(b, 4096, 8192, true) }
+func BenchmarkBurstRead8kLimit64(b *testing.B)  { benchmarkBurst(b, 64, 8192, true) }
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+)
+
+// gpioSettleTime is the time to wait after every edge of the power and reset sequence
+const gpioSettleTime = 100 * time.Millisecond
+
+// Pins are the GPIO pins wired to the concentrator. Reset is mandatory, all other pins are optional
+// as not every board wires them (e.g. the power enable of the RAK2287 and WM1302).
+type Pins struct {
+	// Reset of the SX1302, active high
+	Reset gpio.PinOut
+
+	// PowerEnable switches the power supply of the concentrator, active high
+	PowerEnable gpio.PinOut
+
+	// SX1261Reset of the SX1261 companion radio, active low
+	SX1261Reset gpio.PinOut
+
+	// IRQ signals pending data of the SX1302
+	IRQ gpio.PinIn
+}
+
+// init configures the direction of the pins without resetting the concentrator
+func (p Pins) init() error {
+	if p.Reset == nil {
+		return wrapf("reset pin is not set")
+	}
+
+	if p.IRQ != nil {
+		if err := p.IRQ.In(gpio.PullUp, gpio.FallingEdge); err != nil {
+			return wrapf("failed to configure irq pin %s: %v", p.IRQ, err)
+		}
+	}
+
+	return nil
+}
+
+// reset enables the power supply and pulses the reset of the SX1302, followed by the reset of the SX1261
+func (p Pins) reset() error {
+	steps := []struct {
+		pin   gpio.PinOut
+		level gpio.Level
+	}{
+		{p.PowerEnable, gpio.High},
+		{p.Reset, gpio.High},
+		{p.Reset, gpio.Low},
+		{p.SX1261Reset, gpio.Low},
+		{p.SX1261Reset, gpio.High},
+	}
+
+	for _, step := range steps {
+		if step.pin == nil {
+			continue
+		}
+
+		if err := step.pin.Out(step.level); err != nil {
+			return wrapf("failed to set %s %s: %v", step.pin, step.level, err)
+		}
+
+		time.Sleep(gpioSettleTime)
+	}
+
+	return nil
+}
+
+// release stops the edge detection of the IRQ pin and switches the power supply of the concentrator off
+func (p Pins) release() error {
+	if p.IRQ != nil {
+		if err := p.IRQ.Halt(); err != nil {
+			return wrapf("failed to release irq pin %s: %v", p.IRQ, err)
+		}
+	}
+
+	if p.PowerEnable != nil {
+		if err := p.PowerEnable.Out(gpio.Low); err != nil {
+			return wrapf("failed to set %s %s: %v", p.PowerEnable, gpio.Low, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpiotest"
+)
+
+// edge is a level set on a pin
+type edge struct {
+	pin   string
+	level gpio.Level
+	at    time.Time
+}
+
+// edgeLog records the edges of its pins in order
+type edgeLog struct {
+	mu    sync.Mutex
+	edges []edge
+}
+
+// pin returns a gpiotest pin named name logging its edges
+func (l *edgeLog) pin(name string) *loggedPin {
+	return &loggedPin{Pin: gpiotest.Pin{N: name}, log: l}
+}
+
+type loggedPin struct {
+	gpiotest.Pin
+	log *edgeLog
+}
+
+func (p *loggedPin) Out(level gpio.Level) error {
+	p.log.mu.Lock()
+	p.log.edges = append(p.log.edges, edge{pin: p.N, level: level, at: time.Now()})
+	p.log.mu.Unlock()
+
+	return p.Pin.Out(level)
+}
+
+func TestPinsReset(t *testing.T) {
+	log := &edgeLog{}
+	pins := Pins{
+		Reset:       log.pin("RESET"),
+		PowerEnable: log.pin("POWER_EN"),
+		SX1261Reset: log.pin("SX1261_RESET"),
+	}
+
+	start := time.Now()
+	if err := pins.reset(); err != nil {
+		t.Fatalf("reset() failed: %v", err)
+	}
+
+	want := []struct {
+		pin   string
+		level gpio.Level
+	}{
+		{"POWER_EN", gpio.High},
+		{"RESET", gpio.High},
+		{"RESET", gpio.Low},
+		{"SX1261_RESET", gpio.Low},
+		{"SX1261_RESET", gpio.High},
+	}
+
+	if len(log.edges) != len(want) {
+		t.Fatalf("reset() set %d edges, want %d", len(log.edges), len(want))
+	}
+
+	prev := start
+	for i, e := range log.edges {
+		if e.pin != want[i].pin || e.level != want[i].level {
+			t.Errorf("edge %d = %s %s, want %s %s", i, e.pin, e.level, want[i].pin, want[i].level)
+		}
+
+		// every edge settles before the next one
+		if i > 0 && e.at.Sub(prev) < gpioSettleTime {
+			t.Errorf("edge %d followed the previous edge after %s, want at least %s", i, e.at.Sub(prev), gpioSettleTime)
+		}
+		prev = e.at
+	}
+
+	if elapsed := time.Since(prev); elapsed < gpioSettleTime {
+		t.Errorf("reset() returned %s after the last edge, want at least %s", elapsed, gpioSettleTime)
+	}
+}
+
+func TestPinsResetOptional(t *testing.T) {
+	log := &edgeLog{}
+	pins := Pins{Reset: log.pin("RESET")}
+
+	if err := pins.reset(); err != nil {
+		t.Fatalf("reset() failed: %v", err)
+	}
+
+	if len(log.edges) != 2 || log.edges[0].level != gpio.High || log.edges[1].level != gpio.Low {
+		t.Errorf("reset() without optional pins set %v, want a high and low edge of RESET", log.edges)
+	}
+}
+
+func TestPinsRelease(t *testing.T) {
+	log := &edgeLog{}
+	pins := Pins{Reset: log.pin("RESET"), PowerEnable: log.pin("POWER_EN")}
+
+	if err := pins.release(); err != nil {
+		t.Fatalf("release() failed: %v", err)
+	}
+
+	if len(log.edges) != 1 || log.edges[0].pin != "POWER_EN" || log.edges[0].level != gpio.Low {
+		t.Errorf("release() set %v, want POWER_EN low", log.edges)
+	}
+
+	if err := (Pins{}).init(); err == nil {
+		t.Error("init() accepted pins without reset pin")
+	}
+}
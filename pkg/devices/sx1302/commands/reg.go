@@ -0,0 +1,259 @@
+package commands
+
+import (
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// pageUnknown is the cached page value before the page register was written
+const pageUnknown int8 = -2
+
+// Registers provides field-level access to the SX1302 register map on top of a Transport
+type Registers struct {
+	com  Transport
+	page int8
+
+	// shadow holds the bytes known to the accessor while in bulk mode, so bit fields are read-modify-written
+	// without flushing the queued writes with a read
+	shadow map[shadowAddr]byte
+}
+
+// shadowAddr is the address of a register byte, together with its page
+type shadowAddr struct {
+	page int8
+	addr uint16
+}
+
+// volatile holds the register bytes changed by the chip itself, they are never shadowed
+var volatile = func() map[uint16]bool {
+	bytes := make(map[uint16]bool)
+	for _, reg := range registers {
+		if reg.ReadOnly || !reg.Check {
+			for i := 0; i < reg.size(); i++ {
+				bytes[reg.Addr+uint16(i)] = true
+			}
+		}
+	}
+
+	return bytes
+}()
+
+// NewRegisters creates a new Registers accessor communicating through com
+func NewRegisters(com Transport) *Registers {
+	return &Registers{
+		com:  com,
+		page: pageUnknown,
+	}
+}
+
+// SetWriteMode selects the write mode of the transport. In bulk mode, the bytes written through the accessor are
+// shadowed until the mode is switched back to single, which flushes the queued writes
+func (r *Registers) SetWriteMode(mode model.COMWriteMode) error {
+	if err := r.com.SetWriteMode(mode); err != nil {
+		return err
+	}
+
+	r.shadow = nil
+	if mode == model.ComWriteModeBulk {
+		r.shadow = make(map[shadowAddr]byte)
+	}
+
+	return nil
+}
+
+// RegRead reads the value of the register id. Signed registers are sign-extended
+func (r *Registers) RegRead(id RegID) (int32, error) {
+	reg, err := id.Register()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.selectPage(reg.Page); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, reg.size())
+	if len(buf) == 1 {
+		buf[0], err = r.com.DevRead(model.SpiMuxTargetSX1302, reg.Addr)
+	} else {
+		err = r.com.DevReadBurst(model.SpiMuxTargetSX1302, reg.Addr, buf)
+	}
+	if err != nil {
+		return 0, wrapf("failed to read register %s: %v", reg.Name, err)
+	}
+
+	return reg.decode(buf), nil
+}
+
+// RegWrite writes value to the register id. Bit fields narrower than a byte are read-modify-written
+func (r *Registers) RegWrite(id RegID, value int32) error {
+	reg, err := id.Register()
+	if err != nil {
+		return err
+	}
+
+	if reg.ReadOnly {
+		return wrapf("register %s is read-only", reg.Name)
+	}
+
+	if !reg.fits(value) {
+		return wrapf("value %d does not fit into the %d bits of register %s", value, reg.Leng, reg.Name)
+	}
+
+	if err := r.selectPage(reg.Page); err != nil {
+		return err
+	}
+
+	switch {
+	case reg.Offs == 0 && reg.Leng == 8:
+		err = r.com.DevWrite(model.SpiMuxTargetSX1302, reg.Addr, byte(value))
+		r.setShadow(reg, 0, byte(value))
+
+	case reg.Offs+reg.Leng <= 8:
+		old, ok := r.shadowed(reg)
+		if !ok {
+			old, err = r.com.DevRead(model.SpiMuxTargetSX1302, reg.Addr)
+			if err != nil {
+				break
+			}
+		}
+
+		mask := byte((1<<reg.Leng)-1) << reg.Offs
+		b := (old &^ mask) | (byte(value)<<reg.Offs)&mask
+		err = r.com.DevWrite(model.SpiMuxTargetSX1302, reg.Addr, b)
+		r.setShadow(reg, 0, b)
+
+	case reg.Offs == 0:
+		buf := make([]byte, reg.size())
+		for i := range buf {
+			buf[i] = byte(value >> (8 * i))
+			r.setShadow(reg, i, buf[i])
+		}
+		err = r.com.DevWriteBurst(model.SpiMuxTargetSX1302, reg.Addr, buf)
+
+	default:
+		return wrapf("register %s spans multiple bytes at bit offset %d", reg.Name, reg.Offs)
+	}
+
+	if err != nil {
+		return wrapf("failed to write register %s: %v", reg.Name, err)
+	}
+
+	if id == RegCommonPagePage {
+		r.page = int8(value)
+	}
+
+	return nil
+}
+
+// RegReadBatch reads the registers ids, reading every contiguous address range with a single burst
+func (r *Registers) RegReadBatch(ids []RegID) ([]int32, error) {
+	regs := make([]Register, len(ids))
+	for i, id := range ids {
+		reg, err := id.Register()
+		if err != nil {
+			return nil, err
+		}
+		regs[i] = reg
+	}
+
+	values := make([]int32, len(ids))
+	for start := 0; start < len(regs); {
+		// group registers of the same page whose bytes overlap or directly follow each other
+		first, last := regs[start].Addr, regs[start].Addr+uint16(regs[start].size())
+		end := start + 1
+		for ; end < len(regs); end++ {
+			reg := regs[end]
+			if reg.Page != regs[start].Page || reg.Addr < first || reg.Addr > last {
+				break
+			}
+			if next := reg.Addr + uint16(reg.size()); next > last {
+				last = next
+			}
+		}
+
+		if err := r.selectPage(regs[start].Page); err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, last-first)
+		if err := r.com.DevReadBurst(model.SpiMuxTargetSX1302, first, buf); err != nil {
+			return nil, wrapf("failed to read registers %s to %s: %v", regs[start].Name, regs[end-1].Name, err)
+		}
+
+		for i := start; i < end; i++ {
+			offset := regs[i].Addr - first
+			values[i] = regs[i].decode(buf[offset : offset+uint16(regs[i].size())])
+		}
+
+		start = end
+	}
+
+	return values, nil
+}
+
+// shadowed returns the shadowed first byte of reg, if any
+func (r *Registers) shadowed(reg Register) (byte, bool) {
+	if r.shadow == nil {
+		return 0, false
+	}
+
+	value, ok := r.shadow[shadowAddr{reg.Page, reg.Addr}]
+	return value, ok
+}
+
+// setShadow records value as byte i of reg while in bulk mode, unless the chip changes the byte itself
+func (r *Registers) setShadow(reg Register, i int, value byte) {
+	addr := reg.Addr + uint16(i)
+	if r.shadow == nil || volatile[addr] {
+		return
+	}
+
+	r.shadow[shadowAddr{reg.Page, addr}] = value
+}
+
+// selectPage switches to page if the register is not reachable from the currently selected page
+func (r *Registers) selectPage(page int8) error {
+	if page == PageAll || page == r.page {
+		return nil
+	}
+
+	return r.RegWrite(RegCommonPagePage, int32(page))
+}
+
+// size returns the number of bytes holding the register
+func (reg Register) size() int {
+	return (int(reg.Offs) + int(reg.Leng) + 7) / 8
+}
+
+// fits returns whether value can be represented by the register
+func (reg Register) fits(value int32) bool {
+	if reg.Leng >= 32 {
+		return true
+	}
+
+	if reg.Sign {
+		limit := int32(1) << (reg.Leng - 1)
+		return value >= -limit && value < limit
+	}
+
+	return value >= 0 && int64(value) < int64(1)<<reg.Leng
+}
+
+// decode extracts the register value from buf, holding the register bytes least significant byte first
+func (reg Register) decode(buf []byte) int32 {
+	var raw uint32
+	for i := len(buf) - 1; i >= 0; i-- {
+		raw = raw<<8 | uint32(buf[i])
+	}
+
+	raw >>= reg.Offs
+	if reg.Leng < 32 {
+		raw &= (1 << reg.Leng) - 1
+	}
+
+	if reg.Sign && reg.Leng < 32 && raw&(1<<(reg.Leng-1)) != 0 {
+		raw |= ^uint32(0) << reg.Leng
+	}
+
+	return int32(raw)
+}
@@ -0,0 +1,184 @@
+package commands_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// configWrites is a configuration sequence mixing full bytes, bit fields sharing a byte, multi-byte and signed
+// registers
+var configWrites = []struct {
+	id    commands.RegID
+	value int32
+}{
+	{commands.RegCommonCtrl0Clk32RifCtrl, 1},
+	{commands.RegRadioFeCtrl0RadioADcNotchEn, 1},
+	{commands.RegRadioFeCtrl0RadioAHostFilterGain, 0x0B},
+	{commands.RegRxTopFreq0MsbIfFreq0, -3},
+	{commands.RegRxTopFreq0LsbIfFreq0, 0x80},
+	{commands.RegRxTopFskRefPatternByte0FskRefPattern, 0xC1},
+	{commands.RegRxTopFskRefPatternByte1FskRefPattern, 0x94},
+	{commands.RegRxTopFskCfg0CrcEn, 1},
+	{commands.RegRxTopFskCfg0Bw, 4},
+	{commands.RegRxTopFskCfg0Psize, 2},
+	{commands.RegTimestampGpsCtrlGpsPol, 1},
+	{commands.RegTimestampGpsCtrlGpsEn, 1},
+	{commands.RegTimestampTimestampCtrlEnable, 1},
+}
+
+// applyConfig runs configWrites on a simulator in the given write mode
+func applyConfig(t *testing.T, mode model.COMWriteMode) (*sx1302test.Sim, *commands.Registers) {
+	t.Helper()
+
+	sim := sx1302test.NewSim()
+	ll, err := commands.NewLowLevelSPI(sim, commands.Pins{Reset: sim.ResetPin()})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	regs := commands.NewRegisters(ll)
+	if err := regs.SetWriteMode(mode); err != nil {
+		t.Fatalf("SetWriteMode(%s) failed: %v", mode, err)
+	}
+
+	sim.ClearWrites()
+	for _, w := range configWrites {
+		if err := regs.RegWrite(w.id, w.value); err != nil {
+			t.Fatalf("RegWrite(%d, %d) failed: %v", w.id, w.value, err)
+		}
+	}
+
+	if err := ll.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	return sim, regs
+}
+
+func TestBulkWritesMatchSingleWrites(t *testing.T) {
+	single, _ := applyConfig(t, model.ComWriteModeSingle)
+	bulk, _ := applyConfig(t, model.ComWriteModeBulk)
+
+	for _, w := range configWrites {
+		if got := bulk.Reg(w.id); got != w.value {
+			t.Errorf("register %d = %d in bulk mode, want %d", w.id, got, w.value)
+		}
+	}
+
+	if !bytes.Equal(single.Mem(0, 0x8000), bulk.Mem(0, 0x8000)) {
+		t.Error("register file after bulk writes differs from the one after single writes")
+	}
+
+	if len(bulk.Writes()) > len(single.Writes()) {
+		t.Errorf("bulk mode wrote %d bytes, more than the %d bytes of single mode", len(bulk.Writes()), len(single.Writes()))
+	}
+
+	if bulk.Transactions() >= single.Transactions() {
+		t.Errorf("bulk mode took %d transactions, want less than the %d of single mode", bulk.Transactions(), single.Transactions())
+	}
+}
+
+func TestBulkWritesAreShadowed(t *testing.T) {
+	sim, regs := applyConfig(t, model.ComWriteModeBulk)
+	sim.ClearWrites()
+
+	// the bytes of the configuration are known now, writing its bit fields again needs no read
+	for _, w := range configWrites {
+		if err := regs.RegWrite(w.id, 0); err != nil {
+			t.Fatalf("RegWrite(%d, 0) failed: %v", w.id, err)
+		}
+	}
+
+	if err := regs.SetWriteMode(model.ComWriteModeSingle); err != nil {
+		t.Fatalf("SetWriteMode(%s) failed: %v", model.ComWriteModeSingle, err)
+	}
+
+	if got := sim.Transactions(); got != 1 {
+		t.Errorf("rewriting the configuration took %d transactions, want 1", got)
+	}
+
+	for _, w := range configWrites {
+		if got := sim.Reg(w.id); got != 0 {
+			t.Errorf("register %d = %d, want 0", w.id, got)
+		}
+	}
+}
+
+func TestRegReadBatch(t *testing.T) {
+	_, regs := applyConfig(t, model.ComWriteModeSingle)
+
+	ids := make([]commands.RegID, len(configWrites))
+	for i, w := range configWrites {
+		ids[i] = w.id
+	}
+
+	values, err := regs.RegReadBatch(ids)
+	if err != nil {
+		t.Fatalf("RegReadBatch() failed: %v", err)
+	}
+
+	for i, w := range configWrites {
+		if values[i] != w.value {
+			t.Errorf("RegReadBatch() register %d = %d, want %d", w.id, values[i], w.value)
+		}
+	}
+}
+
+func TestRegisterMap(t *testing.T) {
+	names := make(map[string]commands.RegID)
+	bits := make(map[[2]int]uint8)
+
+	for i, reg := range commands.RegisterMap() {
+		id := commands.RegID(i)
+		if reg.Name == "" {
+			t.Errorf("register %d has no description", id)
+			continue
+		}
+
+		if prev, ok := names[reg.Name]; ok {
+			t.Errorf("registers %d and %d are both named %s", prev, id, reg.Name)
+		}
+		names[reg.Name] = id
+
+		// registers are outside of the MCU memories and the RX and TX buffers
+		for _, mem := range []struct {
+			addr uint16
+			size int
+		}{
+			{commands.AGCMemAddr, commands.MCUMemSize},
+			{commands.ARBMemAddr, commands.MCUMemSize},
+			{commands.RxBufferAddr, commands.RxBufferSize},
+			{commands.TxBufferAddrA, commands.TxBufferSize},
+			{commands.TxBufferAddrB, commands.TxBufferSize},
+		} {
+			if reg.Addr >= mem.addr && int(reg.Addr) < int(mem.addr)+mem.size {
+				t.Errorf("%s at 0x%04X lies in the memory at 0x%04X", reg.Name, reg.Addr, mem.addr)
+			}
+		}
+
+		if reg.Addr >= 0x8000 {
+			t.Errorf("%s: address 0x%04X exceeds 15 bits", reg.Name, reg.Addr)
+		}
+
+		if reg.Leng == 0 || int(reg.Offs)+int(reg.Leng) > 8 {
+			t.Errorf("%s: %d bits at offset %d do not fit a byte", reg.Name, reg.Leng, reg.Offs)
+			continue
+		}
+
+		// fields sharing a byte do not overlap
+		mask := uint8((1<<reg.Leng)-1) << reg.Offs
+		key := [2]int{int(reg.Page), int(reg.Addr)}
+		if bits[key]&mask != 0 {
+			t.Errorf("%s overlaps another field at 0x%04X", reg.Name, reg.Addr)
+		}
+		bits[key] |= mask
+
+		if reg.Default < 0 || reg.Default >= 1<<reg.Leng {
+			t.Errorf("%s: default value %d does not fit %d bits", reg.Name, reg.Default, reg.Leng)
+		}
+	}
+}
@@ -0,0 +1,648 @@
+package commands
+
+// Registers of the SX1302, named after the SX1302 register map
+const (
+	RegCommonPagePage RegID = iota
+	RegCommonCtrl0Clk32RifCtrl
+	RegCommonCtrl0HostRadioCtrl
+	RegCommonCtrl0RadioMiscEn
+	RegCommonCtrl0Sx1261ModeRadioA
+	RegCommonCtrl0Sx1261ModeRadioB
+	RegCommonCtrl1SwapIqRadioA
+	RegCommonCtrl1SwapIqRadioB
+	RegCommonCtrl1SamplingEdgeRadioA
+	RegCommonCtrl1SamplingEdgeRadioB
+	RegCommonSpiDivRatioSpiHalfPeriod
+	RegCommonRadioSelectRadioSelect
+	RegCommonGenConcentratorModemEnable
+	RegCommonGenMbwssfModemEnable
+	RegCommonGenFskModemEnable
+	RegCommonGenGlobalEn
+	RegCommonVersionVersion
+	RegCommonDummyDummy
+	RegClkCtrlClkSelClkRadioASel
+	RegClkCtrlClkSelClkRadioBSel
+	RegClkCtrlClkSelClkdivEn
+	RegClkCtrlDummyDummy
+	RegGpioGpioDirHDirection
+	RegGpioGpioDirLDirection
+	RegGpioGpioOutHOutValue
+	RegGpioGpioOutLOutValue
+	RegGpioGpioInHInValue
+	RegGpioGpioInLInValue
+	RegGpioGpioPdHPdEn
+	RegGpioGpioPdLPdEn
+	RegGpioGpioSel0Selection
+	RegGpioGpioSel1Selection
+	RegGpioGpioSel2Selection
+	RegGpioGpioSel3Selection
+	RegGpioGpioSel4Selection
+	RegGpioGpioSel5Selection
+	RegGpioGpioSel6Selection
+	RegGpioGpioSel7Selection
+	RegGpioGpioSel8Selection
+	RegGpioGpioSel9Selection
+	RegGpioGpioSel10Selection
+	RegGpioGpioSel11Selection
+	RegRxTopRadioSelectRadioSelect
+	RegRxTopFreq0MsbIfFreq0
+	RegRxTopFreq0LsbIfFreq0
+	RegRxTopFreq1MsbIfFreq1
+	RegRxTopFreq1LsbIfFreq1
+	RegRxTopFreq2MsbIfFreq2
+	RegRxTopFreq2LsbIfFreq2
+	RegRxTopFreq3MsbIfFreq3
+	RegRxTopFreq3LsbIfFreq3
+	RegRxTopFreq4MsbIfFreq4
+	RegRxTopFreq4LsbIfFreq4
+	RegRxTopFreq5MsbIfFreq5
+	RegRxTopFreq5LsbIfFreq5
+	RegRxTopFreq6MsbIfFreq6
+	RegRxTopFreq6LsbIfFreq6
+	RegRxTopFreq7MsbIfFreq7
+	RegRxTopFreq7LsbIfFreq7
+	RegRxTopLoraServiceFskRadioSelectLoraService
+	RegRxTopLoraServiceFskRadioSelectFsk
+	RegRxTopLoraServiceFskFreqMsbLoraService
+	RegRxTopLoraServiceFskFreqLsbLoraService
+	RegRxTopLoraServiceFskFreqMsbFsk
+	RegRxTopLoraServiceFskFreqLsbFsk
+	RegRxTopCorrClockEnableClkEn
+	RegRxTopCorrelatorEnCorrEn
+	RegRxTopCorrelatorSfEnCorrSfEn
+	RegRxTopCorrelatorEnableOnlyFirstDetEdgeEnableOnlyFirstDetEdge
+	RegRxTopCorrelatorEnableAccClearEnableCorrAccClear
+	RegRxTopDcNotchCfg1Enable
+	RegRxTopRxDfeAgc1ForceDefaultFir
+	RegRxTopModemPpmOffsetSf11
+	RegRxTopModemPpmOffsetSf12
+	RegRxTopFrameSynch0Sf5Peak1PosSf5
+	RegRxTopFrameSynch1Sf5Peak2PosSf5
+	RegRxTopFrameSynch0Sf6Peak1PosSf6
+	RegRxTopFrameSynch1Sf6Peak2PosSf6
+	RegRxTopFrameSynch0Sf7to12Peak1PosSf7to12
+	RegRxTopFrameSynch1Sf7to12Peak2PosSf7to12
+	RegRxTopLoraServiceFskFrameSynch0Peak1Pos
+	RegRxTopLoraServiceFskFrameSynch1Peak2Pos
+	RegRxTopLoraServiceCfg0RateSf
+	RegRxTopLoraServiceCfg0ModemBw
+	RegRxTopLoraServiceCfg1CodingRate
+	RegRxTopLoraServiceCfg1CrcEn
+	RegRxTopLoraServiceCfg1ImplicitHeader
+	RegRxTopLoraServiceCfg2PayloadLength
+	RegRxTopLoraServiceCfg3ModemEn
+	RegRxTopFskCfg0Bw
+	RegRxTopFskCfg0Psize
+	RegRxTopFskCfg0CrcEn
+	RegRxTopFskCfg0CrcIbm
+	RegRxTopFskCfg1DcfreeEnc
+	RegRxTopFskCfg1PktMode
+	RegRxTopFskCfg1AdrsComp
+	RegRxTopFskBitRateMsbBitRate
+	RegRxTopFskBitRateLsbBitRate
+	RegRxTopFskRefPatternByte0FskRefPattern
+	RegRxTopFskRefPatternByte1FskRefPattern
+	RegRxTopFskRefPatternByte2FskRefPattern
+	RegRxTopFskRefPatternByte3FskRefPattern
+	RegRxTopFskRefPatternByte4FskRefPattern
+	RegRxTopFskRefPatternByte5FskRefPattern
+	RegRxTopFskRefPatternByte6FskRefPattern
+	RegRxTopFskRefPatternByte7FskRefPattern
+	RegRxTopFskPktLenPktLen
+	RegRxTopFskNodeAdrsNodeAdrs
+	RegRxTopFskBroadcastBroadcast
+	RegRxTopFskAutoAfcOnAutoAfcOn
+	RegRxTopFskErrorOsrTolErrorOsrTol
+	RegRxTopRxBufferNbBytesMsbRxBufferNbBytes
+	RegRxTopRxBufferNbBytesLsbRxBufferNbBytes
+	RegRxTopRxBufferLastAddrReadMsbLastAddrRead
+	RegRxTopRxBufferLastAddrReadLsbLastAddrRead
+	RegRxTopRxBufferTimestampCfgMaxTsMetrics
+	RegRxTopRxBufferLegacyTimestampLegacyTimestamp
+	RegRxTopTimestampEnable
+	RegRxTopTimestampNbSymb
+	RegRxTopLoraServiceFskTimestampEnable
+	RegRxTopLoraServiceFskTimestampNbSymb
+	RegRadioFeCtrl0RadioADcNotchEn
+	RegRadioFeCtrl0RadioAHostFilterGain
+	RegRadioFeRssiDbDefRadioARssiDbDefaultValue
+	RegRadioFeRssiDecDefRadioARssiDecDefaultValue
+	RegRadioFeRssiBbFilterAlphaRadioARssiBbFilterAlpha
+	RegRadioFeRssiDecFilterAlphaRadioARssiDecFilterAlpha
+	RegRadioFeDcOffsetIRadioADcOffsetI
+	RegRadioFeDcOffsetQRadioADcOffsetQ
+	RegRadioFeCtrl0RadioBDcNotchEn
+	RegRadioFeCtrl0RadioBHostFilterGain
+	RegRadioFeRssiDbDefRadioBRssiDbDefaultValue
+	RegRadioFeRssiDecDefRadioBRssiDecDefaultValue
+	RegRadioFeRssiBbFilterAlphaRadioBRssiBbFilterAlpha
+	RegRadioFeRssiDecFilterAlphaRadioBRssiDecFilterAlpha
+	RegRadioFeDcOffsetIRadioBDcOffsetI
+	RegRadioFeDcOffsetQRadioBDcOffsetQ
+	RegAgcMcuCtrlMcuClear
+	RegAgcMcuCtrlHostProg
+	RegAgcMcuCtrlParityError
+	RegAgcMcuMcuAgcStatusMcuAgcStatus
+	RegAgcMcuPaGainPaAGain
+	RegAgcMcuPaGainPaBGain
+	RegAgcMcuRfEnARadioRst
+	RegAgcMcuRfEnARadioEn
+	RegAgcMcuRfEnAPaEn
+	RegAgcMcuRfEnALnaEn
+	RegAgcMcuRfEnBRadioRst
+	RegAgcMcuRfEnBRadioEn
+	RegAgcMcuRfEnBPaEn
+	RegAgcMcuRfEnBLnaEn
+	RegAgcMcuLutTableAPaLut
+	RegAgcMcuLutTableALnaLut
+	RegAgcMcuLutTableBPaLut
+	RegAgcMcuLutTableBLnaLut
+	RegAgcMcuMcuMailBoxWrDataByte0McuMailBoxWrData
+	RegAgcMcuMcuMailBoxWrDataByte1McuMailBoxWrData
+	RegAgcMcuMcuMailBoxWrDataByte2McuMailBoxWrData
+	RegAgcMcuMcuMailBoxWrDataByte3McuMailBoxWrData
+	RegAgcMcuMcuMailBoxRdDataByte0McuMailBoxRdData
+	RegAgcMcuMcuMailBoxRdDataByte1McuMailBoxRdData
+	RegAgcMcuMcuMailBoxRdDataByte2McuMailBoxRdData
+	RegAgcMcuMcuMailBoxRdDataByte3McuMailBoxRdData
+	RegArbMcuCtrlMcuClear
+	RegArbMcuCtrlHostProg
+	RegArbMcuCtrlParityError
+	RegArbMcuMcuArbStatusMcuArbStatus
+	RegArbMcuArbDebugCfg0ArbDebugCfg0
+	RegArbMcuArbDebugCfg1ArbDebugCfg1
+	RegArbMcuArbDebugCfg2ArbDebugCfg2
+	RegArbMcuArbDebugCfg3ArbDebugCfg3
+	RegArbMcuArbDebugSts0ArbDebugSts0
+	RegArbMcuArbDebugSts1ArbDebugSts1
+	RegArbMcuArbDebugSts2ArbDebugSts2
+	RegArbMcuArbDebugSts3ArbDebugSts3
+	RegArbMcuArbDebugSts4ArbDebugSts4
+	RegArbMcuArbDebugSts5ArbDebugSts5
+	RegArbMcuArbDebugSts6ArbDebugSts6
+	RegArbMcuArbDebugSts7ArbDebugSts7
+	RegArbMcuArbDebugSts8ArbDebugSts8
+	RegArbMcuArbDebugSts9ArbDebugSts9
+	RegArbMcuArbDebugSts10ArbDebugSts10
+	RegArbMcuArbDebugSts11ArbDebugSts11
+	RegArbMcuArbDebugSts12ArbDebugSts12
+	RegArbMcuArbDebugSts13ArbDebugSts13
+	RegArbMcuArbDebugSts14ArbDebugSts14
+	RegArbMcuArbDebugSts15ArbDebugSts15
+	RegTimestampGpsCtrlGpsEn
+	RegTimestampGpsCtrlGpsPol
+	RegTimestampTimestampPpsMsb2TimestampPps
+	RegTimestampTimestampPpsMsb1TimestampPps
+	RegTimestampTimestampPpsLsb2TimestampPps
+	RegTimestampTimestampPpsLsb1TimestampPps
+	RegTimestampTimestampMsb2Timestamp
+	RegTimestampTimestampMsb1Timestamp
+	RegTimestampTimestampLsb2Timestamp
+	RegTimestampTimestampLsb1Timestamp
+	RegTimestampTimestampCtrlEnable
+	RegTxTopATxTrigTxTrigImmediate
+	RegTxTopATxTrigTxTrigDelayed
+	RegTxTopATxTrigTxTrigGps
+	RegTxTopATimerTrigByte3TimerDelayedTrig
+	RegTxTopATimerTrigByte2TimerDelayedTrig
+	RegTxTopATimerTrigByte1TimerDelayedTrig
+	RegTxTopATimerTrigByte0TimerDelayedTrig
+	RegTxTopATxStartDelayMsbTxStartDelay
+	RegTxTopATxStartDelayLsbTxStartDelay
+	RegTxTopATxCtrlWriteBuffer
+	RegTxTopATxRampDurationTxRampDuration
+	RegTxTopAGenCfg0ModulationType
+	RegTxTopATxRffeIfCtrlTxMode
+	RegTxTopATxRffeIfCtrlTxIfSrc
+	RegTxTopATxRffeIfCtrlTxClkEdge
+	RegTxTopATxRffeIfFreqRfHFreqRf
+	RegTxTopATxRffeIfFreqRfMFreqRf
+	RegTxTopATxRffeIfFreqRfLFreqRf
+	RegTxTopATxRffeIfFreqDevHFreqDev
+	RegTxTopATxRffeIfFreqDevLFreqDev
+	RegTxTopATxFsmStatusTxStatus
+	RegTxTopATxRffeIfIqGainIqGain
+	RegTxTopATxRffeIfIOffsetIOffset
+	RegTxTopATxRffeIfQOffsetQOffset
+	RegTxTopAAgcTxBwAgcTxPaGain
+	RegTxTopAAgcTxPwrAgcDriveTxPwr
+	RegTxTopATxrxCfg00ModemBw
+	RegTxTopATxrxCfg00ModemSf
+	RegTxTopATxrxCfg01CodingRate
+	RegTxTopATxrxCfg01PpmOffset
+	RegTxTopATxrxCfg01PostPreambleGapLong
+	RegTxTopATxrxCfg02FineSynchEn
+	RegTxTopATxrxCfg02ModemEn
+	RegTxTopATxrxCfg02ImplicitHeader
+	RegTxTopATxrxCfg02CrcEn
+	RegTxTopATxrxCfg03PayloadLength
+	RegTxTopATxrxCfg10InvertIq
+	RegTxTopATxrxCfg11PreambleSymbNbMsb
+	RegTxTopATxrxCfg12PreambleSymbNbLsb
+	RegTxTopAFrameSynch0Peak1Pos
+	RegTxTopAFrameSynch1Peak2Pos
+	RegTxTopAFskCfg0PktMode
+	RegTxTopAFskCfg0CrcEn
+	RegTxTopAFskCfg0DcfreeEnc
+	RegTxTopAFskCfg0CrcIbm
+	RegTxTopAFskCfg0Psize
+	RegTxTopAFskPreambleSizeMsbPreambleSize
+	RegTxTopAFskPreambleSizeLsbPreambleSize
+	RegTxTopAFskBitRateMsbBitRate
+	RegTxTopAFskBitRateLsbBitRate
+	RegTxTopAFskModFskRefPatternByte0FskRefPattern
+	RegTxTopAFskModFskRefPatternByte1FskRefPattern
+	RegTxTopAFskModFskRefPatternByte2FskRefPattern
+	RegTxTopAFskModFskRefPatternByte3FskRefPattern
+	RegTxTopAFskModFskRefPatternByte4FskRefPattern
+	RegTxTopAFskModFskRefPatternByte5FskRefPattern
+	RegTxTopAFskModFskRefPatternByte6FskRefPattern
+	RegTxTopAFskModFskRefPatternByte7FskRefPattern
+	RegTxTopAFskPktLenPktLen
+	RegTxTopBTxTrigTxTrigImmediate
+	RegTxTopBTxTrigTxTrigDelayed
+	RegTxTopBTxTrigTxTrigGps
+	RegTxTopBTimerTrigByte3TimerDelayedTrig
+	RegTxTopBTimerTrigByte2TimerDelayedTrig
+	RegTxTopBTimerTrigByte1TimerDelayedTrig
+	RegTxTopBTimerTrigByte0TimerDelayedTrig
+	RegTxTopBTxStartDelayMsbTxStartDelay
+	RegTxTopBTxStartDelayLsbTxStartDelay
+	RegTxTopBTxCtrlWriteBuffer
+	RegTxTopBTxRampDurationTxRampDuration
+	RegTxTopBGenCfg0ModulationType
+	RegTxTopBTxRffeIfCtrlTxMode
+	RegTxTopBTxRffeIfCtrlTxIfSrc
+	RegTxTopBTxRffeIfCtrlTxClkEdge
+	RegTxTopBTxRffeIfFreqRfHFreqRf
+	RegTxTopBTxRffeIfFreqRfMFreqRf
+	RegTxTopBTxRffeIfFreqRfLFreqRf
+	RegTxTopBTxRffeIfFreqDevHFreqDev
+	RegTxTopBTxRffeIfFreqDevLFreqDev
+	RegTxTopBTxFsmStatusTxStatus
+	RegTxTopBTxRffeIfIqGainIqGain
+	RegTxTopBTxRffeIfIOffsetIOffset
+	RegTxTopBTxRffeIfQOffsetQOffset
+	RegTxTopBAgcTxBwAgcTxPaGain
+	RegTxTopBAgcTxPwrAgcDriveTxPwr
+	RegTxTopBTxrxCfg00ModemBw
+	RegTxTopBTxrxCfg00ModemSf
+	RegTxTopBTxrxCfg01CodingRate
+	RegTxTopBTxrxCfg01PpmOffset
+	RegTxTopBTxrxCfg01PostPreambleGapLong
+	RegTxTopBTxrxCfg02FineSynchEn
+	RegTxTopBTxrxCfg02ModemEn
+	RegTxTopBTxrxCfg02ImplicitHeader
+	RegTxTopBTxrxCfg02CrcEn
+	RegTxTopBTxrxCfg03PayloadLength
+	RegTxTopBTxrxCfg10InvertIq
+	RegTxTopBTxrxCfg11PreambleSymbNbMsb
+	RegTxTopBTxrxCfg12PreambleSymbNbLsb
+	RegTxTopBFrameSynch0Peak1Pos
+	RegTxTopBFrameSynch1Peak2Pos
+	RegTxTopBFskCfg0PktMode
+	RegTxTopBFskCfg0CrcEn
+	RegTxTopBFskCfg0DcfreeEnc
+	RegTxTopBFskCfg0CrcIbm
+	RegTxTopBFskCfg0Psize
+	RegTxTopBFskPreambleSizeMsbPreambleSize
+	RegTxTopBFskPreambleSizeLsbPreambleSize
+	RegTxTopBFskBitRateMsbBitRate
+	RegTxTopBFskBitRateLsbBitRate
+	RegTxTopBFskModFskRefPatternByte0FskRefPattern
+	RegTxTopBFskModFskRefPatternByte1FskRefPattern
+	RegTxTopBFskModFskRefPatternByte2FskRefPattern
+	RegTxTopBFskModFskRefPatternByte3FskRefPattern
+	RegTxTopBFskModFskRefPatternByte4FskRefPattern
+	RegTxTopBFskModFskRefPatternByte5FskRefPattern
+	RegTxTopBFskModFskRefPatternByte6FskRefPattern
+	RegTxTopBFskModFskRefPatternByte7FskRefPattern
+	RegTxTopBFskPktLenPktLen
+	RegOtpByteAddrAddr
+	RegOtpRdDataRdData
+
+	// regCount is the total number of registers in the register map
+	regCount
+)
+
+// registers is the SX1302 register map indexed by RegID
+var registers = [regCount]Register{
+	RegCommonPagePage:                                              {Name: "COMMON_PAGE_PAGE", Page: PageAll, Addr: 0x5600, Offs: 0, Leng: 6, Check: true},
+	RegCommonCtrl0Clk32RifCtrl:                                     {Name: "COMMON_CTRL0_CLK32_RIF_CTRL", Page: 0, Addr: 0x5601, Offs: 0, Leng: 2, Check: true},
+	RegCommonCtrl0HostRadioCtrl:                                    {Name: "COMMON_CTRL0_HOST_RADIO_CTRL", Page: 0, Addr: 0x5601, Offs: 2, Leng: 1, Check: true, Default: 1},
+	RegCommonCtrl0RadioMiscEn:                                      {Name: "COMMON_CTRL0_RADIO_MISC_EN", Page: 0, Addr: 0x5601, Offs: 3, Leng: 1, Check: true},
+	RegCommonCtrl0Sx1261ModeRadioA:                                 {Name: "COMMON_CTRL0_SX1261_MODE_RADIO_A", Page: 0, Addr: 0x5601, Offs: 4, Leng: 1, Check: true},
+	RegCommonCtrl0Sx1261ModeRadioB:                                 {Name: "COMMON_CTRL0_SX1261_MODE_RADIO_B", Page: 0, Addr: 0x5601, Offs: 5, Leng: 1, Check: true},
+	RegCommonCtrl1SwapIqRadioA:                                     {Name: "COMMON_CTRL1_SWAP_IQ_RADIO_A", Page: 0, Addr: 0x5602, Offs: 0, Leng: 1, Check: true},
+	RegCommonCtrl1SwapIqRadioB:                                     {Name: "COMMON_CTRL1_SWAP_IQ_RADIO_B", Page: 0, Addr: 0x5602, Offs: 1, Leng: 1, Check: true},
+	RegCommonCtrl1SamplingEdgeRadioA:                               {Name: "COMMON_CTRL1_SAMPLING_EDGE_RADIO_A", Page: 0, Addr: 0x5602, Offs: 2, Leng: 1, Check: true},
+	RegCommonCtrl1SamplingEdgeRadioB:                               {Name: "COMMON_CTRL1_SAMPLING_EDGE_RADIO_B", Page: 0, Addr: 0x5602, Offs: 3, Leng: 1, Check: true},
+	RegCommonSpiDivRatioSpiHalfPeriod:                              {Name: "COMMON_SPI_DIV_RATIO_SPI_HALF_PERIOD", Page: 0, Addr: 0x5603, Offs: 0, Leng: 8, Check: true, Default: 2},
+	RegCommonRadioSelectRadioSelect:                                {Name: "COMMON_RADIO_SELECT_RADIO_SELECT", Page: 0, Addr: 0x5604, Offs: 0, Leng: 8, Check: true},
+	RegCommonGenConcentratorModemEnable:                            {Name: "COMMON_GEN_CONCENTRATOR_MODEM_ENABLE", Page: 0, Addr: 0x5605, Offs: 0, Leng: 1, Check: true},
+	RegCommonGenMbwssfModemEnable:                                  {Name: "COMMON_GEN_MBWSSF_MODEM_ENABLE", Page: 0, Addr: 0x5605, Offs: 1, Leng: 1, Check: true},
+	RegCommonGenFskModemEnable:                                     {Name: "COMMON_GEN_FSK_MODEM_ENABLE", Page: 0, Addr: 0x5605, Offs: 2, Leng: 1, Check: true},
+	RegCommonGenGlobalEn:                                           {Name: "COMMON_GEN_GLOBAL_EN", Page: 0, Addr: 0x5605, Offs: 3, Leng: 1, Check: true},
+	RegCommonVersionVersion:                                        {Name: "COMMON_VERSION_VERSION", Page: 0, Addr: 0x5606, Offs: 0, Leng: 8, ReadOnly: true, Check: true, Default: 0x10},
+	RegCommonDummyDummy:                                            {Name: "COMMON_DUMMY_DUMMY", Page: 0, Addr: 0x5607, Offs: 0, Leng: 8, Check: true},
+	RegClkCtrlClkSelClkRadioASel:                                   {Name: "CLK_CTRL_CLK_SEL_CLK_RADIO_A_SEL", Page: 0, Addr: 0x5620, Offs: 0, Leng: 1, Check: true},
+	RegClkCtrlClkSelClkRadioBSel:                                   {Name: "CLK_CTRL_CLK_SEL_CLK_RADIO_B_SEL", Page: 0, Addr: 0x5620, Offs: 1, Leng: 1, Check: true},
+	RegClkCtrlClkSelClkdivEn:                                       {Name: "CLK_CTRL_CLK_SEL_CLKDIV_EN", Page: 0, Addr: 0x5620, Offs: 2, Leng: 1, Check: true, Default: 1},
+	RegClkCtrlDummyDummy:                                           {Name: "CLK_CTRL_DUMMY_DUMMY", Page: 0, Addr: 0x5621, Offs: 0, Leng: 8, Check: true},
+	RegGpioGpioDirHDirection:                                       {Name: "GPIO_GPIO_DIR_H_DIRECTION", Page: 0, Addr: 0x5640, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioDirLDirection:                                       {Name: "GPIO_GPIO_DIR_L_DIRECTION", Page: 0, Addr: 0x5641, Offs: 0, Leng: 8, Check: true},
+	RegGpioGpioOutHOutValue:                                        {Name: "GPIO_GPIO_OUT_H_OUT_VALUE", Page: 0, Addr: 0x5642, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioOutLOutValue:                                        {Name: "GPIO_GPIO_OUT_L_OUT_VALUE", Page: 0, Addr: 0x5643, Offs: 0, Leng: 8, Check: true},
+	RegGpioGpioInHInValue:                                          {Name: "GPIO_GPIO_IN_H_IN_VALUE", Page: 0, Addr: 0x5644, Offs: 0, Leng: 4, ReadOnly: true},
+	RegGpioGpioInLInValue:                                          {Name: "GPIO_GPIO_IN_L_IN_VALUE", Page: 0, Addr: 0x5645, Offs: 0, Leng: 8, ReadOnly: true},
+	RegGpioGpioPdHPdEn:                                             {Name: "GPIO_GPIO_PD_H_PD_EN", Page: 0, Addr: 0x5646, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioPdLPdEn:                                             {Name: "GPIO_GPIO_PD_L_PD_EN", Page: 0, Addr: 0x5647, Offs: 0, Leng: 8, Check: true},
+	RegGpioGpioSel0Selection:                                       {Name: "GPIO_GPIO_SEL_0_SELECTION", Page: 0, Addr: 0x5648, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel1Selection:                                       {Name: "GPIO_GPIO_SEL_1_SELECTION", Page: 0, Addr: 0x5649, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel2Selection:                                       {Name: "GPIO_GPIO_SEL_2_SELECTION", Page: 0, Addr: 0x564A, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel3Selection:                                       {Name: "GPIO_GPIO_SEL_3_SELECTION", Page: 0, Addr: 0x564B, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel4Selection:                                       {Name: "GPIO_GPIO_SEL_4_SELECTION", Page: 0, Addr: 0x564C, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel5Selection:                                       {Name: "GPIO_GPIO_SEL_5_SELECTION", Page: 0, Addr: 0x564D, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel6Selection:                                       {Name: "GPIO_GPIO_SEL_6_SELECTION", Page: 0, Addr: 0x564E, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel7Selection:                                       {Name: "GPIO_GPIO_SEL_7_SELECTION", Page: 0, Addr: 0x564F, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel8Selection:                                       {Name: "GPIO_GPIO_SEL_8_SELECTION", Page: 0, Addr: 0x5650, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel9Selection:                                       {Name: "GPIO_GPIO_SEL_9_SELECTION", Page: 0, Addr: 0x5651, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel10Selection:                                      {Name: "GPIO_GPIO_SEL_10_SELECTION", Page: 0, Addr: 0x5652, Offs: 0, Leng: 4, Check: true},
+	RegGpioGpioSel11Selection:                                      {Name: "GPIO_GPIO_SEL_11_SELECTION", Page: 0, Addr: 0x5653, Offs: 0, Leng: 4, Check: true},
+	RegRxTopRadioSelectRadioSelect:                                 {Name: "RX_TOP_RADIO_SELECT_RADIO_SELECT", Page: 0, Addr: 0x5100, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFreq0MsbIfFreq0:                                        {Name: "RX_TOP_FREQ_0_MSB_IF_FREQ_0", Page: 0, Addr: 0x5101, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopFreq0LsbIfFreq0:                                        {Name: "RX_TOP_FREQ_0_LSB_IF_FREQ_0", Page: 0, Addr: 0x5102, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFreq1MsbIfFreq1:                                        {Name: "RX_TOP_FREQ_1_MSB_IF_FREQ_1", Page: 0, Addr: 0x5103, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopFreq1LsbIfFreq1:                                        {Name: "RX_TOP_FREQ_1_LSB_IF_FREQ_1", Page: 0, Addr: 0x5104, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFreq2MsbIfFreq2:                                        {Name: "RX_TOP_FREQ_2_MSB_IF_FREQ_2", Page: 0, Addr: 0x5105, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopFreq2LsbIfFreq2:                                        {Name: "RX_TOP_FREQ_2_LSB_IF_FREQ_2", Page: 0, Addr: 0x5106, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFreq3MsbIfFreq3:                                        {Name: "RX_TOP_FREQ_3_MSB_IF_FREQ_3", Page: 0, Addr: 0x5107, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopFreq3LsbIfFreq3:                                        {Name: "RX_TOP_FREQ_3_LSB_IF_FREQ_3", Page: 0, Addr: 0x5108, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFreq4MsbIfFreq4:                                        {Name: "RX_TOP_FREQ_4_MSB_IF_FREQ_4", Page: 0, Addr: 0x5109, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopFreq4LsbIfFreq4:                                        {Name: "RX_TOP_FREQ_4_LSB_IF_FREQ_4", Page: 0, Addr: 0x510A, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFreq5MsbIfFreq5:                                        {Name: "RX_TOP_FREQ_5_MSB_IF_FREQ_5", Page: 0, Addr: 0x510B, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopFreq5LsbIfFreq5:                                        {Name: "RX_TOP_FREQ_5_LSB_IF_FREQ_5", Page: 0, Addr: 0x510C, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFreq6MsbIfFreq6:                                        {Name: "RX_TOP_FREQ_6_MSB_IF_FREQ_6", Page: 0, Addr: 0x510D, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopFreq6LsbIfFreq6:                                        {Name: "RX_TOP_FREQ_6_LSB_IF_FREQ_6", Page: 0, Addr: 0x510E, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFreq7MsbIfFreq7:                                        {Name: "RX_TOP_FREQ_7_MSB_IF_FREQ_7", Page: 0, Addr: 0x510F, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopFreq7LsbIfFreq7:                                        {Name: "RX_TOP_FREQ_7_LSB_IF_FREQ_7", Page: 0, Addr: 0x5110, Offs: 0, Leng: 8, Check: true},
+	RegRxTopLoraServiceFskRadioSelectLoraService:                   {Name: "RX_TOP_LORA_SERVICE_FSK_RADIO_SELECT_LORA_SERVICE", Page: 0, Addr: 0x5111, Offs: 0, Leng: 1, Check: true},
+	RegRxTopLoraServiceFskRadioSelectFsk:                           {Name: "RX_TOP_LORA_SERVICE_FSK_RADIO_SELECT_FSK", Page: 0, Addr: 0x5111, Offs: 1, Leng: 1, Check: true},
+	RegRxTopLoraServiceFskFreqMsbLoraService:                       {Name: "RX_TOP_LORA_SERVICE_FSK_FREQ_MSB_LORA_SERVICE", Page: 0, Addr: 0x5112, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopLoraServiceFskFreqLsbLoraService:                       {Name: "RX_TOP_LORA_SERVICE_FSK_FREQ_LSB_LORA_SERVICE", Page: 0, Addr: 0x5113, Offs: 0, Leng: 8, Check: true},
+	RegRxTopLoraServiceFskFreqMsbFsk:                               {Name: "RX_TOP_LORA_SERVICE_FSK_FREQ_MSB_FSK", Page: 0, Addr: 0x5114, Offs: 0, Sign: true, Leng: 5, Check: true},
+	RegRxTopLoraServiceFskFreqLsbFsk:                               {Name: "RX_TOP_LORA_SERVICE_FSK_FREQ_LSB_FSK", Page: 0, Addr: 0x5115, Offs: 0, Leng: 8, Check: true},
+	RegRxTopCorrClockEnableClkEn:                                   {Name: "RX_TOP_CORR_CLOCK_ENABLE_CLK_EN", Page: 0, Addr: 0x5116, Offs: 0, Leng: 8, Check: true},
+	RegRxTopCorrelatorEnCorrEn:                                     {Name: "RX_TOP_CORRELATOR_EN_CORR_EN", Page: 0, Addr: 0x5117, Offs: 0, Leng: 8, Check: true},
+	RegRxTopCorrelatorSfEnCorrSfEn:                                 {Name: "RX_TOP_CORRELATOR_SF_EN_CORR_SF_EN", Page: 0, Addr: 0x5118, Offs: 0, Leng: 8, Check: true, Default: 0xFF},
+	RegRxTopCorrelatorEnableOnlyFirstDetEdgeEnableOnlyFirstDetEdge: {Name: "RX_TOP_CORRELATOR_ENABLE_ONLY_FIRST_DET_EDGE_ENABLE_ONLY_FIRST_DET_EDGE", Page: 0, Addr: 0x5119, Offs: 0, Leng: 8, Check: true},
+	RegRxTopCorrelatorEnableAccClearEnableCorrAccClear:             {Name: "RX_TOP_CORRELATOR_ENABLE_ACC_CLEAR_ENABLE_CORR_ACC_CLEAR", Page: 0, Addr: 0x511A, Offs: 0, Leng: 8, Check: true},
+	RegRxTopDcNotchCfg1Enable:                                      {Name: "RX_TOP_DC_NOTCH_CFG1_ENABLE", Page: 0, Addr: 0x511B, Offs: 0, Leng: 1, Check: true},
+	RegRxTopRxDfeAgc1ForceDefaultFir:                               {Name: "RX_TOP_RX_DFE_AGC1_FORCE_DEFAULT_FIR", Page: 0, Addr: 0x511C, Offs: 0, Leng: 1, Check: true},
+	RegRxTopModemPpmOffsetSf11:                                     {Name: "RX_TOP_MODEM_PPM_OFFSET_SF11", Page: 0, Addr: 0x511D, Offs: 0, Leng: 1, Check: true},
+	RegRxTopModemPpmOffsetSf12:                                     {Name: "RX_TOP_MODEM_PPM_OFFSET_SF12", Page: 0, Addr: 0x511D, Offs: 1, Leng: 1, Check: true},
+	RegRxTopFrameSynch0Sf5Peak1PosSf5:                              {Name: "RX_TOP_FRAME_SYNCH0_SF5_PEAK1_POS_SF5", Page: 0, Addr: 0x5120, Offs: 0, Leng: 4, Check: true, Default: 2},
+	RegRxTopFrameSynch1Sf5Peak2PosSf5:                              {Name: "RX_TOP_FRAME_SYNCH1_SF5_PEAK2_POS_SF5", Page: 0, Addr: 0x5121, Offs: 0, Leng: 4, Check: true, Default: 4},
+	RegRxTopFrameSynch0Sf6Peak1PosSf6:                              {Name: "RX_TOP_FRAME_SYNCH0_SF6_PEAK1_POS_SF6", Page: 0, Addr: 0x5122, Offs: 0, Leng: 4, Check: true, Default: 2},
+	RegRxTopFrameSynch1Sf6Peak2PosSf6:                              {Name: "RX_TOP_FRAME_SYNCH1_SF6_PEAK2_POS_SF6", Page: 0, Addr: 0x5123, Offs: 0, Leng: 4, Check: true, Default: 4},
+	RegRxTopFrameSynch0Sf7to12Peak1PosSf7to12:                      {Name: "RX_TOP_FRAME_SYNCH0_SF7TO12_PEAK1_POS_SF7TO12", Page: 0, Addr: 0x5124, Offs: 0, Leng: 4, Check: true, Default: 2},
+	RegRxTopFrameSynch1Sf7to12Peak2PosSf7to12:                      {Name: "RX_TOP_FRAME_SYNCH1_SF7TO12_PEAK2_POS_SF7TO12", Page: 0, Addr: 0x5125, Offs: 0, Leng: 4, Check: true, Default: 4},
+	RegRxTopLoraServiceFskFrameSynch0Peak1Pos:                      {Name: "RX_TOP_LORA_SERVICE_FSK_FRAME_SYNCH0_PEAK1_POS", Page: 0, Addr: 0x5126, Offs: 0, Leng: 4, Check: true, Default: 2},
+	RegRxTopLoraServiceFskFrameSynch1Peak2Pos:                      {Name: "RX_TOP_LORA_SERVICE_FSK_FRAME_SYNCH1_PEAK2_POS", Page: 0, Addr: 0x5127, Offs: 0, Leng: 4, Check: true, Default: 4},
+	RegRxTopLoraServiceCfg0RateSf:                                  {Name: "RX_TOP_LORA_SERVICE_CFG0_RATE_SF", Page: 0, Addr: 0x5128, Offs: 0, Leng: 4, Check: true, Default: 7},
+	RegRxTopLoraServiceCfg0ModemBw:                                 {Name: "RX_TOP_LORA_SERVICE_CFG0_MODEM_BW", Page: 0, Addr: 0x5128, Offs: 4, Leng: 4, Check: true, Default: 5},
+	RegRxTopLoraServiceCfg1CodingRate:                              {Name: "RX_TOP_LORA_SERVICE_CFG1_CODING_RATE", Page: 0, Addr: 0x5129, Offs: 0, Leng: 3, Check: true, Default: 1},
+	RegRxTopLoraServiceCfg1CrcEn:                                   {Name: "RX_TOP_LORA_SERVICE_CFG1_CRC_EN", Page: 0, Addr: 0x5129, Offs: 3, Leng: 1, Check: true, Default: 1},
+	RegRxTopLoraServiceCfg1ImplicitHeader:                          {Name: "RX_TOP_LORA_SERVICE_CFG1_IMPLICIT_HEADER", Page: 0, Addr: 0x5129, Offs: 4, Leng: 1, Check: true},
+	RegRxTopLoraServiceCfg2PayloadLength:                           {Name: "RX_TOP_LORA_SERVICE_CFG2_PAYLOAD_LENGTH", Page: 0, Addr: 0x512A, Offs: 0, Leng: 8, Check: true},
+	RegRxTopLoraServiceCfg3ModemEn:                                 {Name: "RX_TOP_LORA_SERVICE_CFG3_MODEM_EN", Page: 0, Addr: 0x512B, Offs: 0, Leng: 1, Check: true},
+	RegRxTopFskCfg0Bw:                                              {Name: "RX_TOP_FSK_CFG0_BW", Page: 0, Addr: 0x5130, Offs: 0, Leng: 3, Check: true},
+	RegRxTopFskCfg0Psize:                                           {Name: "RX_TOP_FSK_CFG0_PSIZE", Page: 0, Addr: 0x5130, Offs: 3, Leng: 3, Check: true},
+	RegRxTopFskCfg0CrcEn:                                           {Name: "RX_TOP_FSK_CFG0_CRC_EN", Page: 0, Addr: 0x5130, Offs: 6, Leng: 1, Check: true},
+	RegRxTopFskCfg0CrcIbm:                                          {Name: "RX_TOP_FSK_CFG0_CRC_IBM", Page: 0, Addr: 0x5130, Offs: 7, Leng: 1, Check: true},
+	RegRxTopFskCfg1DcfreeEnc:                                       {Name: "RX_TOP_FSK_CFG1_DCFREE_ENC", Page: 0, Addr: 0x5131, Offs: 0, Leng: 2, Check: true},
+	RegRxTopFskCfg1PktMode:                                         {Name: "RX_TOP_FSK_CFG1_PKT_MODE", Page: 0, Addr: 0x5131, Offs: 2, Leng: 1, Check: true, Default: 1},
+	RegRxTopFskCfg1AdrsComp:                                        {Name: "RX_TOP_FSK_CFG1_ADRS_COMP", Page: 0, Addr: 0x5131, Offs: 3, Leng: 2, Check: true},
+	RegRxTopFskBitRateMsbBitRate:                                   {Name: "RX_TOP_FSK_BIT_RATE_MSB_BIT_RATE", Page: 0, Addr: 0x5132, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskBitRateLsbBitRate:                                   {Name: "RX_TOP_FSK_BIT_RATE_LSB_BIT_RATE", Page: 0, Addr: 0x5133, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskRefPatternByte0FskRefPattern:                        {Name: "RX_TOP_FSK_REF_PATTERN_BYTE0_FSK_REF_PATTERN", Page: 0, Addr: 0x5134, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskRefPatternByte1FskRefPattern:                        {Name: "RX_TOP_FSK_REF_PATTERN_BYTE1_FSK_REF_PATTERN", Page: 0, Addr: 0x5135, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskRefPatternByte2FskRefPattern:                        {Name: "RX_TOP_FSK_REF_PATTERN_BYTE2_FSK_REF_PATTERN", Page: 0, Addr: 0x5136, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskRefPatternByte3FskRefPattern:                        {Name: "RX_TOP_FSK_REF_PATTERN_BYTE3_FSK_REF_PATTERN", Page: 0, Addr: 0x5137, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskRefPatternByte4FskRefPattern:                        {Name: "RX_TOP_FSK_REF_PATTERN_BYTE4_FSK_REF_PATTERN", Page: 0, Addr: 0x5138, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskRefPatternByte5FskRefPattern:                        {Name: "RX_TOP_FSK_REF_PATTERN_BYTE5_FSK_REF_PATTERN", Page: 0, Addr: 0x5139, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskRefPatternByte6FskRefPattern:                        {Name: "RX_TOP_FSK_REF_PATTERN_BYTE6_FSK_REF_PATTERN", Page: 0, Addr: 0x513A, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskRefPatternByte7FskRefPattern:                        {Name: "RX_TOP_FSK_REF_PATTERN_BYTE7_FSK_REF_PATTERN", Page: 0, Addr: 0x513B, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskPktLenPktLen:                                        {Name: "RX_TOP_FSK_PKT_LEN_PKT_LEN", Page: 0, Addr: 0x513C, Offs: 0, Leng: 8, Check: true, Default: 0xFF},
+	RegRxTopFskNodeAdrsNodeAdrs:                                    {Name: "RX_TOP_FSK_NODE_ADRS_NODE_ADRS", Page: 0, Addr: 0x513D, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskBroadcastBroadcast:                                  {Name: "RX_TOP_FSK_BROADCAST_BROADCAST", Page: 0, Addr: 0x513E, Offs: 0, Leng: 8, Check: true},
+	RegRxTopFskAutoAfcOnAutoAfcOn:                                  {Name: "RX_TOP_FSK_AUTO_AFC_ON_AUTO_AFC_ON", Page: 0, Addr: 0x513F, Offs: 0, Leng: 1, Check: true},
+	RegRxTopFskErrorOsrTolErrorOsrTol:                              {Name: "RX_TOP_FSK_ERROR_OSR_TOL_ERROR_OSR_TOL", Page: 0, Addr: 0x5140, Offs: 0, Leng: 5, Check: true},
+	RegRxTopRxBufferNbBytesMsbRxBufferNbBytes:                      {Name: "RX_TOP_RX_BUFFER_NB_BYTES_MSB_RX_BUFFER_NB_BYTES", Page: 0, Addr: 0x5150, Offs: 0, Leng: 8, ReadOnly: true},
+	RegRxTopRxBufferNbBytesLsbRxBufferNbBytes:                      {Name: "RX_TOP_RX_BUFFER_NB_BYTES_LSB_RX_BUFFER_NB_BYTES", Page: 0, Addr: 0x5151, Offs: 0, Leng: 8, ReadOnly: true},
+	RegRxTopRxBufferLastAddrReadMsbLastAddrRead:                    {Name: "RX_TOP_RX_BUFFER_LAST_ADDR_READ_MSB_LAST_ADDR_READ", Page: 0, Addr: 0x5152, Offs: 0, Leng: 8, ReadOnly: true},
+	RegRxTopRxBufferLastAddrReadLsbLastAddrRead:                    {Name: "RX_TOP_RX_BUFFER_LAST_ADDR_READ_LSB_LAST_ADDR_READ", Page: 0, Addr: 0x5153, Offs: 0, Leng: 8, ReadOnly: true},
+	RegRxTopRxBufferTimestampCfgMaxTsMetrics:                       {Name: "RX_TOP_RX_BUFFER_TIMESTAMP_CFG_MAX_TS_METRICS", Page: 0, Addr: 0x5154, Offs: 0, Leng: 7, Check: true},
+	RegRxTopRxBufferLegacyTimestampLegacyTimestamp:                 {Name: "RX_TOP_RX_BUFFER_LEGACY_TIMESTAMP_LEGACY_TIMESTAMP", Page: 0, Addr: 0x5155, Offs: 0, Leng: 1, Check: true, Default: 1},
+	RegRxTopTimestampEnable:                                        {Name: "RX_TOP_TIMESTAMP_ENABLE", Page: 0, Addr: 0x5156, Offs: 0, Leng: 1, Check: true},
+	RegRxTopTimestampNbSymb:                                        {Name: "RX_TOP_TIMESTAMP_NB_SYMB", Page: 0, Addr: 0x5156, Offs: 1, Leng: 3, Check: true},
+	RegRxTopLoraServiceFskTimestampEnable:                          {Name: "RX_TOP_LORA_SERVICE_FSK_TIMESTAMP_ENABLE", Page: 0, Addr: 0x5157, Offs: 0, Leng: 1, Check: true},
+	RegRxTopLoraServiceFskTimestampNbSymb:                          {Name: "RX_TOP_LORA_SERVICE_FSK_TIMESTAMP_NB_SYMB", Page: 0, Addr: 0x5157, Offs: 1, Leng: 3, Check: true},
+	RegRadioFeCtrl0RadioADcNotchEn:                                 {Name: "RADIO_FE_CTRL0_RADIO_A_DC_NOTCH_EN", Page: 0, Addr: 0x5700, Offs: 0, Leng: 1, Check: true},
+	RegRadioFeCtrl0RadioAHostFilterGain:                            {Name: "RADIO_FE_CTRL0_RADIO_A_HOST_FILTER_GAIN", Page: 0, Addr: 0x5700, Offs: 1, Leng: 4, Check: true},
+	RegRadioFeRssiDbDefRadioARssiDbDefaultValue:                    {Name: "RADIO_FE_RSSI_DB_DEF_RADIO_A_RSSI_DB_DEFAULT_VALUE", Page: 0, Addr: 0x5701, Offs: 0, Leng: 6, Check: true},
+	RegRadioFeRssiDecDefRadioARssiDecDefaultValue:                  {Name: "RADIO_FE_RSSI_DEC_DEF_RADIO_A_RSSI_DEC_DEFAULT_VALUE", Page: 0, Addr: 0x5702, Offs: 0, Leng: 8, Check: true},
+	RegRadioFeRssiBbFilterAlphaRadioARssiBbFilterAlpha:             {Name: "RADIO_FE_RSSI_BB_FILTER_ALPHA_RADIO_A_RSSI_BB_FILTER_ALPHA", Page: 0, Addr: 0x5703, Offs: 0, Leng: 4, Check: true, Default: 6},
+	RegRadioFeRssiDecFilterAlphaRadioARssiDecFilterAlpha:           {Name: "RADIO_FE_RSSI_DEC_FILTER_ALPHA_RADIO_A_RSSI_DEC_FILTER_ALPHA", Page: 0, Addr: 0x5704, Offs: 0, Leng: 4, Check: true, Default: 7},
+	RegRadioFeDcOffsetIRadioADcOffsetI:                             {Name: "RADIO_FE_DC_OFFSET_I_RADIO_A_DC_OFFSET_I", Page: 0, Addr: 0x5705, Offs: 0, Sign: true, Leng: 8, Check: true},
+	RegRadioFeDcOffsetQRadioADcOffsetQ:                             {Name: "RADIO_FE_DC_OFFSET_Q_RADIO_A_DC_OFFSET_Q", Page: 0, Addr: 0x5706, Offs: 0, Sign: true, Leng: 8, Check: true},
+	RegRadioFeCtrl0RadioBDcNotchEn:                                 {Name: "RADIO_FE_CTRL0_RADIO_B_DC_NOTCH_EN", Page: 0, Addr: 0x5708, Offs: 0, Leng: 1, Check: true},
+	RegRadioFeCtrl0RadioBHostFilterGain:                            {Name: "RADIO_FE_CTRL0_RADIO_B_HOST_FILTER_GAIN", Page: 0, Addr: 0x5708, Offs: 1, Leng: 4, Check: true},
+	RegRadioFeRssiDbDefRadioBRssiDbDefaultValue:                    {Name: "RADIO_FE_RSSI_DB_DEF_RADIO_B_RSSI_DB_DEFAULT_VALUE", Page: 0, Addr: 0x5709, Offs: 0, Leng: 6, Check: true},
+	RegRadioFeRssiDecDefRadioBRssiDecDefaultValue:                  {Name: "RADIO_FE_RSSI_DEC_DEF_RADIO_B_RSSI_DEC_DEFAULT_VALUE", Page: 0, Addr: 0x570A, Offs: 0, Leng: 8, Check: true},
+	RegRadioFeRssiBbFilterAlphaRadioBRssiBbFilterAlpha:             {Name: "RADIO_FE_RSSI_BB_FILTER_ALPHA_RADIO_B_RSSI_BB_FILTER_ALPHA", Page: 0, Addr: 0x570B, Offs: 0, Leng: 4, Check: true, Default: 6},
+	RegRadioFeRssiDecFilterAlphaRadioBRssiDecFilterAlpha:           {Name: "RADIO_FE_RSSI_DEC_FILTER_ALPHA_RADIO_B_RSSI_DEC_FILTER_ALPHA", Page: 0, Addr: 0x570C, Offs: 0, Leng: 4, Check: true, Default: 7},
+	RegRadioFeDcOffsetIRadioBDcOffsetI:                             {Name: "RADIO_FE_DC_OFFSET_I_RADIO_B_DC_OFFSET_I", Page: 0, Addr: 0x570D, Offs: 0, Sign: true, Leng: 8, Check: true},
+	RegRadioFeDcOffsetQRadioBDcOffsetQ:                             {Name: "RADIO_FE_DC_OFFSET_Q_RADIO_B_DC_OFFSET_Q", Page: 0, Addr: 0x570E, Offs: 0, Sign: true, Leng: 8, Check: true},
+	RegAgcMcuCtrlMcuClear:                                          {Name: "AGC_MCU_CTRL_MCU_CLEAR", Page: 0, Addr: 0x5780, Offs: 0, Leng: 1, Check: true},
+	RegAgcMcuCtrlHostProg:                                          {Name: "AGC_MCU_CTRL_HOST_PROG", Page: 0, Addr: 0x5780, Offs: 1, Leng: 1, Check: true},
+	RegAgcMcuCtrlParityError:                                       {Name: "AGC_MCU_CTRL_PARITY_ERROR", Page: 0, Addr: 0x5780, Offs: 2, Leng: 1, ReadOnly: true},
+	RegAgcMcuMcuAgcStatusMcuAgcStatus:                              {Name: "AGC_MCU_MCU_AGC_STATUS_MCU_AGC_STATUS", Page: 0, Addr: 0x5781, Offs: 0, Leng: 8, ReadOnly: true},
+	RegAgcMcuPaGainPaAGain:                                         {Name: "AGC_MCU_PA_GAIN_PA_A_GAIN", Page: 0, Addr: 0x5782, Offs: 0, Leng: 2, Check: true},
+	RegAgcMcuPaGainPaBGain:                                         {Name: "AGC_MCU_PA_GAIN_PA_B_GAIN", Page: 0, Addr: 0x5782, Offs: 2, Leng: 2, Check: true},
+	RegAgcMcuRfEnARadioRst:                                         {Name: "AGC_MCU_RF_EN_A_RADIO_RST", Page: 0, Addr: 0x5783, Offs: 0, Leng: 1, Check: true},
+	RegAgcMcuRfEnARadioEn:                                          {Name: "AGC_MCU_RF_EN_A_RADIO_EN", Page: 0, Addr: 0x5783, Offs: 1, Leng: 1, Check: true},
+	RegAgcMcuRfEnAPaEn:                                             {Name: "AGC_MCU_RF_EN_A_PA_EN", Page: 0, Addr: 0x5783, Offs: 2, Leng: 1, Check: true},
+	RegAgcMcuRfEnALnaEn:                                            {Name: "AGC_MCU_RF_EN_A_LNA_EN", Page: 0, Addr: 0x5783, Offs: 3, Leng: 1, Check: true},
+	RegAgcMcuRfEnBRadioRst:                                         {Name: "AGC_MCU_RF_EN_B_RADIO_RST", Page: 0, Addr: 0x5784, Offs: 0, Leng: 1, Check: true},
+	RegAgcMcuRfEnBRadioEn:                                          {Name: "AGC_MCU_RF_EN_B_RADIO_EN", Page: 0, Addr: 0x5784, Offs: 1, Leng: 1, Check: true},
+	RegAgcMcuRfEnBPaEn:                                             {Name: "AGC_MCU_RF_EN_B_PA_EN", Page: 0, Addr: 0x5784, Offs: 2, Leng: 1, Check: true},
+	RegAgcMcuRfEnBLnaEn:                                            {Name: "AGC_MCU_RF_EN_B_LNA_EN", Page: 0, Addr: 0x5784, Offs: 3, Leng: 1, Check: true},
+	RegAgcMcuLutTableAPaLut:                                        {Name: "AGC_MCU_LUT_TABLE_A_PA_LUT", Page: 0, Addr: 0x5785, Offs: 0, Leng: 4, Check: true},
+	RegAgcMcuLutTableALnaLut:                                       {Name: "AGC_MCU_LUT_TABLE_A_LNA_LUT", Page: 0, Addr: 0x5785, Offs: 4, Leng: 4, Check: true},
+	RegAgcMcuLutTableBPaLut:                                        {Name: "AGC_MCU_LUT_TABLE_B_PA_LUT", Page: 0, Addr: 0x5786, Offs: 0, Leng: 4, Check: true},
+	RegAgcMcuLutTableBLnaLut:                                       {Name: "AGC_MCU_LUT_TABLE_B_LNA_LUT", Page: 0, Addr: 0x5786, Offs: 4, Leng: 4, Check: true},
+	RegAgcMcuMcuMailBoxWrDataByte0McuMailBoxWrData:                 {Name: "AGC_MCU_MCU_MAIL_BOX_WR_DATA_BYTE0_MCU_MAIL_BOX_WR_DATA", Page: 0, Addr: 0x578A, Offs: 0, Leng: 8, Check: true},
+	RegAgcMcuMcuMailBoxWrDataByte1McuMailBoxWrData:                 {Name: "AGC_MCU_MCU_MAIL_BOX_WR_DATA_BYTE1_MCU_MAIL_BOX_WR_DATA", Page: 0, Addr: 0x578B, Offs: 0, Leng: 8, Check: true},
+	RegAgcMcuMcuMailBoxWrDataByte2McuMailBoxWrData:                 {Name: "AGC_MCU_MCU_MAIL_BOX_WR_DATA_BYTE2_MCU_MAIL_BOX_WR_DATA", Page: 0, Addr: 0x578C, Offs: 0, Leng: 8, Check: true},
+	RegAgcMcuMcuMailBoxWrDataByte3McuMailBoxWrData:                 {Name: "AGC_MCU_MCU_MAIL_BOX_WR_DATA_BYTE3_MCU_MAIL_BOX_WR_DATA", Page: 0, Addr: 0x578D, Offs: 0, Leng: 8, Check: true},
+	RegAgcMcuMcuMailBoxRdDataByte0McuMailBoxRdData:                 {Name: "AGC_MCU_MCU_MAIL_BOX_RD_DATA_BYTE0_MCU_MAIL_BOX_RD_DATA", Page: 0, Addr: 0x578E, Offs: 0, Leng: 8, ReadOnly: true},
+	RegAgcMcuMcuMailBoxRdDataByte1McuMailBoxRdData:                 {Name: "AGC_MCU_MCU_MAIL_BOX_RD_DATA_BYTE1_MCU_MAIL_BOX_RD_DATA", Page: 0, Addr: 0x578F, Offs: 0, Leng: 8, ReadOnly: true},
+	RegAgcMcuMcuMailBoxRdDataByte2McuMailBoxRdData:                 {Name: "AGC_MCU_MCU_MAIL_BOX_RD_DATA_BYTE2_MCU_MAIL_BOX_RD_DATA", Page: 0, Addr: 0x5790, Offs: 0, Leng: 8, ReadOnly: true},
+	RegAgcMcuMcuMailBoxRdDataByte3McuMailBoxRdData:                 {Name: "AGC_MCU_MCU_MAIL_BOX_RD_DATA_BYTE3_MCU_MAIL_BOX_RD_DATA", Page: 0, Addr: 0x5791, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuCtrlMcuClear:                                          {Name: "ARB_MCU_CTRL_MCU_CLEAR", Page: 0, Addr: 0x5800, Offs: 0, Leng: 1, Check: true},
+	RegArbMcuCtrlHostProg:                                          {Name: "ARB_MCU_CTRL_HOST_PROG", Page: 0, Addr: 0x5800, Offs: 1, Leng: 1, Check: true},
+	RegArbMcuCtrlParityError:                                       {Name: "ARB_MCU_CTRL_PARITY_ERROR", Page: 0, Addr: 0x5800, Offs: 2, Leng: 1, ReadOnly: true},
+	RegArbMcuMcuArbStatusMcuArbStatus:                              {Name: "ARB_MCU_MCU_ARB_STATUS_MCU_ARB_STATUS", Page: 0, Addr: 0x5801, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugCfg0ArbDebugCfg0:                              {Name: "ARB_MCU_ARB_DEBUG_CFG_0_ARB_DEBUG_CFG_0", Page: 0, Addr: 0x5802, Offs: 0, Leng: 8, Check: true},
+	RegArbMcuArbDebugCfg1ArbDebugCfg1:                              {Name: "ARB_MCU_ARB_DEBUG_CFG_1_ARB_DEBUG_CFG_1", Page: 0, Addr: 0x5803, Offs: 0, Leng: 8, Check: true},
+	RegArbMcuArbDebugCfg2ArbDebugCfg2:                              {Name: "ARB_MCU_ARB_DEBUG_CFG_2_ARB_DEBUG_CFG_2", Page: 0, Addr: 0x5804, Offs: 0, Leng: 8, Check: true},
+	RegArbMcuArbDebugCfg3ArbDebugCfg3:                              {Name: "ARB_MCU_ARB_DEBUG_CFG_3_ARB_DEBUG_CFG_3", Page: 0, Addr: 0x5805, Offs: 0, Leng: 8, Check: true},
+	RegArbMcuArbDebugSts0ArbDebugSts0:                              {Name: "ARB_MCU_ARB_DEBUG_STS_0_ARB_DEBUG_STS_0", Page: 0, Addr: 0x5806, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts1ArbDebugSts1:                              {Name: "ARB_MCU_ARB_DEBUG_STS_1_ARB_DEBUG_STS_1", Page: 0, Addr: 0x5807, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts2ArbDebugSts2:                              {Name: "ARB_MCU_ARB_DEBUG_STS_2_ARB_DEBUG_STS_2", Page: 0, Addr: 0x5808, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts3ArbDebugSts3:                              {Name: "ARB_MCU_ARB_DEBUG_STS_3_ARB_DEBUG_STS_3", Page: 0, Addr: 0x5809, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts4ArbDebugSts4:                              {Name: "ARB_MCU_ARB_DEBUG_STS_4_ARB_DEBUG_STS_4", Page: 0, Addr: 0x580A, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts5ArbDebugSts5:                              {Name: "ARB_MCU_ARB_DEBUG_STS_5_ARB_DEBUG_STS_5", Page: 0, Addr: 0x580B, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts6ArbDebugSts6:                              {Name: "ARB_MCU_ARB_DEBUG_STS_6_ARB_DEBUG_STS_6", Page: 0, Addr: 0x580C, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts7ArbDebugSts7:                              {Name: "ARB_MCU_ARB_DEBUG_STS_7_ARB_DEBUG_STS_7", Page: 0, Addr: 0x580D, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts8ArbDebugSts8:                              {Name: "ARB_MCU_ARB_DEBUG_STS_8_ARB_DEBUG_STS_8", Page: 0, Addr: 0x580E, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts9ArbDebugSts9:                              {Name: "ARB_MCU_ARB_DEBUG_STS_9_ARB_DEBUG_STS_9", Page: 0, Addr: 0x580F, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts10ArbDebugSts10:                            {Name: "ARB_MCU_ARB_DEBUG_STS_10_ARB_DEBUG_STS_10", Page: 0, Addr: 0x5810, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts11ArbDebugSts11:                            {Name: "ARB_MCU_ARB_DEBUG_STS_11_ARB_DEBUG_STS_11", Page: 0, Addr: 0x5811, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts12ArbDebugSts12:                            {Name: "ARB_MCU_ARB_DEBUG_STS_12_ARB_DEBUG_STS_12", Page: 0, Addr: 0x5812, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts13ArbDebugSts13:                            {Name: "ARB_MCU_ARB_DEBUG_STS_13_ARB_DEBUG_STS_13", Page: 0, Addr: 0x5813, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts14ArbDebugSts14:                            {Name: "ARB_MCU_ARB_DEBUG_STS_14_ARB_DEBUG_STS_14", Page: 0, Addr: 0x5814, Offs: 0, Leng: 8, ReadOnly: true},
+	RegArbMcuArbDebugSts15ArbDebugSts15:                            {Name: "ARB_MCU_ARB_DEBUG_STS_15_ARB_DEBUG_STS_15", Page: 0, Addr: 0x5815, Offs: 0, Leng: 8, ReadOnly: true},
+	RegTimestampGpsCtrlGpsEn:                                       {Name: "TIMESTAMP_GPS_CTRL_GPS_EN", Page: 0, Addr: 0x5A00, Offs: 0, Leng: 1, Check: true},
+	RegTimestampGpsCtrlGpsPol:                                      {Name: "TIMESTAMP_GPS_CTRL_GPS_POL", Page: 0, Addr: 0x5A00, Offs: 1, Leng: 1, Check: true, Default: 1},
+	RegTimestampTimestampPpsMsb2TimestampPps:                       {Name: "TIMESTAMP_TIMESTAMP_PPS_MSB2_TIMESTAMP_PPS", Page: 0, Addr: 0x5A01, Offs: 0, Leng: 8, ReadOnly: true},
+	RegTimestampTimestampPpsMsb1TimestampPps:                       {Name: "TIMESTAMP_TIMESTAMP_PPS_MSB1_TIMESTAMP_PPS", Page: 0, Addr: 0x5A02, Offs: 0, Leng: 8, ReadOnly: true},
+	RegTimestampTimestampPpsLsb2TimestampPps:                       {Name: "TIMESTAMP_TIMESTAMP_PPS_LSB2_TIMESTAMP_PPS", Page: 0, Addr: 0x5A03, Offs: 0, Leng: 8, ReadOnly: true},
+	RegTimestampTimestampPpsLsb1TimestampPps:                       {Name: "TIMESTAMP_TIMESTAMP_PPS_LSB1_TIMESTAMP_PPS", Page: 0, Addr: 0x5A04, Offs: 0, Leng: 8, ReadOnly: true},
+	RegTimestampTimestampMsb2Timestamp:                             {Name: "TIMESTAMP_TIMESTAMP_MSB2_TIMESTAMP", Page: 0, Addr: 0x5A05, Offs: 0, Leng: 8, ReadOnly: true},
+	RegTimestampTimestampMsb1Timestamp:                             {Name: "TIMESTAMP_TIMESTAMP_MSB1_TIMESTAMP", Page: 0, Addr: 0x5A06, Offs: 0, Leng: 8, ReadOnly: true},
+	RegTimestampTimestampLsb2Timestamp:                             {Name: "TIMESTAMP_TIMESTAMP_LSB2_TIMESTAMP", Page: 0, Addr: 0x5A07, Offs: 0, Leng: 8, ReadOnly: true},
+	RegTimestampTimestampLsb1Timestamp:                             {Name: "TIMESTAMP_TIMESTAMP_LSB1_TIMESTAMP", Page: 0, Addr: 0x5A08, Offs: 0, Leng: 8, ReadOnly: true},
+	RegTimestampTimestampCtrlEnable:                                {Name: "TIMESTAMP_TIMESTAMP_CTRL_ENABLE", Page: 0, Addr: 0x5A09, Offs: 0, Leng: 1, Check: true},
+	RegTxTopATxTrigTxTrigImmediate:                                 {Name: "TX_TOP_A_TX_TRIG_TX_TRIG_IMMEDIATE", Page: 0, Addr: 0x5C00, Offs: 0, Leng: 1},
+	RegTxTopATxTrigTxTrigDelayed:                                   {Name: "TX_TOP_A_TX_TRIG_TX_TRIG_DELAYED", Page: 0, Addr: 0x5C00, Offs: 1, Leng: 1},
+	RegTxTopATxTrigTxTrigGps:                                       {Name: "TX_TOP_A_TX_TRIG_TX_TRIG_GPS", Page: 0, Addr: 0x5C00, Offs: 2, Leng: 1},
+	RegTxTopATimerTrigByte3TimerDelayedTrig:                        {Name: "TX_TOP_A_TIMER_TRIG_BYTE3_TIMER_DELAYED_TRIG", Page: 0, Addr: 0x5C01, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATimerTrigByte2TimerDelayedTrig:                        {Name: "TX_TOP_A_TIMER_TRIG_BYTE2_TIMER_DELAYED_TRIG", Page: 0, Addr: 0x5C02, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATimerTrigByte1TimerDelayedTrig:                        {Name: "TX_TOP_A_TIMER_TRIG_BYTE1_TIMER_DELAYED_TRIG", Page: 0, Addr: 0x5C03, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATimerTrigByte0TimerDelayedTrig:                        {Name: "TX_TOP_A_TIMER_TRIG_BYTE0_TIMER_DELAYED_TRIG", Page: 0, Addr: 0x5C04, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxStartDelayMsbTxStartDelay:                           {Name: "TX_TOP_A_TX_START_DELAY_MSB_TX_START_DELAY", Page: 0, Addr: 0x5C05, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxStartDelayLsbTxStartDelay:                           {Name: "TX_TOP_A_TX_START_DELAY_LSB_TX_START_DELAY", Page: 0, Addr: 0x5C06, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxCtrlWriteBuffer:                                     {Name: "TX_TOP_A_TX_CTRL_WRITE_BUFFER", Page: 0, Addr: 0x5C07, Offs: 0, Leng: 1},
+	RegTxTopATxRampDurationTxRampDuration:                          {Name: "TX_TOP_A_TX_RAMP_DURATION_TX_RAMP_DURATION", Page: 0, Addr: 0x5C08, Offs: 0, Leng: 3, Check: true},
+	RegTxTopAGenCfg0ModulationType:                                 {Name: "TX_TOP_A_GEN_CFG_0_MODULATION_TYPE", Page: 0, Addr: 0x5C09, Offs: 0, Leng: 2, Check: true},
+	RegTxTopATxRffeIfCtrlTxMode:                                    {Name: "TX_TOP_A_TX_RFFE_IF_CTRL_TX_MODE", Page: 0, Addr: 0x5C0A, Offs: 0, Leng: 1, Check: true},
+	RegTxTopATxRffeIfCtrlTxIfSrc:                                   {Name: "TX_TOP_A_TX_RFFE_IF_CTRL_TX_IF_SRC", Page: 0, Addr: 0x5C0A, Offs: 1, Leng: 2, Check: true},
+	RegTxTopATxRffeIfCtrlTxClkEdge:                                 {Name: "TX_TOP_A_TX_RFFE_IF_CTRL_TX_CLK_EDGE", Page: 0, Addr: 0x5C0A, Offs: 3, Leng: 1, Check: true},
+	RegTxTopATxRffeIfFreqRfHFreqRf:                                 {Name: "TX_TOP_A_TX_RFFE_IF_FREQ_RF_H_FREQ_RF", Page: 0, Addr: 0x5C0B, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxRffeIfFreqRfMFreqRf:                                 {Name: "TX_TOP_A_TX_RFFE_IF_FREQ_RF_M_FREQ_RF", Page: 0, Addr: 0x5C0C, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxRffeIfFreqRfLFreqRf:                                 {Name: "TX_TOP_A_TX_RFFE_IF_FREQ_RF_L_FREQ_RF", Page: 0, Addr: 0x5C0D, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxRffeIfFreqDevHFreqDev:                               {Name: "TX_TOP_A_TX_RFFE_IF_FREQ_DEV_H_FREQ_DEV", Page: 0, Addr: 0x5C0E, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxRffeIfFreqDevLFreqDev:                               {Name: "TX_TOP_A_TX_RFFE_IF_FREQ_DEV_L_FREQ_DEV", Page: 0, Addr: 0x5C0F, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxFsmStatusTxStatus:                                   {Name: "TX_TOP_A_TX_FSM_STATUS_TX_STATUS", Page: 0, Addr: 0x5C10, Offs: 0, Leng: 8, ReadOnly: true, Default: 0x80},
+	RegTxTopATxRffeIfIqGainIqGain:                                  {Name: "TX_TOP_A_TX_RFFE_IF_IQ_GAIN_IQ_GAIN", Page: 0, Addr: 0x5C11, Offs: 0, Leng: 2, Check: true},
+	RegTxTopATxRffeIfIOffsetIOffset:                                {Name: "TX_TOP_A_TX_RFFE_IF_I_OFFSET_I_OFFSET", Page: 0, Addr: 0x5C12, Offs: 0, Sign: true, Leng: 8, Check: true},
+	RegTxTopATxRffeIfQOffsetQOffset:                                {Name: "TX_TOP_A_TX_RFFE_IF_Q_OFFSET_Q_OFFSET", Page: 0, Addr: 0x5C13, Offs: 0, Sign: true, Leng: 8, Check: true},
+	RegTxTopAAgcTxBwAgcTxPaGain:                                    {Name: "TX_TOP_A_AGC_TX_BW_AGC_TX_PA_GAIN", Page: 0, Addr: 0x5C14, Offs: 0, Leng: 2, Check: true},
+	RegTxTopAAgcTxPwrAgcDriveTxPwr:                                 {Name: "TX_TOP_A_AGC_TX_PWR_AGC_DRIVE_TX_PWR", Page: 0, Addr: 0x5C15, Offs: 0, Leng: 6, Check: true},
+	RegTxTopATxrxCfg00ModemBw:                                      {Name: "TX_TOP_A_TXRX_CFG0_0_MODEM_BW", Page: 0, Addr: 0x5C16, Offs: 0, Leng: 4, Check: true, Default: 4},
+	RegTxTopATxrxCfg00ModemSf:                                      {Name: "TX_TOP_A_TXRX_CFG0_0_MODEM_SF", Page: 0, Addr: 0x5C16, Offs: 4, Leng: 4, Check: true, Default: 7},
+	RegTxTopATxrxCfg01CodingRate:                                   {Name: "TX_TOP_A_TXRX_CFG0_1_CODING_RATE", Page: 0, Addr: 0x5C17, Offs: 0, Leng: 3, Check: true, Default: 1},
+	RegTxTopATxrxCfg01PpmOffset:                                    {Name: "TX_TOP_A_TXRX_CFG0_1_PPM_OFFSET", Page: 0, Addr: 0x5C17, Offs: 3, Leng: 2, Check: true},
+	RegTxTopATxrxCfg01PostPreambleGapLong:                          {Name: "TX_TOP_A_TXRX_CFG0_1_POST_PREAMBLE_GAP_LONG", Page: 0, Addr: 0x5C17, Offs: 5, Leng: 1, Check: true},
+	RegTxTopATxrxCfg02FineSynchEn:                                  {Name: "TX_TOP_A_TXRX_CFG0_2_FINE_SYNCH_EN", Page: 0, Addr: 0x5C18, Offs: 0, Leng: 1, Check: true},
+	RegTxTopATxrxCfg02ModemEn:                                      {Name: "TX_TOP_A_TXRX_CFG0_2_MODEM_EN", Page: 0, Addr: 0x5C18, Offs: 1, Leng: 1, Check: true},
+	RegTxTopATxrxCfg02ImplicitHeader:                               {Name: "TX_TOP_A_TXRX_CFG0_2_IMPLICIT_HEADER", Page: 0, Addr: 0x5C18, Offs: 2, Leng: 1, Check: true},
+	RegTxTopATxrxCfg02CrcEn:                                        {Name: "TX_TOP_A_TXRX_CFG0_2_CRC_EN", Page: 0, Addr: 0x5C18, Offs: 3, Leng: 1, Check: true, Default: 1},
+	RegTxTopATxrxCfg03PayloadLength:                                {Name: "TX_TOP_A_TXRX_CFG0_3_PAYLOAD_LENGTH", Page: 0, Addr: 0x5C19, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxrxCfg10InvertIq:                                     {Name: "TX_TOP_A_TXRX_CFG1_0_INVERT_IQ", Page: 0, Addr: 0x5C1A, Offs: 0, Leng: 1, Check: true},
+	RegTxTopATxrxCfg11PreambleSymbNbMsb:                            {Name: "TX_TOP_A_TXRX_CFG1_1_PREAMBLE_SYMB_NB_MSB", Page: 0, Addr: 0x5C1B, Offs: 0, Leng: 8, Check: true},
+	RegTxTopATxrxCfg12PreambleSymbNbLsb:                            {Name: "TX_TOP_A_TXRX_CFG1_2_PREAMBLE_SYMB_NB_LSB", Page: 0, Addr: 0x5C1C, Offs: 0, Leng: 8, Check: true, Default: 8},
+	RegTxTopAFrameSynch0Peak1Pos:                                   {Name: "TX_TOP_A_FRAME_SYNCH_0_PEAK1_POS", Page: 0, Addr: 0x5C1D, Offs: 0, Leng: 4, Check: true, Default: 2},
+	RegTxTopAFrameSynch1Peak2Pos:                                   {Name: "TX_TOP_A_FRAME_SYNCH_1_PEAK2_POS", Page: 0, Addr: 0x5C1E, Offs: 0, Leng: 4, Check: true, Default: 4},
+	RegTxTopAFskCfg0PktMode:                                        {Name: "TX_TOP_A_FSK_CFG_0_PKT_MODE", Page: 0, Addr: 0x5C20, Offs: 0, Leng: 1, Check: true, Default: 1},
+	RegTxTopAFskCfg0CrcEn:                                          {Name: "TX_TOP_A_FSK_CFG_0_CRC_EN", Page: 0, Addr: 0x5C20, Offs: 1, Leng: 1, Check: true},
+	RegTxTopAFskCfg0DcfreeEnc:                                      {Name: "TX_TOP_A_FSK_CFG_0_DCFREE_ENC", Page: 0, Addr: 0x5C20, Offs: 2, Leng: 2, Check: true},
+	RegTxTopAFskCfg0CrcIbm:                                         {Name: "TX_TOP_A_FSK_CFG_0_CRC_IBM", Page: 0, Addr: 0x5C20, Offs: 4, Leng: 1, Check: true},
+	RegTxTopAFskCfg0Psize:                                          {Name: "TX_TOP_A_FSK_CFG_0_PSIZE", Page: 0, Addr: 0x5C20, Offs: 5, Leng: 3, Check: true},
+	RegTxTopAFskPreambleSizeMsbPreambleSize:                        {Name: "TX_TOP_A_FSK_PREAMBLE_SIZE_MSB_PREAMBLE_SIZE", Page: 0, Addr: 0x5C21, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskPreambleSizeLsbPreambleSize:                        {Name: "TX_TOP_A_FSK_PREAMBLE_SIZE_LSB_PREAMBLE_SIZE", Page: 0, Addr: 0x5C22, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskBitRateMsbBitRate:                                  {Name: "TX_TOP_A_FSK_BIT_RATE_MSB_BIT_RATE", Page: 0, Addr: 0x5C23, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskBitRateLsbBitRate:                                  {Name: "TX_TOP_A_FSK_BIT_RATE_LSB_BIT_RATE", Page: 0, Addr: 0x5C24, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskModFskRefPatternByte0FskRefPattern:                 {Name: "TX_TOP_A_FSK_MOD_FSK_REF_PATTERN_BYTE0_FSK_REF_PATTERN", Page: 0, Addr: 0x5C25, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskModFskRefPatternByte1FskRefPattern:                 {Name: "TX_TOP_A_FSK_MOD_FSK_REF_PATTERN_BYTE1_FSK_REF_PATTERN", Page: 0, Addr: 0x5C26, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskModFskRefPatternByte2FskRefPattern:                 {Name: "TX_TOP_A_FSK_MOD_FSK_REF_PATTERN_BYTE2_FSK_REF_PATTERN", Page: 0, Addr: 0x5C27, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskModFskRefPatternByte3FskRefPattern:                 {Name: "TX_TOP_A_FSK_MOD_FSK_REF_PATTERN_BYTE3_FSK_REF_PATTERN", Page: 0, Addr: 0x5C28, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskModFskRefPatternByte4FskRefPattern:                 {Name: "TX_TOP_A_FSK_MOD_FSK_REF_PATTERN_BYTE4_FSK_REF_PATTERN", Page: 0, Addr: 0x5C29, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskModFskRefPatternByte5FskRefPattern:                 {Name: "TX_TOP_A_FSK_MOD_FSK_REF_PATTERN_BYTE5_FSK_REF_PATTERN", Page: 0, Addr: 0x5C2A, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskModFskRefPatternByte6FskRefPattern:                 {Name: "TX_TOP_A_FSK_MOD_FSK_REF_PATTERN_BYTE6_FSK_REF_PATTERN", Page: 0, Addr: 0x5C2B, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskModFskRefPatternByte7FskRefPattern:                 {Name: "TX_TOP_A_FSK_MOD_FSK_REF_PATTERN_BYTE7_FSK_REF_PATTERN", Page: 0, Addr: 0x5C2C, Offs: 0, Leng: 8, Check: true},
+	RegTxTopAFskPktLenPktLen:                                       {Name: "TX_TOP_A_FSK_PKT_LEN_PKT_LEN", Page: 0, Addr: 0x5C2D, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxTrigTxTrigImmediate:                                 {Name: "TX_TOP_B_TX_TRIG_TX_TRIG_IMMEDIATE", Page: 0, Addr: 0x5E00, Offs: 0, Leng: 1},
+	RegTxTopBTxTrigTxTrigDelayed:                                   {Name: "TX_TOP_B_TX_TRIG_TX_TRIG_DELAYED", Page: 0, Addr: 0x5E00, Offs: 1, Leng: 1},
+	RegTxTopBTxTrigTxTrigGps:                                       {Name: "TX_TOP_B_TX_TRIG_TX_TRIG_GPS", Page: 0, Addr: 0x5E00, Offs: 2, Leng: 1},
+	RegTxTopBTimerTrigByte3TimerDelayedTrig:                        {Name: "TX_TOP_B_TIMER_TRIG_BYTE3_TIMER_DELAYED_TRIG", Page: 0, Addr: 0x5E01, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTimerTrigByte2TimerDelayedTrig:                        {Name: "TX_TOP_B_TIMER_TRIG_BYTE2_TIMER_DELAYED_TRIG", Page: 0, Addr: 0x5E02, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTimerTrigByte1TimerDelayedTrig:                        {Name: "TX_TOP_B_TIMER_TRIG_BYTE1_TIMER_DELAYED_TRIG", Page: 0, Addr: 0x5E03, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTimerTrigByte0TimerDelayedTrig:                        {Name: "TX_TOP_B_TIMER_TRIG_BYTE0_TIMER_DELAYED_TRIG", Page: 0, Addr: 0x5E04, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxStartDelayMsbTxStartDelay:                           {Name: "TX_TOP_B_TX_START_DELAY_MSB_TX_START_DELAY", Page: 0, Addr: 0x5E05, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxStartDelayLsbTxStartDelay:                           {Name: "TX_TOP_B_TX_START_DELAY_LSB_TX_START_DELAY", Page: 0, Addr: 0x5E06, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxCtrlWriteBuffer:                                     {Name: "TX_TOP_B_TX_CTRL_WRITE_BUFFER", Page: 0, Addr: 0x5E07, Offs: 0, Leng: 1},
+	RegTxTopBTxRampDurationTxRampDuration:                          {Name: "TX_TOP_B_TX_RAMP_DURATION_TX_RAMP_DURATION", Page: 0, Addr: 0x5E08, Offs: 0, Leng: 3, Check: true},
+	RegTxTopBGenCfg0ModulationType:                                 {Name: "TX_TOP_B_GEN_CFG_0_MODULATION_TYPE", Page: 0, Addr: 0x5E09, Offs: 0, Leng: 2, Check: true},
+	RegTxTopBTxRffeIfCtrlTxMode:                                    {Name: "TX_TOP_B_TX_RFFE_IF_CTRL_TX_MODE", Page: 0, Addr: 0x5E0A, Offs: 0, Leng: 1, Check: true},
+	RegTxTopBTxRffeIfCtrlTxIfSrc:                                   {Name: "TX_TOP_B_TX_RFFE_IF_CTRL_TX_IF_SRC", Page: 0, Addr: 0x5E0A, Offs: 1, Leng: 2, Check: true},
+	RegTxTopBTxRffeIfCtrlTxClkEdge:                                 {Name: "TX_TOP_B_TX_RFFE_IF_CTRL_TX_CLK_EDGE", Page: 0, Addr: 0x5E0A, Offs: 3, Leng: 1, Check: true},
+	RegTxTopBTxRffeIfFreqRfHFreqRf:                                 {Name: "TX_TOP_B_TX_RFFE_IF_FREQ_RF_H_FREQ_RF", Page: 0, Addr: 0x5E0B, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxRffeIfFreqRfMFreqRf:                                 {Name: "TX_TOP_B_TX_RFFE_IF_FREQ_RF_M_FREQ_RF", Page: 0, Addr: 0x5E0C, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxRffeIfFreqRfLFreqRf:                                 {Name: "TX_TOP_B_TX_RFFE_IF_FREQ_RF_L_FREQ_RF", Page: 0, Addr: 0x5E0D, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxRffeIfFreqDevHFreqDev:                               {Name: "TX_TOP_B_TX_RFFE_IF_FREQ_DEV_H_FREQ_DEV", Page: 0, Addr: 0x5E0E, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxRffeIfFreqDevLFreqDev:                               {Name: "TX_TOP_B_TX_RFFE_IF_FREQ_DEV_L_FREQ_DEV", Page: 0, Addr: 0x5E0F, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxFsmStatusTxStatus:                                   {Name: "TX_TOP_B_TX_FSM_STATUS_TX_STATUS", Page: 0, Addr: 0x5E10, Offs: 0, Leng: 8, ReadOnly: true, Default: 0x80},
+	RegTxTopBTxRffeIfIqGainIqGain:                                  {Name: "TX_TOP_B_TX_RFFE_IF_IQ_GAIN_IQ_GAIN", Page: 0, Addr: 0x5E11, Offs: 0, Leng: 2, Check: true},
+	RegTxTopBTxRffeIfIOffsetIOffset:                                {Name: "TX_TOP_B_TX_RFFE_IF_I_OFFSET_I_OFFSET", Page: 0, Addr: 0x5E12, Offs: 0, Sign: true, Leng: 8, Check: true},
+	RegTxTopBTxRffeIfQOffsetQOffset:                                {Name: "TX_TOP_B_TX_RFFE_IF_Q_OFFSET_Q_OFFSET", Page: 0, Addr: 0x5E13, Offs: 0, Sign: true, Leng: 8, Check: true},
+	RegTxTopBAgcTxBwAgcTxPaGain:                                    {Name: "TX_TOP_B_AGC_TX_BW_AGC_TX_PA_GAIN", Page: 0, Addr: 0x5E14, Offs: 0, Leng: 2, Check: true},
+	RegTxTopBAgcTxPwrAgcDriveTxPwr:                                 {Name: "TX_TOP_B_AGC_TX_PWR_AGC_DRIVE_TX_PWR", Page: 0, Addr: 0x5E15, Offs: 0, Leng: 6, Check: true},
+	RegTxTopBTxrxCfg00ModemBw:                                      {Name: "TX_TOP_B_TXRX_CFG0_0_MODEM_BW", Page: 0, Addr: 0x5E16, Offs: 0, Leng: 4, Check: true, Default: 4},
+	RegTxTopBTxrxCfg00ModemSf:                                      {Name: "TX_TOP_B_TXRX_CFG0_0_MODEM_SF", Page: 0, Addr: 0x5E16, Offs: 4, Leng: 4, Check: true, Default: 7},
+	RegTxTopBTxrxCfg01CodingRate:                                   {Name: "TX_TOP_B_TXRX_CFG0_1_CODING_RATE", Page: 0, Addr: 0x5E17, Offs: 0, Leng: 3, Check: true, Default: 1},
+	RegTxTopBTxrxCfg01PpmOffset:                                    {Name: "TX_TOP_B_TXRX_CFG0_1_PPM_OFFSET", Page: 0, Addr: 0x5E17, Offs: 3, Leng: 2, Check: true},
+	RegTxTopBTxrxCfg01PostPreambleGapLong:                          {Name: "TX_TOP_B_TXRX_CFG0_1_POST_PREAMBLE_GAP_LONG", Page: 0, Addr: 0x5E17, Offs: 5, Leng: 1, Check: true},
+	RegTxTopBTxrxCfg02FineSynchEn:                                  {Name: "TX_TOP_B_TXRX_CFG0_2_FINE_SYNCH_EN", Page: 0, Addr: 0x5E18, Offs: 0, Leng: 1, Check: true},
+	RegTxTopBTxrxCfg02ModemEn:                                      {Name: "TX_TOP_B_TXRX_CFG0_2_MODEM_EN", Page: 0, Addr: 0x5E18, Offs: 1, Leng: 1, Check: true},
+	RegTxTopBTxrxCfg02ImplicitHeader:                               {Name: "TX_TOP_B_TXRX_CFG0_2_IMPLICIT_HEADER", Page: 0, Addr: 0x5E18, Offs: 2, Leng: 1, Check: true},
+	RegTxTopBTxrxCfg02CrcEn:                                        {Name: "TX_TOP_B_TXRX_CFG0_2_CRC_EN", Page: 0, Addr: 0x5E18, Offs: 3, Leng: 1, Check: true, Default: 1},
+	RegTxTopBTxrxCfg03PayloadLength:                                {Name: "TX_TOP_B_TXRX_CFG0_3_PAYLOAD_LENGTH", Page: 0, Addr: 0x5E19, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxrxCfg10InvertIq:                                     {Name: "TX_TOP_B_TXRX_CFG1_0_INVERT_IQ", Page: 0, Addr: 0x5E1A, Offs: 0, Leng: 1, Check: true},
+	RegTxTopBTxrxCfg11PreambleSymbNbMsb:                            {Name: "TX_TOP_B_TXRX_CFG1_1_PREAMBLE_SYMB_NB_MSB", Page: 0, Addr: 0x5E1B, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBTxrxCfg12PreambleSymbNbLsb:                            {Name: "TX_TOP_B_TXRX_CFG1_2_PREAMBLE_SYMB_NB_LSB", Page: 0, Addr: 0x5E1C, Offs: 0, Leng: 8, Check: true, Default: 8},
+	RegTxTopBFrameSynch0Peak1Pos:                                   {Name: "TX_TOP_B_FRAME_SYNCH_0_PEAK1_POS", Page: 0, Addr: 0x5E1D, Offs: 0, Leng: 4, Check: true, Default: 2},
+	RegTxTopBFrameSynch1Peak2Pos:                                   {Name: "TX_TOP_B_FRAME_SYNCH_1_PEAK2_POS", Page: 0, Addr: 0x5E1E, Offs: 0, Leng: 4, Check: true, Default: 4},
+	RegTxTopBFskCfg0PktMode:                                        {Name: "TX_TOP_B_FSK_CFG_0_PKT_MODE", Page: 0, Addr: 0x5E20, Offs: 0, Leng: 1, Check: true, Default: 1},
+	RegTxTopBFskCfg0CrcEn:                                          {Name: "TX_TOP_B_FSK_CFG_0_CRC_EN", Page: 0, Addr: 0x5E20, Offs: 1, Leng: 1, Check: true},
+	RegTxTopBFskCfg0DcfreeEnc:                                      {Name: "TX_TOP_B_FSK_CFG_0_DCFREE_ENC", Page: 0, Addr: 0x5E20, Offs: 2, Leng: 2, Check: true},
+	RegTxTopBFskCfg0CrcIbm:                                         {Name: "TX_TOP_B_FSK_CFG_0_CRC_IBM", Page: 0, Addr: 0x5E20, Offs: 4, Leng: 1, Check: true},
+	RegTxTopBFskCfg0Psize:                                          {Name: "TX_TOP_B_FSK_CFG_0_PSIZE", Page: 0, Addr: 0x5E20, Offs: 5, Leng: 3, Check: true},
+	RegTxTopBFskPreambleSizeMsbPreambleSize:                        {Name: "TX_TOP_B_FSK_PREAMBLE_SIZE_MSB_PREAMBLE_SIZE", Page: 0, Addr: 0x5E21, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskPreambleSizeLsbPreambleSize:                        {Name: "TX_TOP_B_FSK_PREAMBLE_SIZE_LSB_PREAMBLE_SIZE", Page: 0, Addr: 0x5E22, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskBitRateMsbBitRate:                                  {Name: "TX_TOP_B_FSK_BIT_RATE_MSB_BIT_RATE", Page: 0, Addr: 0x5E23, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskBitRateLsbBitRate:                                  {Name: "TX_TOP_B_FSK_BIT_RATE_LSB_BIT_RATE", Page: 0, Addr: 0x5E24, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskModFskRefPatternByte0FskRefPattern:                 {Name: "TX_TOP_B_FSK_MOD_FSK_REF_PATTERN_BYTE0_FSK_REF_PATTERN", Page: 0, Addr: 0x5E25, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskModFskRefPatternByte1FskRefPattern:                 {Name: "TX_TOP_B_FSK_MOD_FSK_REF_PATTERN_BYTE1_FSK_REF_PATTERN", Page: 0, Addr: 0x5E26, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskModFskRefPatternByte2FskRefPattern:                 {Name: "TX_TOP_B_FSK_MOD_FSK_REF_PATTERN_BYTE2_FSK_REF_PATTERN", Page: 0, Addr: 0x5E27, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskModFskRefPatternByte3FskRefPattern:                 {Name: "TX_TOP_B_FSK_MOD_FSK_REF_PATTERN_BYTE3_FSK_REF_PATTERN", Page: 0, Addr: 0x5E28, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskModFskRefPatternByte4FskRefPattern:                 {Name: "TX_TOP_B_FSK_MOD_FSK_REF_PATTERN_BYTE4_FSK_REF_PATTERN", Page: 0, Addr: 0x5E29, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskModFskRefPatternByte5FskRefPattern:                 {Name: "TX_TOP_B_FSK_MOD_FSK_REF_PATTERN_BYTE5_FSK_REF_PATTERN", Page: 0, Addr: 0x5E2A, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskModFskRefPatternByte6FskRefPattern:                 {Name: "TX_TOP_B_FSK_MOD_FSK_REF_PATTERN_BYTE6_FSK_REF_PATTERN", Page: 0, Addr: 0x5E2B, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskModFskRefPatternByte7FskRefPattern:                 {Name: "TX_TOP_B_FSK_MOD_FSK_REF_PATTERN_BYTE7_FSK_REF_PATTERN", Page: 0, Addr: 0x5E2C, Offs: 0, Leng: 8, Check: true},
+	RegTxTopBFskPktLenPktLen:                                       {Name: "TX_TOP_B_FSK_PKT_LEN_PKT_LEN", Page: 0, Addr: 0x5E2D, Offs: 0, Leng: 8, Check: true},
+	RegOtpByteAddrAddr:                                             {Name: "OTP_BYTE_ADDR_ADDR", Page: 0, Addr: 0x5FD0, Offs: 0, Leng: 8, Check: true},
+	RegOtpRdDataRdData:                                             {Name: "OTP_RD_DATA_RD_DATA", Page: 0, Addr: 0x5FD1, Offs: 0, Leng: 8, ReadOnly: true},
+}
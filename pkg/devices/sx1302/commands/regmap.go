@@ -0,0 +1,90 @@
+package commands
+
+// Register describes a field of the SX1302 register file
+type Register struct {
+	// Name of the register as used in the SX1302 datasheet
+	Name string
+
+	// Page containing the register (PageAll for all pages)
+	Page int8
+
+	// Base address of the register (15 bit)
+	Addr uint16
+
+	// Position of the register LSB (between 0 to 7)
+	Offs uint8
+
+	// Indicates the register is signed (2 complement)
+	Sign bool
+
+	// Number of bits in the register
+	Leng uint8
+
+	// Indicates a read-only register
+	ReadOnly bool
+
+	// Register can be checked or not: (pulse, w0clr, w1clr)
+	Check bool
+
+	// Register default value
+	Default int32
+}
+
+// Memory regions of the SX1302 address space
+const (
+	// AGCMemAddr is the start of the program memory of the AGC MCU
+	AGCMemAddr uint16 = 0x0000
+
+	// ARBMemAddr is the start of the program memory of the arbiter MCU
+	ARBMemAddr uint16 = 0x2000
+
+	// MCUMemSize is the size of the program memory of each MCU
+	MCUMemSize int = 8192
+
+	// RxBufferAddr is the start of the RX buffer holding received packets
+	RxBufferAddr uint16 = 0x4000
+
+	// RxBufferSize is the size of the RX buffer
+	RxBufferSize int = 4096
+
+	// TxBufferAddrA is the start of the TX buffer of RF chain A
+	TxBufferAddrA uint16 = 0x5300
+
+	// TxBufferAddrB is the start of the TX buffer of RF chain B
+	TxBufferAddrB uint16 = 0x5500
+
+	// TxBufferSize is the size of each TX buffer
+	TxBufferSize int = 256
+)
+
+// PageAll marks a register that is reachable independently of the selected page
+const PageAll int8 = -1
+
+// RegID identifies a register of the SX1302 register map
+type RegID int
+
+// The register map in registers.go follows the register table of the reference HAL, regenerate it from a checkout
+// of the HAL at $SX1302_HAL
+//go:generate go run ./internal/regmapgen -in $SX1302_HAL/libloragw/src/loragw_reg.c -out registers.go
+
+// RegisterMap returns a copy of the complete register map, indexed by RegID
+func RegisterMap() []Register {
+	return append([]Register(nil), registers[:]...)
+}
+
+// Register returns the register description of id
+func (id RegID) Register() (Register, error) {
+	if id < 0 || id >= regCount {
+		return Register{}, wrapf("register id %d is out of range", int(id))
+	}
+
+	return registers[id], nil
+}
+
+func (id RegID) String() string {
+	if id < 0 || id >= regCount {
+		return "Unknown"
+	}
+
+	return registers[id].Name
+}
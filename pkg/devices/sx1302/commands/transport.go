@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// Transport is the communication interface to the SX1302 and the radios behind its SPI mux.
+// LowLevel implements it for concentrators attached to SPI, USB for the ones behind the USB MCU bridge.
+type Transport interface {
+	// DevWrite writes a single byte to the register at address of the given SPI mux target
+	DevWrite(muxTarget uint8, address uint16, data byte) error
+
+	// DevRead reads a single byte from the register at address of the given SPI mux target
+	DevRead(muxTarget uint8, address uint16) (byte, error)
+
+	// DevWriteBurst writes data to consecutive registers starting at address of the given SPI mux target
+	DevWriteBurst(muxTarget uint8, address uint16, data []byte) error
+
+	// DevReadBurst fills buf from consecutive registers starting at address of the given SPI mux target
+	DevReadBurst(muxTarget uint8, address uint16, buf []byte) error
+
+	// DevReadFifo fills buf from the FIFO at address of the given SPI mux target. Unlike DevReadBurst, every
+	// transaction reads address, the chip advances the FIFO on its own
+	DevReadFifo(muxTarget uint8, address uint16, buf []byte) error
+
+	// DevTransfer sends w to the given SPI mux target, unframed, and fills r with the bytes clocked in while w was
+	// sent. r is either nil or as long as w. Pending bulk writes are flushed first
+	DevTransfer(muxTarget uint8, w []byte, r []byte) error
+
+	// SetWriteMode selects whether writes are sent immediately or queued until the next Flush or read
+	SetWriteMode(mode model.COMWriteMode) error
+
+	// Flush sends all writes queued in bulk mode
+	Flush() error
+
+	// Reset resets the SX1302 and the radios attached to it
+	Reset() error
+
+	// Close flushes pending writes and releases the transport
+	Close() error
+}
+
+var _ Transport = &LowLevel{}
+
+// Dialer opens the transport to the concentrator described by board. A concentrator dials its transport on every
+// Start and closes it on Stop.
+type Dialer func(board *model.BoardConf) (Transport, error)
+
+// Dial opens the transport selected by board.ComType: the SPI port board.ComPath, with no GPIO pins, or the serial
+// port board.ComPath of the USB MCU bridge. The SPI port is closed with the transport.
+func Dial(board *model.BoardConf) (Transport, error) {
+	switch board.ComType {
+	case model.ComSPI:
+		port, err := spireg.Open(board.ComPath)
+		if err != nil {
+			return nil, wrapf("failed to open the spi port %s: %v", board.ComPath, err)
+		}
+
+		conn, err := ConnectSPI(port)
+		if err != nil {
+			port.Close()
+			return nil, wrapf("failed to connect to %s: %v", port, err)
+		}
+
+		com, err := NewLowLevelConn(conn, Pins{})
+		if err != nil {
+			port.Close()
+			return nil, err
+		}
+		com.port = port
+
+		return com, nil
+
+	case model.ComUSB:
+		return OpenUSB(board.ComPath)
+	}
+
+	return nil, wrapf("no transport for com type %s", board.ComType)
+}
+
+// SPIDialer returns a Dialer connecting to the concentrator on spiPort, using the GPIO pins wired to the board.
+// The port is connected by the first dial and stays connected, it is never closed by the transport.
+func SPIDialer(spiPort spi.Port, pins Pins) Dialer {
+	var conn spi.Conn
+	return func(*model.BoardConf) (Transport, error) {
+		if conn == nil {
+			c, err := ConnectSPI(spiPort)
+			if err != nil {
+				return nil, wrapf("failed to connect to %s: %v", spiPort, err)
+			}
+			conn = c
+		}
+
+		return NewLowLevelConn(conn, pins)
+	}
+}
+
+// TransportDialer returns a Dialer handing out com on every dial. Closing the transport it returns only flushes the
+// pending writes, com stays open.
+func TransportDialer(com Transport) Dialer {
+	return func(*model.BoardConf) (Transport, error) {
+		return keepOpen{com}, nil
+	}
+}
+
+// keepOpen is a Transport that is not closed by Close
+type keepOpen struct {
+	Transport
+}
+
+// Close flushes the pending writes
+func (k keepOpen) Close() error {
+	return k.Flush()
+}
@@ -0,0 +1,534 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/serial"
+)
+
+// McuOrder is the order of a command sent to the MCU bridging USB to the SX1302
+type McuOrder uint8
+
+const (
+	// McuOrderPing requests the unique ID and firmware version of the MCU
+	McuOrderPing McuOrder = 0x00
+	// McuOrderGetStatus requests the system time and temperature of the MCU
+	McuOrderGetStatus McuOrder = 0x01
+	// McuOrderBootloaderMode reboots the MCU into its bootloader
+	McuOrderBootloaderMode McuOrder = 0x02
+	// McuOrderReset resets the concentrator
+	McuOrderReset McuOrder = 0x03
+	// McuOrderWriteGPIO sets a GPIO of the MCU
+	McuOrderWriteGPIO McuOrder = 0x04
+	// McuOrderMultipleSPI executes a list of SPI requests
+	McuOrderMultipleSPI McuOrder = 0x05
+
+	// McuOrderAck is set in the order of the answer to a command
+	McuOrderAck McuOrder = 0x40
+	// McuOrderUnknown is the answer to an unknown command
+	McuOrderUnknown McuOrder = 0xFF
+)
+
+func (o McuOrder) String() string {
+	switch o {
+	case McuOrderPing:
+		return "Ping"
+	case McuOrderGetStatus:
+		return "GetStatus"
+	case McuOrderBootloaderMode:
+		return "BootloaderMode"
+	case McuOrderReset:
+		return "Reset"
+	case McuOrderWriteGPIO:
+		return "WriteGPIO"
+	case McuOrderMultipleSPI:
+		return "MultipleSPI"
+	case McuOrderUnknown:
+		return "Unknown"
+	}
+
+	if o&McuOrderAck != 0 {
+		return "Ack" + (o &^ McuOrderAck).String()
+	}
+
+	return "Undefined"
+}
+
+const (
+	// usbBaudRate is the baud rate of the USB CDC tty exposed by the MCU
+	usbBaudRate int = 115200
+
+	// usbTimeout is the maximum time to wait for the answer of the MCU
+	usbTimeout time.Duration = 500 * time.Millisecond
+
+	// usbHeaderSize is the size of the command and answer header: id, size MSB, size LSB, order
+	usbHeaderSize int = 4
+
+	// usbSpiReqHeaderSize is the size of a SPI request header: id, type, size MSB, size LSB
+	usbSpiReqHeaderSize int = 4
+
+	// usbSpiAckHeaderSize is the size of a SPI answer header: id, type, status, size MSB, size LSB
+	usbSpiAckHeaderSize int = 5
+
+	// usbPingIDSize is the size of the unique ID of the MCU at the start of the ping answer, followed by its version
+	usbPingIDSize int = 12
+
+	// usbMaxPayload is the maximum payload size of a single command
+	usbMaxPayload int = 4096
+
+	// usbSpiReqTypeReadWrite is a plain SPI transfer request
+	usbSpiReqTypeReadWrite uint8 = 0x01
+
+	// usbSpiStatusOk signals a successful SPI request
+	usbSpiStatusOk uint8 = 0x00
+
+	// usbResetTypeGateway resets the SX1302 and the radios
+	usbResetTypeGateway uint8 = 0x00
+)
+
+// McuFirmwareVersion is the firmware version of the MCU supported by the driver, as reported by Ping
+const McuFirmwareVersion = "01.00.00"
+
+// McuInfo is the answer to a ping of the MCU
+type McuInfo struct {
+	// UniqueID of the MCU, high word first
+	UniqueID [3]uint32
+	Version  string
+}
+
+// McuStatus is the answer to a status request of the MCU
+type McuStatus struct {
+	// SystemTime of the MCU in seconds
+	SystemTime uint32
+
+	// Temperature of the MCU in °C
+	Temperature float32
+}
+
+// USB is a handler of a SX1302 concentrator attached through the USB MCU bridge of the Corecell USB variants.
+// The MCU speaks a framed command protocol and executes SPI requests on behalf of the host.
+type USB struct {
+	port  io.ReadWriteCloser
+	reqID uint8
+
+	// writes queued while in ComWriteModeBulk
+	writeMode model.COMWriteMode
+	pending   [][]byte
+}
+
+// OpenUSB opens the tty at path and connects to the MCU behind it
+func OpenUSB(path string) (*USB, error) {
+	port, err := serial.Open(path, serial.Config{
+		Baud:        usbBaudRate,
+		ReadTimeout: usbTimeout,
+	})
+	if err != nil {
+		return nil, wrapf("failed to open %s: %v", path, err)
+	}
+
+	dev, err := NewUSB(port)
+	if err != nil {
+		port.Close()
+		return nil, err
+	}
+
+	return dev, nil
+}
+
+// NewUSB connects to the MCU communicating through port and checks that it answers
+func NewUSB(port io.ReadWriteCloser) (*USB, error) {
+	dev := &USB{port: port}
+
+	info, err := dev.Ping()
+	if err != nil {
+		return nil, wrapf("mcu does not answer: %v", err)
+	}
+
+	if info.Version != McuFirmwareVersion {
+		return nil, wrapf("mcu firmware version %s is not supported, flash version %s", info.Version, McuFirmwareVersion)
+	}
+
+	log.WithFields(log.Fields{
+		"unique_id": fmt.Sprintf("%08X%08X%08X", info.UniqueID[0], info.UniqueID[1], info.UniqueID[2]),
+		"version":   info.Version,
+	}).Info("Connected to concentrator MCU")
+
+	return dev, nil
+}
+
+// Ping requests the unique ID and firmware version of the MCU
+func (u *USB) Ping() (McuInfo, error) {
+	ack, err := u.command(McuOrderPing, nil)
+	if err != nil {
+		return McuInfo{}, err
+	}
+
+	if len(ack) < usbPingIDSize {
+		return McuInfo{}, wrapf("ping answer of %d bytes is too short", len(ack))
+	}
+
+	version := ack[usbPingIDSize:]
+	for i, c := range version {
+		if c == 0 {
+			version = version[:i]
+			break
+		}
+	}
+
+	info := McuInfo{Version: string(version)}
+	for i := range info.UniqueID {
+		info.UniqueID[i] = binary.BigEndian.Uint32(ack[4*i:])
+	}
+
+	return info, nil
+}
+
+// Status requests the system time and temperature of the MCU
+func (u *USB) Status() (McuStatus, error) {
+	ack, err := u.command(McuOrderGetStatus, nil)
+	if err != nil {
+		return McuStatus{}, err
+	}
+
+	if len(ack) < 6 {
+		return McuStatus{}, wrapf("status answer of %d bytes is too short", len(ack))
+	}
+
+	return McuStatus{
+		SystemTime:  binary.BigEndian.Uint32(ack[0:4]),
+		Temperature: float32(int16(binary.BigEndian.Uint16(ack[4:6]))) / 100,
+	}, nil
+}
+
+// Reset resets the SX1302 and the radios attached to it
+func (u *USB) Reset() error {
+	if err := u.Flush(); err != nil {
+		return err
+	}
+
+	ack, err := u.command(McuOrderReset, []byte{usbResetTypeGateway})
+	if err != nil {
+		return err
+	}
+
+	if len(ack) < 1 || ack[0] != usbSpiStatusOk {
+		return wrapf("mcu failed to reset the concentrator")
+	}
+
+	return nil
+}
+
+// WriteGPIO sets pin of GPIO port of the MCU to value
+func (u *USB) WriteGPIO(port uint8, pin uint8, value bool) error {
+	level := byte(0)
+	if value {
+		level = 1
+	}
+
+	ack, err := u.command(McuOrderWriteGPIO, []byte{port, pin, level})
+	if err != nil {
+		return err
+	}
+
+	if len(ack) < 1 || ack[0] != usbSpiStatusOk {
+		return wrapf("mcu failed to write gpio %d.%d", port, pin)
+	}
+
+	return nil
+}
+
+// WriteRegister writes data to the register at address of the given SPI mux target
+func (u *USB) WriteRegister(muxTarget uint8, address uint16, data byte) error {
+	return u.DevWrite(muxTarget, address, data)
+}
+
+// ReadRegister reads the register at address of the given SPI mux target
+func (u *USB) ReadRegister(muxTarget uint8, address uint16) (byte, error) {
+	return u.DevRead(muxTarget, address)
+}
+
+// WriteMultiple sends the SPI frames in a single command, splitting it if it exceeds the command size
+func (u *USB) WriteMultiple(frames [][]byte) error {
+	var batch [][]byte
+	size := 0
+	for _, frame := range frames {
+		if size+usbSpiReqHeaderSize+len(frame) > usbMaxPayload {
+			if _, err := u.spi(batch); err != nil {
+				return err
+			}
+			batch, size = batch[:0], 0
+		}
+
+		batch = append(batch, frame)
+		size += usbSpiReqHeaderSize + len(frame)
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	_, err := u.spi(batch)
+	return err
+}
+
+// DevWrite writes a single byte to the register at address of the given SPI mux target.
+func (u *USB) DevWrite(muxTarget uint8, address uint16, data byte) error {
+	header, err := frameHeader(muxTarget, writeAccess, address)
+	if err != nil {
+		return err
+	}
+
+	return u.write(append(header, data))
+}
+
+// DevRead reads a single byte from the register at address of the given SPI mux target.
+func (u *USB) DevRead(muxTarget uint8, address uint16) (byte, error) {
+	buf := make([]byte, 1)
+	if err := u.DevReadBurst(muxTarget, address, buf); err != nil {
+		return 0, err
+	}
+
+	return buf[0], nil
+}
+
+// DevWriteBurst writes data to consecutive registers starting at address of the given SPI mux target.
+func (u *USB) DevWriteBurst(muxTarget uint8, address uint16, data []byte) error {
+	if len(data) == 0 {
+		return wrapf("burst write of 0 bytes")
+	}
+
+	if err := checkRange(address, len(data)); err != nil {
+		return err
+	}
+
+	chunkSize := usbMaxPayload - usbSpiReqHeaderSize - writeHeaderSize
+	for offset := 0; offset < len(data); offset += chunkSize {
+		chunk := data[offset:min(offset+chunkSize, len(data))]
+
+		header, err := frameHeader(muxTarget, writeAccess, address+uint16(offset))
+		if err != nil {
+			return err
+		}
+
+		if err := u.write(append(header, chunk...)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DevReadBurst fills buf from consecutive registers starting at address of the given SPI mux target.
+func (u *USB) DevReadBurst(muxTarget uint8, address uint16, buf []byte) error {
+	return u.readBurst(muxTarget, address, buf, false)
+}
+
+// DevReadFifo fills buf from the FIFO at address of the given SPI mux target. Every transaction starts at address.
+func (u *USB) DevReadFifo(muxTarget uint8, address uint16, buf []byte) error {
+	return u.readBurst(muxTarget, address, buf, true)
+}
+
+// readBurst fills buf starting at address, the transaction of each chunk starts at address if fifo is set or at the
+// register following the previous chunk otherwise
+func (u *USB) readBurst(muxTarget uint8, address uint16, buf []byte, fifo bool) error {
+	if len(buf) == 0 {
+		return wrapf("burst read of 0 bytes")
+	}
+
+	if err := checkBurstRange(address, len(buf), fifo); err != nil {
+		return err
+	}
+
+	if err := u.Flush(); err != nil {
+		return err
+	}
+
+	chunkSize := usbMaxPayload - usbSpiAckHeaderSize - readHeaderSize
+	for offset := 0; offset < len(buf); offset += chunkSize {
+		chunk := buf[offset:min(offset+chunkSize, len(buf))]
+
+		header, err := frameHeader(muxTarget, readAccess, chunkAddress(address, offset, fifo))
+		if err != nil {
+			return err
+		}
+
+		// header and dummy byte, followed by the bytes clocked out by the chip
+		frame := make([]byte, readHeaderSize+len(chunk))
+		copy(frame, header)
+
+		answers, err := u.spi([][]byte{frame})
+		if err != nil {
+			return err
+		}
+
+		if len(answers[0]) != len(frame) {
+			return wrapf("spi answer of %d bytes for a request of %d bytes", len(answers[0]), len(frame))
+		}
+
+		copy(chunk, answers[0][readHeaderSize:])
+	}
+
+	return nil
+}
+
+// DevTransfer sends w to the given SPI mux target, unframed, and fills r with the bytes clocked in while w was sent.
+// Pending bulk writes are flushed first.
+func (u *USB) DevTransfer(muxTarget uint8, w []byte, r []byte) error {
+	if len(r) != 0 && len(r) != len(w) {
+		return wrapf("transfer reads %d bytes while writing %d bytes", len(r), len(w))
+	}
+
+	if err := u.Flush(); err != nil {
+		return err
+	}
+
+	frame := append([]byte{muxTarget}, w...)
+	answers, err := u.spi([][]byte{frame})
+	if err != nil {
+		return err
+	}
+
+	if len(answers[0]) != len(frame) {
+		return wrapf("spi answer of %d bytes for a request of %d bytes", len(answers[0]), len(frame))
+	}
+
+	copy(r, answers[0][1:])
+	return nil
+}
+
+// SetWriteMode selects whether writes are sent immediately or queued until the next Flush or read.
+// Pending writes are flushed when switching back to ComWriteModeSingle.
+func (u *USB) SetWriteMode(mode model.COMWriteMode) error {
+	switch mode {
+	case model.ComWriteModeSingle:
+		if err := u.Flush(); err != nil {
+			return err
+		}
+	case model.ComWriteModeBulk:
+	default:
+		return wrapf("unsupported write mode %s", mode)
+	}
+
+	u.writeMode = mode
+	return nil
+}
+
+// Flush sends all writes queued in bulk mode, grouped into as few commands as possible
+func (u *USB) Flush() error {
+	if len(u.pending) == 0 {
+		return nil
+	}
+
+	pending := u.pending
+	u.pending = nil
+
+	return u.WriteMultiple(pending)
+}
+
+// Close flushes pending writes and closes the tty
+func (u *USB) Close() error {
+	err := u.Flush()
+	if cerr := u.port.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// write sends the SPI frame or queues it in bulk mode
+func (u *USB) write(frame []byte) error {
+	if u.writeMode == model.ComWriteModeBulk {
+		u.pending = append(u.pending, frame)
+		return nil
+	}
+
+	_, err := u.spi([][]byte{frame})
+	return err
+}
+
+// spi executes the SPI frames with a single MultipleSPI command and returns the bytes clocked in for every frame
+func (u *USB) spi(frames [][]byte) ([][]byte, error) {
+	var payload []byte
+	for i, frame := range frames {
+		payload = append(payload, uint8(i), usbSpiReqTypeReadWrite, byte(len(frame)>>8), byte(len(frame)))
+		payload = append(payload, frame...)
+	}
+
+	ack, err := u.command(McuOrderMultipleSPI, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make([][]byte, len(frames))
+	for i := range frames {
+		if len(ack) < usbSpiAckHeaderSize {
+			return nil, wrapf("spi answer %d is truncated", i)
+		}
+
+		id, status := ack[0], ack[2]
+		size := int(binary.BigEndian.Uint16(ack[3:5]))
+		ack = ack[usbSpiAckHeaderSize:]
+
+		if id != uint8(i) {
+			return nil, wrapf("spi answer %d has unexpected id %d", i, id)
+		}
+
+		if status != usbSpiStatusOk {
+			return nil, wrapf("spi request %d failed with status 0x%02X", i, status)
+		}
+
+		if len(ack) < size {
+			return nil, wrapf("spi answer %d is truncated", i)
+		}
+
+		answers[i], ack = ack[:size], ack[size:]
+	}
+
+	return answers, nil
+}
+
+// command sends a command with the given order to the MCU and returns the payload of its answer
+func (u *USB) command(order McuOrder, payload []byte) ([]byte, error) {
+	if len(payload) > usbMaxPayload {
+		return nil, wrapf("command payload of %d bytes exceeds %d bytes", len(payload), usbMaxPayload)
+	}
+
+	u.reqID++
+	cmd := make([]byte, usbHeaderSize, usbHeaderSize+len(payload))
+	cmd[0] = u.reqID
+	binary.BigEndian.PutUint16(cmd[1:3], uint16(len(payload)))
+	cmd[3] = byte(order)
+	cmd = append(cmd, payload...)
+
+	if _, err := u.port.Write(cmd); err != nil {
+		return nil, wrapf("failed to send %s command: %v", order, err)
+	}
+
+	header := make([]byte, usbHeaderSize)
+	if _, err := io.ReadFull(u.port, header); err != nil {
+		return nil, wrapf("failed to receive %s answer: %v", order, err)
+	}
+
+	ack := make([]byte, binary.BigEndian.Uint16(header[1:3]))
+	if _, err := io.ReadFull(u.port, ack); err != nil {
+		return nil, wrapf("failed to receive %s answer: %v", order, err)
+	}
+
+	if header[0] != u.reqID {
+		return nil, wrapf("%s answer has id %d, expected %d", order, header[0], u.reqID)
+	}
+
+	if ackOrder := McuOrder(header[3]); ackOrder != order|McuOrderAck {
+		return nil, wrapf("unexpected answer %s to %s command", ackOrder, order)
+	}
+
+	return ack, nil
+}
+
+var _ Transport = &USB{}
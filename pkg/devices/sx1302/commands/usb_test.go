@@ -0,0 +1,313 @@
+package commands_test
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"periph.io/x/conn/v3/spi"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+	"github.com/cedi/go_sx1302/pkg/serial/serialtest"
+)
+
+// mcuCommand is a command received by the MCU emulation
+type mcuCommand struct {
+	id      uint8
+	order   commands.McuOrder
+	payload []byte
+}
+
+// mcu emulates the MCU of a USB concentrator on the master side of a pty, executing SPI requests on a simulator
+type mcu struct {
+	master *os.File
+	spi    spi.Conn
+
+	mu       sync.Mutex
+	received []mcuCommand
+
+	// ackOrder overrides the order of the answers if set
+	ackOrder commands.McuOrder
+
+	// version overrides the firmware version of the ping answer if set
+	version string
+}
+
+// newMCU starts an MCU emulation and returns the path of the tty to open
+func newMCU(t *testing.T) (*mcu, string, *sx1302test.Sim) {
+	t.Helper()
+
+	master, path, err := serialtest.OpenPTY()
+	if err != nil {
+		t.Skipf("no pseudo-terminal: %v", err)
+	}
+
+	sim := sx1302test.NewSim()
+	conn, err := sim.Connect(0, spi.Mode0, 8)
+	if err != nil {
+		t.Fatalf("Connect() failed: %v", err)
+	}
+
+	m := &mcu{master: master, spi: conn}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.serve()
+	}()
+
+	t.Cleanup(func() {
+		master.Close()
+		<-done
+	})
+
+	return m, path, sim
+}
+
+// commands returns the commands received so far
+func (m *mcu) commands() []mcuCommand {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]mcuCommand(nil), m.received...)
+}
+
+// serve answers commands until the pty is closed
+func (m *mcu) serve() {
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(m.master, header); err != nil {
+			return
+		}
+
+		payload := make([]byte, binary.BigEndian.Uint16(header[1:3]))
+		if _, err := io.ReadFull(m.master, payload); err != nil {
+			return
+		}
+
+		cmd := mcuCommand{id: header[0], order: commands.McuOrder(header[3]), payload: payload}
+		m.mu.Lock()
+		m.received = append(m.received, cmd)
+		ackOrder := m.ackOrder
+		m.mu.Unlock()
+
+		if ackOrder == 0 {
+			ackOrder = cmd.order | commands.McuOrderAck
+		}
+
+		ack := m.answer(cmd)
+		answer := []byte{cmd.id, byte(len(ack) >> 8), byte(len(ack)), byte(ackOrder)}
+		if _, err := m.master.Write(append(answer, ack...)); err != nil {
+			return
+		}
+	}
+}
+
+// answer executes cmd and returns the payload of its answer
+func (m *mcu) answer(cmd mcuCommand) []byte {
+	switch cmd.order {
+	case commands.McuOrderPing:
+		m.mu.Lock()
+		version := m.version
+		m.mu.Unlock()
+		if version == "" {
+			version = commands.McuFirmwareVersion
+		}
+
+		// a unique ID of three words followed by the version
+		return append([]byte{0x00, 0x3A, 0x00, 0x1F, 0x31, 0x33, 0x50, 0x11, 0x20, 0x38, 0x38, 0x32}, version+"\x00"...)
+
+	case commands.McuOrderReset:
+		return []byte{0x00}
+
+	case commands.McuOrderMultipleSPI:
+		var ack []byte
+		for req := cmd.payload; len(req) >= 4; {
+			id, size := req[0], int(binary.BigEndian.Uint16(req[2:4]))
+			frame := req[4 : 4+size]
+			req = req[4+size:]
+
+			resp := make([]byte, len(frame))
+			status := byte(0x00)
+			if err := m.spi.Tx(frame, resp); err != nil {
+				status = 0x01
+			}
+
+			ack = append(ack, id, 0x01, status, byte(size>>8), byte(size))
+			ack = append(ack, resp...)
+		}
+		return ack
+	}
+
+	return nil
+}
+
+// spiRequests splits the payload of a MultipleSPI command into its SPI frames
+func spiRequests(t *testing.T, payload []byte) [][]byte {
+	t.Helper()
+
+	var frames [][]byte
+	for i := 0; len(payload) > 0; i++ {
+		if len(payload) < 4 {
+			t.Fatalf("truncated spi request header % X", payload)
+		}
+
+		if payload[0] != uint8(i) || payload[1] != 0x01 {
+			t.Errorf("spi request %d has id %d and type %d, want id %d and type 1", i, payload[0], payload[1], i)
+		}
+
+		size := int(binary.BigEndian.Uint16(payload[2:4]))
+		frames = append(frames, payload[4:4+size])
+		payload = payload[4+size:]
+	}
+
+	return frames
+}
+
+func TestUSBPing(t *testing.T) {
+	m, path, _ := newMCU(t)
+
+	usb, err := commands.OpenUSB(path)
+	if err != nil {
+		t.Fatalf("OpenUSB() failed: %v", err)
+	}
+	defer usb.Close()
+
+	info, err := usb.Ping()
+	if err != nil {
+		t.Fatalf("Ping() failed: %v", err)
+	}
+
+	if want := (commands.McuInfo{UniqueID: [3]uint32{0x003A001F, 0x31335011, 0x20383832}, Version: commands.McuFirmwareVersion}); info != want {
+		t.Errorf("Ping() = %+v, want %+v", info, want)
+	}
+
+	// the header carries an incrementing id, the payload size and the order
+	cmds := m.commands()
+	if len(cmds) != 2 {
+		t.Fatalf("mcu received %d commands, want 2", len(cmds))
+	}
+
+	for i, cmd := range cmds {
+		if cmd.id != uint8(i+1) || cmd.order != commands.McuOrderPing || len(cmd.payload) != 0 {
+			t.Errorf("command %d = %+v, want ping with id %d", i, cmd, i+1)
+		}
+	}
+}
+
+func TestUSBUnsupportedVersion(t *testing.T) {
+	m, path, _ := newMCU(t)
+	m.mu.Lock()
+	m.version = "00.02.06"
+	m.mu.Unlock()
+
+	if usb, err := commands.OpenUSB(path); err == nil {
+		usb.Close()
+		t.Fatal("OpenUSB() of an mcu with an unsupported firmware version succeeded")
+	}
+}
+
+func TestUSBRegisters(t *testing.T) {
+	m, path, sim := newMCU(t)
+
+	usb, err := commands.OpenUSB(path)
+	if err != nil {
+		t.Fatalf("OpenUSB() failed: %v", err)
+	}
+	defer usb.Close()
+
+	regs := commands.NewRegisters(usb)
+	if err := regs.RegWrite(commands.RegRadioFeCtrl0RadioAHostFilterGain, 0x0B); err != nil {
+		t.Fatalf("RegWrite() failed: %v", err)
+	}
+
+	if got := sim.Reg(commands.RegRadioFeCtrl0RadioAHostFilterGain); got != 0x0B {
+		t.Errorf("simulator register = 0x%02X, want 0x0B", got)
+	}
+
+	got, err := regs.RegRead(commands.RegRadioFeCtrl0RadioAHostFilterGain)
+	if err != nil {
+		t.Fatalf("RegRead() failed: %v", err)
+	}
+
+	if got != 0x0B {
+		t.Errorf("RegRead() = 0x%02X, want 0x0B", got)
+	}
+
+	// every register access is a MultipleSPI command with a single SX1302 frame
+	for _, cmd := range m.commands()[1:] {
+		if cmd.order != commands.McuOrderMultipleSPI {
+			t.Fatalf("register access sent a %s command", cmd.order)
+		}
+
+		for _, frame := range spiRequests(t, cmd.payload) {
+			if frame[0] != model.SpiMuxTargetSX1302 {
+				t.Errorf("frame % X is not addressed to the SX1302", frame)
+			}
+		}
+	}
+}
+
+func TestUSBBulkWrites(t *testing.T) {
+	m, path, sim := newMCU(t)
+
+	usb, err := commands.OpenUSB(path)
+	if err != nil {
+		t.Fatalf("OpenUSB() failed: %v", err)
+	}
+	defer usb.Close()
+
+	if err := usb.SetWriteMode(model.ComWriteModeBulk); err != nil {
+		t.Fatalf("SetWriteMode() failed: %v", err)
+	}
+
+	for i := uint16(0); i < 3; i++ {
+		if err := usb.DevWrite(model.SpiMuxTargetSX1302, 0x0100+i, byte(0xA0+i)); err != nil {
+			t.Fatalf("DevWrite() failed: %v", err)
+		}
+	}
+
+	if n := len(m.commands()); n != 1 {
+		t.Fatalf("bulk writes were sent before the flush, mcu received %d commands", n)
+	}
+
+	if err := usb.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	cmds := m.commands()
+	if len(cmds) != 2 || cmds[1].order != commands.McuOrderMultipleSPI {
+		t.Fatalf("flush sent %d commands, want a single MultipleSPI command", len(cmds)-1)
+	}
+
+	if frames := spiRequests(t, cmds[1].payload); len(frames) != 3 {
+		t.Errorf("MultipleSPI command holds %d requests, want 3", len(frames))
+	}
+
+	if got, want := sim.Mem(0x0100, 3), []byte{0xA0, 0xA1, 0xA2}; string(got) != string(want) {
+		t.Errorf("simulator memory = % X, want % X", got, want)
+	}
+}
+
+func TestUSBUnexpectedAck(t *testing.T) {
+	m, path, _ := newMCU(t)
+
+	usb, err := commands.OpenUSB(path)
+	if err != nil {
+		t.Fatalf("OpenUSB() failed: %v", err)
+	}
+	defer usb.Close()
+
+	m.mu.Lock()
+	m.ackOrder = commands.McuOrderUnknown
+	m.mu.Unlock()
+
+	_, err = usb.DevRead(model.SpiMuxTargetSX1302, 0x5600)
+	if err == nil || !strings.Contains(err.Error(), "unexpected answer Unknown") {
+		t.Errorf("DevRead() with an unknown command answer = %v, want an unexpected answer error", err)
+	}
+}
@@ -0,0 +1,156 @@
+package sx1302
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+)
+
+// counterRegs are the bytes of the free running 32MHz timestamp counter, most significant byte first
+var counterRegs = []commands.RegID{
+	commands.RegTimestampTimestampMsb2Timestamp,
+	commands.RegTimestampTimestampMsb1Timestamp,
+	commands.RegTimestampTimestampLsb2Timestamp,
+	commands.RegTimestampTimestampLsb1Timestamp,
+}
+
+// ppsCounterRegs are the bytes of the timestamp counter latched on the last PPS, most significant byte first
+var ppsCounterRegs = []commands.RegID{
+	commands.RegTimestampTimestampPpsMsb2TimestampPps,
+	commands.RegTimestampTimestampPpsMsb1TimestampPps,
+	commands.RegTimestampTimestampPpsLsb2TimestampPps,
+	commands.RegTimestampTimestampPpsLsb1TimestampPps,
+}
+
+// counterRefreshInterval is the interval the timestamp counter is read at to keep track of its wraps
+const counterRefreshInterval = 30 * time.Second
+
+// timestampCounter extends the 32 bit timestamp counter of the SX1302, which wraps every ~134s, by counting its wraps.
+// It has to be updated more often than the counter wraps.
+type timestampCounter struct {
+	// inst is the counter value read last
+	inst uint32
+
+	// wraps is the number of times the counter wrapped until inst
+	wraps uint64
+}
+
+// update records the counter value inst read from the concentrator
+func (c *timestampCounter) update(inst uint32) {
+	if inst < c.inst {
+		c.wraps++
+	}
+	c.inst = inst
+}
+
+// expandUs converts the counter value ts latched before the last update to microseconds, including the wraps
+func (c *timestampCounter) expandUs(ts uint32) uint64 {
+	wraps := c.wraps
+	if ts > c.inst && wraps > 0 {
+		// latched before the last wrap
+		wraps--
+	}
+
+	return wraps<<27 | uint64(ts>>5)
+}
+
+// CounterUs returns the current value of the concentrator counter in microseconds. Unlike the 32 bit CountUs of the
+// packets it does not wrap, it counts from the start of the concentrator.
+func (d *Dev) CounterUs() (uint64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.context.IsStarted {
+		return 0, errors.New("concentrator is not started")
+	}
+
+	d.regMu.Lock()
+	defer d.regMu.Unlock()
+
+	return d.counterUs()
+}
+
+// refreshCounter reads the timestamp counter every counterRefreshInterval until stop is closed, so that no wrap is
+// missed while no packets are received or sent
+func (d *Dev) refreshCounter(stop <-chan struct{}) {
+	ticker := time.NewTicker(counterRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		d.regMu.Lock()
+		_, err := d.counterUs()
+		d.regMu.Unlock()
+
+		if err != nil {
+			log.WithError(err).Warn("Failed to read the timestamp counter")
+		}
+	}
+}
+
+// counterUs reads the timestamp counter and returns it in microseconds, including the wraps. The caller holds regMu
+func (d *Dev) counterUs() (uint64, error) {
+	inst, err := readCounter(d.regs)
+	if err != nil {
+		return 0, err
+	}
+	d.counter.update(inst)
+
+	return d.counter.expandUs(inst), nil
+}
+
+// PPSCounterUs returns the counter value latched on the last PPS of the GPS in microseconds, on the same scale as
+// CounterUs
+func (d *Dev) PPSCounterUs() (uint64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.context.IsStarted {
+		return 0, errors.New("concentrator is not started")
+	}
+
+	d.regMu.Lock()
+	defer d.regMu.Unlock()
+
+	pps, err := readCounterRegs(d.regs, ppsCounterRegs)
+	if err != nil {
+		return 0, err
+	}
+
+	// the counter is read after the latch, so the wraps are counted up to the latch
+	inst, err := readCounter(d.regs)
+	if err != nil {
+		return 0, err
+	}
+	d.counter.update(inst)
+
+	return d.counter.expandUs(pps), nil
+}
+
+// readCounter reads the current value of the 32MHz timestamp counter
+func readCounter(regs *commands.Registers) (uint32, error) {
+	return readCounterRegs(regs, counterRegs)
+}
+
+// readCounterRegs reads a counter value from its bytes ids, most significant byte first
+func readCounterRegs(regs *commands.Registers, ids []commands.RegID) (uint32, error) {
+	values, err := regs.RegReadBatch(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var counter uint32
+	for _, v := range values {
+		counter = counter<<8 | uint32(v)
+	}
+
+	return counter, nil
+}
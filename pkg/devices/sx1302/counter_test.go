@@ -0,0 +1,94 @@
+package sx1302_test
+
+import (
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+)
+
+func TestTimestampCounter(t *testing.T) {
+	// a step updates the counter with the values polled, then expands the values latched before the last poll
+	type expand struct {
+		ts   uint32
+		want uint64
+	}
+
+	tests := []struct {
+		name   string
+		polls  []uint32
+		expand []expand
+	}{
+		{
+			name:   "no wrap",
+			polls:  []uint32{0x00000100, 0x00010000},
+			expand: []expand{{0x00008000, 0x400}, {0x00010000, 0x800}},
+		},
+		{
+			name:  "32MHz wrap",
+			polls: []uint32{0xFFFFFFE0, 0x00000020},
+			expand: []expand{
+				{0x00000020, 1<<27 | 0x1},
+				{0xFFFFFFC0, 0x7FFFFFE},
+			},
+		},
+		{
+			// the counter wrapped between two polls, the packets were latched on either side of the wrap
+			name:  "wrap between polls",
+			polls: []uint32{0x40000000, 0xF0000000, 0x00001000},
+			expand: []expand{
+				{0xFFFFFF00, 0x7FFFFF8},
+				{0x00000800, 1<<27 | 0x40},
+			},
+		},
+		{
+			name:  "no wrap counted before the first poll",
+			polls: []uint32{0x00001000},
+			expand: []expand{
+				{0xFFFFFF00, 0x7FFFFF8},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c sx1302.TimestampCounter
+			for _, inst := range tt.polls {
+				c.Update(inst)
+			}
+
+			for _, e := range tt.expand {
+				if got := c.ExpandUs(e.ts); got != e.want {
+					t.Errorf("ExpandUs(0x%08X) = 0x%X, want 0x%X", e.ts, got, e.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTimestampCounterBeyond32Bits(t *testing.T) {
+	// 32 wraps of the 32MHz counter are 2^32µs, the microsecond counter goes on beyond 32 bits
+	var c sx1302.TimestampCounter
+	for i := 0; i < 32; i++ {
+		c.Update(0x80000000)
+		c.Update(0xFFFFFFE0)
+
+		if i < 31 {
+			c.Update(0x00000000)
+		}
+	}
+
+	if got := c.ExpandUs(0xFFFFFFE0); got != 0xFFFFFFFF {
+		t.Errorf("ExpandUs() before the 2^32µs boundary = 0x%X, want 0xFFFFFFFF", got)
+	}
+
+	c.Update(0x00000020)
+
+	if got := c.ExpandUs(0x00000020); got != 0x100000001 {
+		t.Errorf("ExpandUs() after the 2^32µs boundary = 0x%X, want 0x100000001", got)
+	}
+
+	// a packet latched just before the boundary keeps its value
+	if got := c.ExpandUs(0xFFFFFFE0); got != 0xFFFFFFFF {
+		t.Errorf("ExpandUs() of a packet latched before the boundary = 0x%X, want 0xFFFFFFFF", got)
+	}
+}
@@ -0,0 +1,48 @@
+package sx1302
+
+import "github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+
+// TxGainLUT returns the TX gain LUT of rfChain, including the offsets found by the calibration
+func (d *Dev) TxGainLUT(rfChain uint8) model.TxGainLUT {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.context.TxGainLUT[rfChain]
+}
+
+// ParseRxBuffer splits buf into packets and returns their payloads, the number of bytes they take in buf and the
+// number of skipped bytes
+func ParseRxBuffer(buf []byte) (payloads [][]byte, parsed int, skipped int) {
+	packets, skipped := parseRxBuffer(buf)
+	for _, p := range packets {
+		payloads = append(payloads, p.payload)
+		parsed += rxPktHeadSize + len(p.payload) + rxPktTailSize + len(p.tsMetrics)
+	}
+
+	return payloads, parsed, skipped
+}
+
+// LoRaTimestampCorrection returns the delay in microseconds between the end of a LoRa packet and its timestamp
+func LoRaTimestampCorrection(bandwidth model.Bandwith, sf uint8, cr uint8, crcEn bool, size int, service bool) uint32 {
+	return loraTimestampCorrection(bandwidth, sf, cr, crcEn, size, service)
+}
+
+// ValidateTxGainLUT checks lut against the gain fields of radioType
+func ValidateTxGainLUT(lut model.TxGainLUT, radioType model.RadioType) error {
+	return validateTxGainLUT(lut, radioType)
+}
+
+// TimestampCounter extends the values of the 32MHz timestamp counter by counting its wraps
+type TimestampCounter struct {
+	c timestampCounter
+}
+
+// Update records the counter value inst read from the concentrator
+func (c *TimestampCounter) Update(inst uint32) {
+	c.c.update(inst)
+}
+
+// ExpandUs converts the counter value ts latched before the last update to microseconds, including the wraps
+func (c *TimestampCounter) ExpandUs(ts uint32) uint64 {
+	return c.c.expandUs(ts)
+}
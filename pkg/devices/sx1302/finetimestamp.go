@@ -0,0 +1,176 @@
+package sx1302
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+const (
+	// otpModelIDAddr is the OTP byte holding the chip model ID, chipModelSX1302 and chipModelSX1303 are its values
+	otpModelIDAddr  int32 = 0xD0
+	chipModelSX1302 uint8 = 0x02
+	chipModelSX1303 uint8 = 0x03
+
+	// ftimeTsMetricsMax is the number of timestamp metrics stored per packet, ftimeNbSymbols the number of symbols
+	// the modems measure them over beyond the preamble, as in the reference HAL
+	ftimeTsMetricsMax = 32
+	ftimeNbSymbols    = 0
+
+	// ftimeTickNs is the period of the 32MHz timestamp counter in nanoseconds, ppsPeriodTicks the PPS period in ticks
+	ftimeTickNs    = 31.25
+	ppsPeriodTicks = 32000000
+	counterFreqHz  = 32e6
+
+	// ppsToleranceTicks is the deviation of the PPS period accepted for the crystal error of the concentrator
+	ppsToleranceTicks = 320
+)
+
+// WithFineTimestampConfig enables fine timestamping of LoRa packets, which is only supported by the SX1303. The
+// chip model is checked by Start.
+func WithFineTimestampConfig(conf *model.FineTimeStampConf) SX1302Config {
+	return func(d *Dev) {
+		if d.context.IsStarted {
+			log.Fatal("gateway is already running. Please stop it before changing configuration")
+		}
+
+		if conf.Enable {
+			switch conf.Mode {
+			case model.FineTsModeHighCap, model.FineTsModeAllSf:
+			default:
+				log.Fatalf("invalid fine timestamping mode %d", conf.Mode)
+			}
+		}
+
+		d.context.FineTimestampCfg = conf
+
+		log.WithFields(log.Fields{
+			"enable": conf.Enable,
+			"mode":   conf.Mode,
+		}).Info("Fine timestamp configuration loaded")
+	}
+}
+
+// fineTimestampEnabled returns whether fine timestamping is configured
+func (d *Dev) fineTimestampEnabled() bool {
+	return d.context.FineTimestampCfg != nil && d.context.FineTimestampCfg.Enable
+}
+
+// checkFineTimestamp verifies that the chip supports fine timestamping if it is enabled
+func (d *Dev) checkFineTimestamp() error {
+	if !d.fineTimestampEnabled() {
+		return nil
+	}
+
+	modelID, err := d.readModelID()
+	if err != nil {
+		return fmt.Errorf("failed to read the chip model id: %w", err)
+	}
+
+	switch modelID {
+	case chipModelSX1303:
+		return nil
+	case chipModelSX1302:
+		return fmt.Errorf("fine timestamping is not supported by the SX1302 (chip model id 0x%02X), it requires a SX1303", modelID)
+	}
+
+	return fmt.Errorf("fine timestamping is not supported by the chip model id 0x%02X, it requires a SX1303", modelID)
+}
+
+// readModelID reads the chip model ID from the OTP
+func (d *Dev) readModelID() (uint8, error) {
+	if err := d.regs.RegWrite(commands.RegOtpByteAddrAddr, otpModelIDAddr); err != nil {
+		return 0, err
+	}
+
+	id, err := d.regs.RegRead(commands.RegOtpRdDataRdData)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint8(id), nil
+}
+
+// configureTimestampMode selects the timestamp latched for received packets: the end of the packet as on the SX1302,
+// or the end of the header followed by the timestamp metrics of the multi-SF and the LoRa service modems when fine
+// timestamping is enabled
+func (d *Dev) configureTimestampMode() error {
+	if !d.fineTimestampEnabled() {
+		return d.regs.RegWrite(commands.RegRxTopRxBufferLegacyTimestampLegacyTimestamp, 1)
+	}
+
+	return d.writeRegs([]regWrite{
+		{commands.RegRxTopRxBufferLegacyTimestampLegacyTimestamp, 0},
+		{commands.RegRxTopRxBufferTimestampCfgMaxTsMetrics, ftimeTsMetricsMax},
+		{commands.RegRxTopTimestampEnable, 1},
+		{commands.RegRxTopTimestampNbSymb, ftimeNbSymbols},
+		{commands.RegRxTopLoraServiceFskTimestampEnable, 1},
+		{commands.RegRxTopLoraServiceFskTimestampNbSymb, ftimeNbSymbols},
+	})
+}
+
+// ftimeHeaderTicks returns the time in counter ticks from the end of the preamble to the timestamp latched at the end
+// of the header, as corrected by the reference HAL: 8 header symbols and 4.25 symbols of sync word and start frame
+// delimiter less a chip, 2 symbols more for SF5 and SF6. The symbols of the node are shorter by its relative
+// frequency error freqErr.
+func ftimeHeaderTicks(sf model.DataRate, bw model.Bandwith, freqErr float64) float64 {
+	symbols := 8 + 4
+	if sf == model.DrLoraSf5 || sf == model.DrLoraSf6 {
+		symbols += 2
+	}
+
+	chips := 1 << sf
+	chipTicks := counterFreqHz / float64(bw.Hz())
+	return float64(symbols*chips+chips/4-1) * chipTicks / (1 + freqErr)
+}
+
+// fineTimestamp returns the time of the end of the preamble of the LoRa packet p since the PPS latched at pps in
+// nanoseconds, refined by the mean of its timestamp metrics. The time of the header is corrected for the spreading
+// factor and bandwidth of pkt and for its frequency offset from the channel, which includes the rounding of the IF
+// frequency. ok is false if fine timestamping is disabled for the packet or no PPS was latched within the second
+// before it.
+func (d *Dev) fineTimestamp(p rxPacket, pkt model.PktRx, pps uint32) (ns uint32, ok bool) {
+	if p.channel == ifChainFSK || pps == 0 || pkt.FreqHz == 0 || model.Bandwith(pkt.Bandwidth).Hz() == 0 {
+		return 0, false
+	}
+
+	sf := model.DataRate(p.datarate)
+	if d.context.FineTimestampCfg.Mode == model.FineTsModeHighCap && sf > model.DrLoraSf10 {
+		return 0, false
+	}
+
+	// the metrics are pairs of average and standard deviation, the average is an offset in counter ticks
+	n := min(len(p.tsMetrics)/2, ftimeTsMetricsMax)
+	if n == 0 {
+		return 0, false
+	}
+
+	var sum int
+	for i := 0; i < n; i++ {
+		sum += int(p.tsMetrics[2*i])
+	}
+
+	freqErr := float64(pkt.FreqOffset) / float64(pkt.FreqHz)
+	ticks := float64(int32(p.timestamp-pps)) - ftimeHeaderTicks(sf, model.Bandwith(pkt.Bandwidth), freqErr)
+	if ticks < 0 {
+		// a PPS was latched after the preamble, before the RX buffer was read
+		ticks += ppsPeriodTicks
+	}
+
+	if ticks < 0 || ticks >= ppsPeriodTicks+ppsToleranceTicks {
+		return 0, false
+	}
+
+	ftime := (ticks + float64(sum)/float64(n)) * ftimeTickNs
+	switch {
+	case ftime < 0:
+		ftime += 1e9
+	case ftime >= 1e9:
+		ftime -= 1e9
+	}
+
+	return uint32(ftime), true
+}
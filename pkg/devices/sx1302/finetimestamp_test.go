@@ -0,0 +1,112 @@
+package sx1302_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// newFtimeDev returns a started SX1303 with fine timestamping in mode and a PPS latched at the counter value pps
+func newFtimeDev(t *testing.T, ftime *model.FineTimeStampConf, pps uint32) (*sx1302.Dev, *sx1302test.Sim) {
+	t.Helper()
+
+	d, sim := newSimDev(t,
+		sx1302.WithIfChainConfig(0, &model.RxIf{Enable: true, FreqHz: -250000}),
+		sx1302.WithFineTimestampConfig(ftime),
+	)
+	sim.ModelID = sx1302test.ModelIDSX1303
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	t.Cleanup(func() { d.Stop() })
+
+	sim.SetReg(commands.RegTimestampTimestampPpsMsb2TimestampPps, int32(pps>>24))
+	sim.SetReg(commands.RegTimestampTimestampPpsMsb1TimestampPps, int32(pps>>16&0xFF))
+	sim.SetReg(commands.RegTimestampTimestampPpsLsb2TimestampPps, int32(pps>>8&0xFF))
+	sim.SetReg(commands.RegTimestampTimestampPpsLsb1TimestampPps, int32(pps&0xFF))
+
+	return d, sim
+}
+
+func TestFineTimestampConfiguration(t *testing.T) {
+	tests := []struct {
+		name        string
+		ftime       *model.FineTimeStampConf
+		legacy      int32
+		enable      int32
+		doubleDemod int32
+	}{
+		{"disabled", &model.FineTimeStampConf{Enable: false, Mode: 7}, 1, 0, 0x00},
+		{"high capacity", &model.FineTimeStampConf{Enable: true, Mode: model.FineTsModeHighCap}, 0, 1, 0x3F},
+		{"all sf", &model.FineTimeStampConf{Enable: true, Mode: model.FineTsModeAllSf}, 0, 1, 0xFF},
+	}
+
+	for _, tt := range tests {
+		_, sim := newFtimeDev(t, tt.ftime, 0)
+
+		if got := sim.Reg(commands.RegRxTopRxBufferLegacyTimestampLegacyTimestamp); got != tt.legacy {
+			t.Errorf("%s: legacy timestamp = %d, want %d", tt.name, got, tt.legacy)
+		}
+
+		for _, id := range []commands.RegID{commands.RegRxTopTimestampEnable, commands.RegRxTopLoraServiceFskTimestampEnable} {
+			if got := sim.Reg(id); got != tt.enable {
+				t.Errorf("%s: %s = %d, want %d", tt.name, commands.RegisterMap()[id].Name, got, tt.enable)
+			}
+		}
+
+		if got := sim.Reg(commands.RegArbMcuArbDebugCfg3ArbDebugCfg3); got != tt.doubleDemod {
+			t.Errorf("%s: ARB double demodulation = 0x%02X, want 0x%02X", tt.name, got, tt.doubleDemod)
+		}
+	}
+}
+
+func TestFineTimestamp(t *testing.T) {
+	const pps = 1000000
+
+	// the counter is latched at the end of the header, 401152 ticks after the preamble of a SF7 packet and 116480
+	// ticks after the one of a SF5 packet. The preambles end 100ms after the PPS, the metrics add 15 ticks.
+	metrics := []int8{10, 1, 20, 2}
+	tests := []struct {
+		name   string
+		mode   model.FineTimestampingMode
+		pkt    sx1302test.RxPacket
+		ok     bool
+		wantNs uint32
+	}{
+		{"SF7", model.FineTsModeAllSf, sx1302test.RxPacket{Datarate: 7, Timestamp: pps + 401152 + 3200000, TsMetrics: metrics}, true, 100000468},
+		{"SF5", model.FineTsModeAllSf, sx1302test.RxPacket{Datarate: 5, Timestamp: pps + 116480 + 3200000, TsMetrics: metrics}, true, 100000468},
+
+		// the node is 23841Hz or 27.5ppm fast, its header is 11 ticks shorter
+		{"frequency offset", model.FineTsModeAllSf, sx1302test.RxPacket{Datarate: 7, FreqOffsetError: 200000, Timestamp: pps + 401152 + 3200000, TsMetrics: metrics}, true, 100000812},
+
+		// a PPS was latched between the preamble and the header
+		{"pps after preamble", model.FineTsModeAllSf, sx1302test.RxPacket{Datarate: 7, Timestamp: pps + 100000, TsMetrics: metrics}, true, 990589468},
+
+		{"SF11 high capacity", model.FineTsModeHighCap, sx1302test.RxPacket{Datarate: 11, Timestamp: pps + 3200000, TsMetrics: metrics}, false, 0},
+		{"no metrics", model.FineTsModeAllSf, sx1302test.RxPacket{Datarate: 7, Timestamp: pps + 3200000}, false, 0},
+	}
+
+	for _, tt := range tests {
+		d, sim := newFtimeDev(t, &model.FineTimeStampConf{Enable: true, Mode: tt.mode}, pps)
+
+		tt.pkt.Payload = []byte{0x01}
+		sim.InjectRx(tt.pkt.Encode())
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		packets, err := d.Receive(ctx, 1)
+		cancel()
+		if err != nil {
+			t.Fatalf("%s: Receive() failed: %v", tt.name, err)
+		}
+
+		if got := packets[0]; got.FtimeReceived != tt.ok || got.Ftime != tt.wantNs {
+			t.Errorf("%s: Receive() ftime %v %dns, want %v %dns", tt.name, got.FtimeReceived, got.Ftime, tt.ok, tt.wantNs)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package sx1302_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1261"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// newLBTDev returns a started device sending on rf chain 0 with listen-before-talk on 868.1MHz
+func newLBTDev(t *testing.T) (*sx1302.Dev, *sx1302test.SX1261) {
+	t.Helper()
+
+	radio := &sx1302test.SX1261{PatchVersion: "2D06"}
+	patch := sx1261.Patch{Words: []uint32{0x01020304, 0x05060708}, Version: "2D06"}
+
+	conf := model.NewSX1261Conf()
+	conf.Enable = true
+	conf.RssiOffset = -4
+	conf.LbtConf = model.LBTConf{
+		Enable:     true,
+		RssiTarget: -80,
+		NbChannel:  1,
+		Channels: []model.LBTChanConf{
+			{FreqHz: 868100000, Bandwidth: uint8(model.Bw125kHz), ScanTimeUs: model.ScanTime12Us, TransmitTimeMs: 4000},
+		},
+	}
+
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+	rf.TxEnable = true
+
+	d, sim := newSimDev(t,
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithTxGainLUT(0, &model.TxGainLUT{LUT: []model.TXGain{{RfPower: 14, PwrIdx: 20}}}),
+		sx1302.WithSX1261Config(conf),
+		sx1302.WithSX1261(radio, patch),
+	)
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	t.Cleanup(func() { stopTx(d, sim) })
+
+	return d, radio
+}
+
+// loraPacket returns an immediate LoRa packet on freqHz
+func loraPacket(freqHz uint32) model.PktTx {
+	pkt := model.PktTx{
+		FreqHz:     freqHz,
+		TxMode:     model.TxModeImmediate,
+		RfPower:    14,
+		Modulation: model.ModLora,
+		Bandwidth:  uint8(model.Bw125kHz),
+		Datarate:   uint32(model.DrLoraSf7),
+		Coderate:   model.CrLora45,
+		Size:       4,
+	}
+	copy(pkt.Payload[:], "ping")
+
+	return pkt
+}
+
+func TestSendStartsLBT(t *testing.T) {
+	d, radio := newLBTDev(t)
+	before := len(radio.Frames())
+
+	if err := d.Send(loraPacket(868100000)); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+
+	// the radio is tuned to the channel before sensing it for 24 samples below -80dBm, before the -4dB offset
+	freq := sx1261.FreqToReg(868100000)
+	tune := []byte{byte(sx1261.OpcodeSetRfFrequency), byte(freq >> 24), byte(freq >> 16), byte(freq >> 8), byte(freq)}
+	lbt := []byte{byte(sx1261.OpcodeLBTStart), 11, 0x00, 24, 152, 1}
+
+	frames := radio.Frames()[before:]
+	tuned := -1
+	for i, frame := range frames {
+		if bytes.Equal(frame, tune) {
+			tuned = i
+		}
+	}
+
+	if tuned < 0 {
+		t.Fatalf("Send() did not tune the sx1261 to the lbt channel, frames % X", frames)
+	}
+
+	if last := frames[len(frames)-1]; !bytes.Equal(last, lbt) {
+		t.Errorf("last sx1261 frame = % X, want LBTStart % X", last, lbt)
+	}
+}
+
+func TestSendWithoutLBTChannel(t *testing.T) {
+	d, radio := newLBTDev(t)
+	before := len(radio.Frames())
+
+	err := d.Send(loraPacket(869525000))
+	if err == nil || !strings.Contains(err.Error(), "no lbt channel") {
+		t.Fatalf("Send() outside the lbt channels = %v, want a missing lbt channel error", err)
+	}
+
+	if n := len(radio.Frames()) - before; n != 0 {
+		t.Errorf("Send() outside the lbt channels sent %d frames to the sx1261", n)
+	}
+}
+
+func TestStartLBTWithoutPatch(t *testing.T) {
+	conf := model.NewSX1261Conf()
+	conf.Enable = true
+	conf.LbtConf = model.LBTConf{
+		Enable:    true,
+		NbChannel: 1,
+		Channels:  []model.LBTChanConf{{FreqHz: 868100000, Bandwidth: uint8(model.Bw125kHz), ScanTimeUs: model.ScanTime5000Us}},
+	}
+
+	d, _ := newSimDev(t, sx1302.WithSX1261Config(conf), sx1302.WithSX1261(&sx1302test.SX1261{}, sx1261.Patch{}))
+	if err := d.Start(); err == nil {
+		d.Stop()
+		t.Fatal("Start() enabled lbt without the sx1261 firmware patch")
+	}
+}
@@ -110,9 +110,13 @@ func NewLgwContext(boardCfg *BoardConf, rfChainCfg []RxRf, ifChainCfg []RxIf, de
 	}
 
 	if len(txGainLUT) == 0 {
-		txGainLUT = []TxGainLUT{
-			NewTxGainLUTWithDefaults(),
-			NewTxGainLUTWithDefaults(),
+		// the defaults follow the radio type of each rf chain
+		for i := 0; i < int(MaxRfChains); i++ {
+			var rf RxRf
+			if i < len(rfChainCfg) {
+				rf = rfChainCfg[i]
+			}
+			txGainLUT = append(txGainLUT, NewTxGainLUTWithDefaults(rf.Type))
 		}
 	}
 
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+// The 32 bit CountUs of the packets wraps every ~71 minutes. The helpers below treat two counter values as close to
+// each other, i.e. less than half the range (~35 minutes) apart.
+
+// CountUsSub returns the time from b to a, negative if a is before b
+func CountUsSub(a, b uint32) time.Duration {
+	return time.Duration(int32(a-b)) * time.Microsecond
+}
+
+// CountUsAdd returns the counter value d after c, d is truncated to microseconds
+func CountUsAdd(c uint32, d time.Duration) uint32 {
+	return c + uint32(d.Microseconds())
+}
+
+// CountUsBefore returns whether a is before b
+func CountUsBefore(a, b uint32) bool {
+	return int32(a-b) < 0
+}
+
+// ExtendCountUs returns the extended 64 bit counter value closest to ref whose lower 32 bits are c
+func ExtendCountUs(ref uint64, c uint32) uint64 {
+	ext := ref + uint64(int64(int32(c-uint32(ref))))
+	if int32(c-uint32(ref)) < 0 && ext > ref {
+		// the counter does not go below 0, the closest value is after ref
+		return uint64(c)
+	}
+
+	return ext
+}
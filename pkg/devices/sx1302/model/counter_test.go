@@ -0,0 +1,53 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+func TestExtendCountUs(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  uint64
+		c    uint32
+		want uint64
+	}{
+		{"same", 0x1000, 0x1000, 0x1000},
+		{"after ref", 0x1000, 0x2000, 0x2000},
+		{"before ref", 0x2000, 0x1000, 0x1000},
+		{"across the 2^32 boundary", 0xFFFFFF00, 0x00000100, 0x100000100},
+		{"back across the 2^32 boundary", 0x100000100, 0xFFFFFF00, 0xFFFFFF00},
+		{"second wrap", 0x1FFFFFFF0, 0x00000010, 0x200000010},
+		{"not below 0", 0x10, 0xFFFFFFF0, 0xFFFFFFF0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.ExtendCountUs(tt.ref, tt.c); got != tt.want {
+				t.Errorf("ExtendCountUs(0x%X, 0x%08X) = 0x%X, want 0x%X", tt.ref, tt.c, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountUsWrap(t *testing.T) {
+	before, after := uint32(0xFFFFFFF0), uint32(0x00000010)
+
+	if got := model.CountUsSub(after, before); got != 32*time.Microsecond {
+		t.Errorf("CountUsSub() across the wrap = %v, want 32µs", got)
+	}
+
+	if got := model.CountUsSub(before, after); got != -32*time.Microsecond {
+		t.Errorf("CountUsSub() back across the wrap = %v, want -32µs", got)
+	}
+
+	if got := model.CountUsAdd(before, 32*time.Microsecond); got != after {
+		t.Errorf("CountUsAdd() across the wrap = 0x%08X, want 0x%08X", got, after)
+	}
+
+	if !model.CountUsBefore(before, after) || model.CountUsBefore(after, before) {
+		t.Error("CountUsBefore() does not order the values across the wrap")
+	}
+}
@@ -1,5 +1,53 @@
 package model
 
+// Status of a received packet
+const (
+	// StatUndefined is the status of a packet whose CRC status is unknown
+	StatUndefined uint8 = 0x00
+	// StatNoCrc is the status of a packet sent without CRC
+	StatNoCrc uint8 = 0x01
+	// StatCrcBad is the status of a packet with a CRC error
+	StatCrcBad uint8 = 0x11
+	// StatCrcOk is the status of a packet with a valid CRC
+	StatCrcOk uint8 = 0x10
+)
+
+// Modulation of a packet
+const (
+	// ModUndefined means the modulation is unknown
+	ModUndefined uint8 = 0x00
+	// ModCw is a continuous wave
+	ModCw uint8 = 0x08
+	// ModLora is a LoRa modulated packet
+	ModLora uint8 = 0x10
+	// ModFsk is a FSK modulated packet
+	ModFsk uint8 = 0x20
+)
+
+// Coding rate of a LoRa packet
+const (
+	// CrUndefined means the coding rate is unknown
+	CrUndefined uint8 = 0x00
+	// CrLora45 is the coding rate 4/5
+	CrLora45 uint8 = 0x01
+	// CrLora46 is the coding rate 4/6
+	CrLora46 uint8 = 0x02
+	// CrLora47 is the coding rate 4/7
+	CrLora47 uint8 = 0x03
+	// CrLora48 is the coding rate 4/8
+	CrLora48 uint8 = 0x04
+)
+
+// TX mode of a packet to send
+const (
+	// TxModeImmediate sends the packet as soon as possible
+	TxModeImmediate uint8 = 0
+	// TxModeTimestamped sends the packet when the concentrator counter reaches CountUs
+	TxModeTimestamped uint8 = 1
+	// TxModeOnGPS sends the packet on the next GPS PPS
+	TxModeOnGPS uint8 = 2
+)
+
 // Structure containing the metadata of a packet that was received and a pointer to the payload
 type PktRx struct {
 	FreqHz        uint32     // central frequency of the IF chain
@@ -7,6 +55,7 @@ type PktRx struct {
 	IfChain       uint8      // by which IF chain was packet received
 	Status        uint8      // status of the received packet
 	CountUs       uint32     // internal concentrator counter for timestamping, 1 microsecond resolution
+	CountUs64     uint64     // CountUs extended to 64 bits, monotonic since the concentrator was started
 	RfChain       uint8      // through which RF chain the packet was received
 	ModemID       uint8      // Modem ID
 	Modulation    uint8      // modulation used by the packet
@@ -30,6 +79,7 @@ type PktTx struct {
 	FreqHz     uint32     // center frequency of TX
 	TxMode     uint8      // select on what event/time the TX is triggered
 	CountUs    uint32     // timestamp or delay in microseconds for TX trigger
+	CountUs64  uint64     // extended timestamp for TX trigger, takes precedence over CountUs if set
 	RfChain    uint8      // through which RF chain will the packet be sent
 	RfPower    int8       // TX power, in dBm
 	Modulation uint8      // modulation to use for the packet
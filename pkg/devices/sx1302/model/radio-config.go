@@ -103,8 +103,17 @@ type TXGain struct {
 	PwrIdx uint8
 }
 
-// NewTxGainWithDefaults creates a new TXGain object with default config
-func NewTxGainWithDefaults() TXGain {
+// NewTxGainWithDefaults creates a new TXGain object with default config for a radio of type radioType. The gain
+// fields of a SX1250 differ from those of a SX1255 and SX1257.
+func NewTxGainWithDefaults(radioType RadioType) TXGain {
+	if radioType == RadioTypeSX1250 {
+		return TXGain{
+			RfPower: 14,
+			PaGain:  0,
+			PwrIdx:  17,
+		}
+	}
+
 	return TXGain{
 		RfPower: 14,
 		DigGain: 0,
@@ -123,10 +132,26 @@ type TxGainLUT struct {
 	LUT []TXGain
 }
 
-// NewTxGainLUTWithDefaults creates a new TxGainLUT object with defaults
-func NewTxGainLUTWithDefaults() TxGainLUT {
+// Select returns the entry with the highest RfPower at or below rfPower, the LUT is sorted by ascending RfPower. If
+// rfPower is below all entries, the first entry is returned and ok is false.
+func (l TxGainLUT) Select(rfPower int8) (gain TXGain, ok bool) {
+	for i := len(l.LUT) - 1; i >= 0; i-- {
+		if l.LUT[i].RfPower <= rfPower {
+			return l.LUT[i], true
+		}
+	}
+
+	if len(l.LUT) == 0 {
+		return TXGain{}, false
+	}
+
+	return l.LUT[0], false
+}
+
+// NewTxGainLUTWithDefaults creates a new TxGainLUT object with defaults for a radio of type radioType
+func NewTxGainLUTWithDefaults(radioType RadioType) TxGainLUT {
 	return TxGainLUT{
-		LUT: []TXGain{NewTxGainWithDefaults()},
+		LUT: []TXGain{NewTxGainWithDefaults(radioType)},
 	}
 }
 
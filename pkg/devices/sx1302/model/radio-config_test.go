@@ -0,0 +1,42 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+func TestTxGainLUTSelect(t *testing.T) {
+	lut := model.TxGainLUT{LUT: []model.TXGain{
+		{RfPower: 10, PwrIdx: 12},
+		{RfPower: 14, PwrIdx: 17},
+		{RfPower: 20, PwrIdx: 22},
+	}}
+
+	tests := []struct {
+		name    string
+		rfPower int8
+		want    int8
+		ok      bool
+	}{
+		{"exact first", 10, 10, true},
+		{"exact", 14, 14, true},
+		{"exact last", 20, 20, true},
+		{"between steps", 16, 14, true},
+		{"below the first step", 9, 10, false},
+		{"above the last step", 27, 20, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gain, ok := lut.Select(tt.rfPower)
+			if gain.RfPower != tt.want || ok != tt.ok {
+				t.Errorf("Select(%d) = %ddBm, %v, want %ddBm, %v", tt.rfPower, gain.RfPower, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if _, ok := (model.TxGainLUT{}).Select(14); ok {
+		t.Error("Select() of an empty lut succeeded")
+	}
+}
@@ -12,6 +12,9 @@ const (
 	// SpiMuxTargetRadioB defines generic RadioB
 	SpiMuxTargetRadioB uint8 = 0x02
 
+	// SpiMuxTargetSX1261 defines the SX1261 companion radio, reachable through the MCU of USB concentrators
+	SpiMuxTargetSX1261 uint8 = 0x03
+
 	// MaxIFChains is the number of IF+modem RX chains
 	MaxIFChains int = 10
 
@@ -40,7 +43,7 @@ type COMType int
 const (
 	// ComSPI is to use SPI to communicate with the board
 	ComSPI COMType = iota
-	// ComUSB is to use USB to communicate with the board. NOTE: This is currently unsupported
+	// ComUSB is to use USB to communicate with the board through its MCU bridge
 	ComUSB
 )
 
@@ -187,6 +190,33 @@ func (s SpectralScanStatus) String() string {
 	return "Unknown"
 }
 
+// TxStatus is the status of the TX state machine of a RF chain
+type TxStatus int
+
+const (
+	// TxStatusUnknown means the state machine reports a status not known to the driver
+	TxStatusUnknown TxStatus = iota
+	// TxStatusFree means no packet is scheduled or emitted
+	TxStatusFree
+	// TxStatusScheduled means a packet is waiting for its trigger
+	TxStatusScheduled
+	// TxStatusEmitting means a packet is being emitted
+	TxStatusEmitting
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case TxStatusFree:
+		return "Free"
+	case TxStatusScheduled:
+		return "Scheduled"
+	case TxStatusEmitting:
+		return "Emitting"
+	}
+
+	return "Unknown"
+}
+
 // Bandwith is the values available for the 'bandwidth' parameters (LoRa & FSK)
 // NOTE: directly encode FSK RX bandwidth, do not change
 type Bandwith uint8
@@ -213,6 +243,20 @@ func (b Bandwith) String() string {
 	return "Undefined"
 }
 
+// Hz returns the bandwidth in Hz, 0 for an undefined bandwidth
+func (b Bandwith) Hz() uint32 {
+	switch b {
+	case Bw500kHz:
+		return 500000
+	case Bw250kHz:
+		return 250000
+	case Bw125kHz:
+		return 125000
+	}
+
+	return 0
+}
+
 // DataRate is the values available for the 'datarate' parameters
 // NOTE: LoRa values used directly to code SF bitmask in 'multi' modem, do not change
 type DataRate uint32
@@ -0,0 +1,200 @@
+package sx1302
+
+import (
+	"context"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// OverflowPolicy selects what Packets does with a received packet when its channel is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the consumer. Meanwhile packets pile up in the RX buffer of the concentrator, which
+	// drops them once it is full
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropOldest drops the oldest packet of the channel to make room for the new one
+	OverflowDropOldest
+
+	// OverflowDropNewest drops the new packet
+	OverflowDropNewest
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "Block"
+	case OverflowDropOldest:
+		return "DropOldest"
+	case OverflowDropNewest:
+		return "DropNewest"
+	}
+
+	return "Unknown"
+}
+
+// defaultPacketBuffer is the size of the channel returned by Packets, unless configured with WithPacketBuffer
+const defaultPacketBuffer = 64
+
+// PacketStats are the counters of the packets delivered by Packets
+type PacketStats struct {
+	// Received is the number of packets read from the concentrator
+	Received uint64
+
+	// Delivered is the number of packets sent to the channel, not counting those dropped from it afterwards. Once the
+	// channel is drained, Received is the sum of Delivered, DroppedOldest and DroppedNewest.
+	Delivered uint64
+
+	// DroppedOldest and DroppedNewest are the number of packets dropped by the overflow policy
+	DroppedOldest uint64
+	DroppedNewest uint64
+}
+
+// packetCounters are the counters behind PacketStats
+type packetCounters struct {
+	received      atomic.Uint64
+	delivered     atomic.Uint64
+	droppedOldest atomic.Uint64
+	droppedNewest atomic.Uint64
+}
+
+// WithPacketBuffer configures the size of the channel returned by Packets and what happens when it is full
+func WithPacketBuffer(size int, policy OverflowPolicy) SX1302Config {
+	return func(d *Dev) {
+		if d.context.IsStarted {
+			log.Fatal("gateway is already running. Please stop it before changing configuration")
+		}
+
+		if size < 0 {
+			log.Fatalf("invalid packet buffer size %d", size)
+		}
+
+		switch policy {
+		case OverflowBlock:
+		case OverflowDropOldest, OverflowDropNewest:
+			if size == 0 {
+				log.Fatalf("overflow policy %s needs a packet buffer", policy)
+			}
+		default:
+			log.Fatalf("invalid overflow policy %d", policy)
+		}
+
+		d.packetBuffer = size
+		d.overflowPolicy = policy
+	}
+}
+
+// Packets delivers the received packets in order until ctx is done or the concentrator is stopped, then the channel
+// is closed. A full channel is handled as configured with WithPacketBuffer, see PacketStats for the drop counters.
+//
+// Packets and Receive share the packets of the concentrator, each packet is returned by only one of them.
+func (d *Dev) Packets(ctx context.Context) <-chan model.PktRx {
+	ch := make(chan model.PktRx, d.packetBuffer)
+
+	d.mu.Lock()
+	started, com, regs, stopped := d.context.IsStarted, d.com, d.regs, d.stopped
+	d.mu.Unlock()
+
+	if !started {
+		log.Error("Cannot deliver packets, the concentrator is not started")
+		close(ch)
+		return ch
+	}
+
+	go func() {
+		defer close(ch)
+
+		err := rxLoop(ctx, com, stopped, func() (int, error) {
+			packets, err := d.takeRx(com, regs)
+			if err != nil {
+				return 0, err
+			}
+
+			for _, pkt := range packets {
+				if !d.deliver(ctx, ch, pkt) {
+					return 0, ctx.Err()
+				}
+			}
+
+			return len(packets), nil
+		})
+
+		if err != nil && ctx.Err() == nil {
+			log.WithError(err).Error("Stopped delivering packets")
+		}
+	}()
+
+	return ch
+}
+
+// PacketStats returns the counters of the packets delivered by Packets
+func (d *Dev) PacketStats() PacketStats {
+	return PacketStats{
+		Received:      d.packetCounters.received.Load(),
+		Delivered:     d.packetCounters.delivered.Load(),
+		DroppedOldest: d.packetCounters.droppedOldest.Load(),
+		DroppedNewest: d.packetCounters.droppedNewest.Load(),
+	}
+}
+
+// takeRx fetches the RX buffer and returns all packets not yet returned by Receive
+func (d *Dev) takeRx(com commands.Transport, regs *commands.Registers) ([]model.PktRx, error) {
+	d.rxMu.Lock()
+	defer d.rxMu.Unlock()
+
+	if err := d.fetchRx(com, regs); err != nil {
+		return nil, err
+	}
+
+	packets := d.rxPending
+	d.rxPending = nil
+	d.packetCounters.received.Add(uint64(len(packets)))
+
+	return packets, nil
+}
+
+// deliver sends pkt to ch following the overflow policy. It returns false if ctx is done
+func (d *Dev) deliver(ctx context.Context, ch chan model.PktRx, pkt model.PktRx) bool {
+	switch d.overflowPolicy {
+	case OverflowDropOldest:
+		for {
+			select {
+			case ch <- pkt:
+				d.packetCounters.delivered.Add(1)
+				return true
+			default:
+			}
+
+			// the consumer may empty the channel meanwhile, then the send is retried
+			// the evicted packet was counted as delivered
+			select {
+			case <-ch:
+				d.packetCounters.delivered.Add(^uint64(0))
+				d.packetCounters.droppedOldest.Add(1)
+			default:
+			}
+		}
+
+	case OverflowDropNewest:
+		select {
+		case ch <- pkt:
+			d.packetCounters.delivered.Add(1)
+		default:
+			d.packetCounters.droppedNewest.Add(1)
+		}
+		return true
+	}
+
+	select {
+	case ch <- pkt:
+		d.packetCounters.delivered.Add(1)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
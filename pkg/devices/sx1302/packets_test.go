@@ -0,0 +1,183 @@
+package sx1302_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+func TestPacketsDropOldest(t *testing.T) {
+	d, sim := newSimDev(t,
+		sx1302.WithIfChainConfig(0, &model.RxIf{Enable: true, FreqHz: -200000}),
+		sx1302.WithPacketBuffer(1, sx1302.OverflowDropOldest),
+	)
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop()
+
+	for i := 0; i < 3; i++ {
+		sim.InjectRx(sx1302test.RxPacket{Datarate: 7, Payload: []byte{byte(i)}, Timestamp: uint32(i)}.Encode())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := d.Packets(ctx)
+
+	// wait until every packet was delivered or dropped
+	deadline := time.Now().Add(time.Second)
+	for stats := d.PacketStats(); stats.Delivered+stats.DroppedOldest < 3; stats = d.PacketStats() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Packets() handled %+v, want 3 packets", stats)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// the channel keeps the newest packet
+	if pkt := <-ch; pkt.Payload[0] != 2 {
+		t.Errorf("Packets() delivered packet %d, want packet 2", pkt.Payload[0])
+	}
+
+	want := sx1302.PacketStats{Received: 3, Delivered: 1, DroppedOldest: 2}
+	if got := d.PacketStats(); got != want {
+		t.Errorf("PacketStats() = %+v, want %+v", got, want)
+	}
+}
+
+// waitPacketStats waits until the packets delivered and dropped by Packets add up to n
+func waitPacketStats(t *testing.T, d *sx1302.Dev, n uint64) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for stats := d.PacketStats(); stats.Delivered+stats.DroppedOldest+stats.DroppedNewest < n; stats = d.PacketStats() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Packets() handled %+v, want %d packets", stats, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPacketsDropNewest(t *testing.T) {
+	d, sim := newSimDev(t,
+		sx1302.WithIfChainConfig(0, &model.RxIf{Enable: true, FreqHz: -200000}),
+		sx1302.WithPacketBuffer(1, sx1302.OverflowDropNewest),
+	)
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop()
+
+	for i := 0; i < 3; i++ {
+		sim.InjectRx(sx1302test.RxPacket{Datarate: 7, Payload: []byte{byte(i)}, Timestamp: uint32(i)}.Encode())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := d.Packets(ctx)
+
+	waitPacketStats(t, d, 3)
+
+	// the channel keeps the oldest packet
+	if pkt := <-ch; pkt.Payload[0] != 0 {
+		t.Errorf("Packets() delivered packet %d, want packet 0", pkt.Payload[0])
+	}
+
+	want := sx1302.PacketStats{Received: 3, Delivered: 1, DroppedNewest: 2}
+	if got := d.PacketStats(); got != want {
+		t.Errorf("PacketStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPacketsBlock(t *testing.T) {
+	d, sim := newSimDev(t,
+		sx1302.WithIfChainConfig(0, &model.RxIf{Enable: true, FreqHz: -200000}),
+		sx1302.WithPacketBuffer(1, sx1302.OverflowBlock),
+	)
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop()
+
+	const count = 5
+	for i := 0; i < count; i++ {
+		sim.InjectRx(sx1302test.RxPacket{Datarate: 7, Payload: []byte{byte(i)}, Timestamp: uint32(i)}.Encode())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := d.Packets(ctx)
+
+	// the delivery waits for the consumer instead of dropping packets
+	waitPacketStats(t, d, 1)
+	time.Sleep(20 * time.Millisecond)
+
+	if got := d.PacketStats(); got.Delivered != 1 || got.DroppedOldest != 0 || got.DroppedNewest != 0 {
+		t.Errorf("PacketStats() of a blocked consumer = %+v, want 1 delivered packet", got)
+	}
+
+	// every packet is delivered in order
+	for i := 0; i < count; i++ {
+		select {
+		case pkt := <-ch:
+			if pkt.Payload[0] != byte(i) {
+				t.Errorf("Packets() delivered packet %d, want packet %d", pkt.Payload[0], i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Packets() delivered %d packets, want %d", i, count)
+		}
+	}
+
+	// the counter follows the send
+	waitPacketStats(t, d, count)
+
+	want := sx1302.PacketStats{Received: count, Delivered: count}
+	if got := d.PacketStats(); got != want {
+		t.Errorf("PacketStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPacketsBlockedCancel(t *testing.T) {
+	d, sim := newSimDev(t,
+		sx1302.WithIfChainConfig(0, &model.RxIf{Enable: true, FreqHz: -200000}),
+		sx1302.WithPacketBuffer(0, sx1302.OverflowBlock),
+	)
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop()
+
+	sim.InjectRx(sx1302test.RxPacket{Datarate: 7, Payload: []byte{0x01}}.Encode())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := d.Packets(ctx)
+
+	// wait until the packet is waiting for the consumer, then give up on it
+	deadline := time.Now().Add(time.Second)
+	for d.PacketStats().Received == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Packets() did not receive the packet")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		// the select of the delivery may pick the consumer or the cancellation
+		if ok {
+			if _, ok = <-ch; ok {
+				t.Error("Packets() delivered a packet after the cancellation")
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Packets() did not close the channel after the cancellation")
+	}
+}
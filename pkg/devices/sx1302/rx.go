@@ -0,0 +1,382 @@
+package sx1302
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+const (
+	// rxIRQTimeout is the longest time to wait for the IRQ before polling the concentrator anyway
+	rxIRQTimeout = time.Second
+
+	// rxBufferSizeReads is the number of reads of the RX buffer size to wait for the size to settle
+	rxBufferSizeReads = 10
+)
+
+// RxLoop calls fetch whenever the concentrator may hold received packets, until ctx is done, the transport is closed
+// or fetch fails. fetch returns the number of packets it received, the loop only waits for the next interrupt once
+// fetch returned no packets. Without an IRQ pin the loop falls back to polling every 10ms.
+//
+// RxLoop returns nil if it was stopped by Stop and ctx.Err() if ctx is done.
+func (d *Dev) RxLoop(ctx context.Context, fetch func() (int, error)) error {
+	// Stop replaces the transport, the loop ends with the one it was started on
+	d.mu.Lock()
+	com, stopped := d.com, d.stopped
+	d.mu.Unlock()
+
+	return rxLoop(ctx, com, stopped, fetch)
+}
+
+// rxLoop runs RxLoop on the transport com until stopped is closed
+func rxLoop(ctx context.Context, com commands.Transport, stopped <-chan struct{}, fetch func() (int, error)) error {
+	for {
+		// a transport given by WithTransport stays open after Stop
+		if isClosed(stopped) {
+			return nil
+		}
+
+		n, err := fetch()
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := waitForData(ctx, com); err != nil {
+			if errors.Is(err, commands.ErrStopped) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// isClosed returns whether the channel ch is closed
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitForData blocks until the concentrator signals pending data, or the poll interval expired if it cannot signal it
+func waitForData(ctx context.Context, com commands.Transport) error {
+	if waiter, ok := com.(commands.Waiter); ok {
+		_, err := waiter.WaitForData(ctx, rxIRQTimeout)
+		return err
+	}
+
+	timer := time.NewTimer(commands.PollInterval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Frequency offset resolution of the LoRa demodulators in Hz, depending on the bandwidth
+const (
+	freqOffsetLSB125kHz = 0.11920929  // 125kHz * 2^-20
+	freqOffsetLSB250kHz = 0.238418579 // 250kHz * 2^-20
+	freqOffsetLSB500kHz = 0.476837158 // 500kHz * 2^-20
+)
+
+// fskTimestampCorrection returns the delay in microseconds between the end of a FSK packet and its timestamp
+func fskTimestampCorrection(datarate model.DataRate) uint32 {
+	return 680000/uint32(datarate) - 20
+}
+
+// loraTimestampCorrection returns the delay in microseconds between the end of a LoRa packet and its timestamp, as
+// the timestamp correction of lgw_receive of the reference HAL. It is the base delay of the modem, the service modem
+// or the multi-SF modems, plus the time the demodulator takes to decode the header and the last block of the
+// payload, which depends on the spreading factor, the bandwidth, the coding rate and the number of coded nibbles.
+func loraTimestampCorrection(bandwidth model.Bandwith, sf uint8, cr uint8, crcEn bool, size int, service bool) uint32 {
+	var delayX, bwPow int
+	switch bandwidth {
+	case model.Bw125kHz:
+		delayX, bwPow = 64, 1
+	case model.Bw250kHz:
+		delayX, bwPow = 32, 2
+	case model.Bw500kHz:
+		delayX, bwPow = 16, 4
+	default:
+		return 0
+	}
+
+	// the multi-SF modems demodulate 125kHz channels only
+	if !service {
+		delayX = 114
+	}
+
+	if sf < 5 || sf > 12 {
+		return 0
+	}
+
+	// low datarate optimisation
+	var ppm int
+	if (bandwidth == model.Bw125kHz && sf >= 11) || (bandwidth == model.Bw250kHz && sf == 12) {
+		ppm = 1
+	}
+
+	var crc int
+	if crcEn {
+		crc = 1
+	}
+
+	s := int(sf)
+	nibbles := 2 * (size + 2*crc)
+
+	var delayY, delayZ int
+	if nibbles-(s-7) <= 0 {
+		// the payload fits entirely in the first 8 symbols
+		delayY = ((1<<(s-1))*(s+1) + 3*(1<<(s-4))) / bwPow
+		delayZ = 32 * (nibbles + 5) / bwPow
+	} else {
+		delayY = ((1<<(s-1))*(s+1) + (4-ppm)*(1<<(s-4))) / bwPow
+		delayZ = (16 + 4*int(cr)) * (((nibbles - s + 6) % (s - 2*ppm)) + 1) / bwPow
+	}
+
+	return uint32(delayX + delayY + delayZ)
+}
+
+// Receive returns up to max received packets. It blocks until at least one packet was received, ctx is done or the
+// concentrator is stopped. Packets beyond max are kept for the next call.
+func (d *Dev) Receive(ctx context.Context, max int) ([]model.PktRx, error) {
+	if max < 1 {
+		return nil, fmt.Errorf("invalid maximum number of packets %d", max)
+	}
+
+	d.mu.Lock()
+	started, com, regs, stopped := d.context.IsStarted, d.com, d.regs, d.stopped
+	d.mu.Unlock()
+
+	if !started {
+		return nil, errors.New("concentrator is not started")
+	}
+
+	d.rxMu.Lock()
+	defer d.rxMu.Unlock()
+
+	for len(d.rxPending) == 0 {
+		if isClosed(stopped) {
+			return nil, errors.New("concentrator was stopped")
+		}
+
+		if err := d.fetchRx(com, regs); err != nil {
+			return nil, err
+		}
+
+		if len(d.rxPending) > 0 {
+			break
+		}
+
+		if err := waitForData(ctx, com); err != nil {
+			if errors.Is(err, commands.ErrStopped) {
+				return nil, errors.New("concentrator was stopped")
+			}
+			return nil, err
+		}
+	}
+
+	n := min(max, len(d.rxPending))
+	packets := append([]model.PktRx(nil), d.rxPending[:n]...)
+	d.rxPending = d.rxPending[n:]
+
+	return packets, nil
+}
+
+// fetchRx reads the RX buffer and appends the packets it holds to rxPending
+func (d *Dev) fetchRx(com commands.Transport, regs *commands.Registers) error {
+	d.regMu.Lock()
+	defer d.regMu.Unlock()
+
+	size, err := rxBufferSize(regs)
+	if err != nil {
+		return fmt.Errorf("failed to read the rx buffer size: %w", err)
+	}
+
+	if size == 0 {
+		return nil
+	}
+
+	// the packets in the buffer were latched before the current counter value
+	counter, err := readCounter(regs)
+	if err != nil {
+		return fmt.Errorf("failed to read the timestamp counter: %w", err)
+	}
+	d.counter.update(counter)
+
+	// fine timestamps are relative to the PPS latched last
+	var pps uint32
+	ftime := d.fineTimestampEnabled()
+	if ftime {
+		if pps, err = readCounterRegs(regs, ppsCounterRegs); err != nil {
+			return fmt.Errorf("failed to read the pps counter: %w", err)
+		}
+	}
+
+	buf := make([]byte, size)
+	if err := com.DevReadFifo(model.SpiMuxTargetSX1302, commands.RxBufferAddr, buf); err != nil {
+		return fmt.Errorf("failed to read the rx buffer: %w", err)
+	}
+
+	packets, skipped := parseRxBuffer(buf)
+	if skipped > 0 {
+		log.WithFields(log.Fields{
+			"skipped": skipped,
+			"size":    size,
+		}).Warn("Skipped corrupted bytes of the rx buffer")
+	}
+
+	for _, p := range packets {
+		pkt, err := d.pktRx(p)
+		if err != nil {
+			log.WithError(err).Warn("Dropped received packet")
+			continue
+		}
+
+		if ftime {
+			pkt.Ftime, pkt.FtimeReceived = d.fineTimestamp(p, pkt, pps)
+		}
+
+		d.rxPending = append(d.rxPending, pkt)
+	}
+
+	return nil
+}
+
+// rxBufferSize returns the number of bytes in the RX buffer
+func rxBufferSize(regs *commands.Registers) (int, error) {
+	ids := []commands.RegID{
+		commands.RegRxTopRxBufferNbBytesMsbRxBufferNbBytes,
+		commands.RegRxTopRxBufferNbBytesLsbRxBufferNbBytes,
+	}
+
+	// the two bytes are not latched together: read until the size is stable
+	var last int
+	for attempt := 0; attempt < rxBufferSizeReads; attempt++ {
+		values, err := regs.RegReadBatch(ids)
+		if err != nil {
+			return 0, err
+		}
+
+		size := int(values[0])<<8 | int(values[1])
+		if attempt > 0 && size >= last {
+			return min(size, commands.RxBufferSize), nil
+		}
+		last = size
+	}
+
+	return 0, fmt.Errorf("rx buffer size did not settle after %d reads, last read %d bytes", rxBufferSizeReads, last)
+}
+
+// pktRx fills the packet metadata of p the way lgw_receive of the reference HAL does
+func (d *Dev) pktRx(p rxPacket) (model.PktRx, error) {
+	if int(p.channel) >= model.MaxIFChains || int(p.channel) >= len(d.context.IfChainCfg) {
+		return model.PktRx{}, fmt.Errorf("packet received on the invalid if chain %d", p.channel)
+	}
+
+	ifChain := d.context.IfChainCfg[p.channel]
+	if int(ifChain.RFChain) >= len(d.context.RfChainCfg) {
+		return model.PktRx{}, fmt.Errorf("packet received on the unconfigured if chain %d", p.channel)
+	}
+	rfChain := d.context.RfChainCfg[ifChain.RFChain]
+
+	pkt := model.PktRx{
+		FreqHz:  uint32(int64(rfChain.FreqHz) + int64(ifChain.FreqHz)),
+		IfChain: p.channel,
+		RfChain: ifChain.RFChain,
+		ModemID: p.modemID,
+		Rssic:   float32(p.rssiChanAvg) + rfChain.RssiOffset,
+		Rssis:   float32(p.rssiSigAvg) + rfChain.RssiOffset,
+		Size:    uint16(len(p.payload)),
+	}
+	copy(pkt.Payload[:], p.payload)
+
+	// correction is the delay between the end of the packet and its timestamp
+	var correction uint32
+	switch uint8(p.channel) {
+	case ifChainFSK:
+		pkt.Modulation = model.ModFsk
+		pkt.Status = crcStatus(p.crcEn, p.crcError)
+		pkt.Bandwidth = uint8(d.context.FSKCfg.Bandwidth)
+		pkt.Datarate = uint32(d.context.FSKCfg.Datarate)
+		correction = fskTimestampCorrection(d.context.FSKCfg.Datarate)
+
+	default:
+		pkt.Modulation = model.ModLora
+		pkt.Bandwidth = uint8(model.Bw125kHz)
+		crcEn := p.crcEn
+		if uint8(p.channel) == ifChainLoRaService {
+			pkt.Bandwidth = uint8(d.context.LoraServiceCfg.Bandwidth)
+			crcEn = crcEn || d.context.LoraServiceCfg.ImplicitCrcEn
+		}
+
+		pkt.Status = crcStatus(crcEn, p.crcError)
+		pkt.Datarate = uint32(p.datarate)
+		pkt.Snr = float32(p.snrAvg) / 4
+
+		switch p.codingRate {
+		case model.CrLora45, model.CrLora46, model.CrLora47, model.CrLora48:
+			pkt.Coderate = p.codingRate
+		default:
+			pkt.Coderate = model.CrUndefined
+		}
+
+		switch model.Bandwith(pkt.Bandwidth) {
+		case model.Bw125kHz:
+			pkt.FreqOffset = int32(float64(p.freqOffsetError) * freqOffsetLSB125kHz)
+		case model.Bw250kHz:
+			pkt.FreqOffset = int32(float64(p.freqOffsetError) * freqOffsetLSB250kHz)
+		case model.Bw500kHz:
+			pkt.FreqOffset = int32(float64(p.freqOffsetError) * freqOffsetLSB500kHz)
+		}
+
+		correction = loraTimestampCorrection(model.Bandwith(pkt.Bandwidth), p.datarate, p.codingRate, crcEn, len(p.payload),
+			uint8(p.channel) == ifChainLoRaService)
+
+		// revert the rounding of the IF frequency to the resolution of the channelizer registers
+		msb, lsb := ifFreqToReg(ifChain.FreqHz)
+		pkt.FreqOffset += ifChain.FreqHz - ifRegToFreq(msb, lsb)
+	}
+
+	if pkt.Status == model.StatCrcOk {
+		pkt.Crc = p.crc
+	}
+
+	// the correction does not reach before the start of the counter
+	countUs := d.counter.expandUs(p.timestamp)
+	pkt.CountUs64 = countUs - min(uint64(correction), countUs)
+	pkt.CountUs = uint32(pkt.CountUs64)
+
+	return pkt, nil
+}
+
+// crcStatus returns the status of a packet with the given CRC settings
+func crcStatus(crcEn bool, crcError bool) uint8 {
+	switch {
+	case !crcEn:
+		return model.StatNoCrc
+	case crcError:
+		return model.StatCrcBad
+	}
+
+	return model.StatCrcOk
+}
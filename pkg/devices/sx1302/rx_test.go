@@ -0,0 +1,225 @@
+package sx1302_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// rxBuffer returns a RX buffer holding a LoRa packet with fine timestamp metrics followed by a FSK packet
+func rxBuffer() []byte {
+	lora := sx1302test.RxPacket{
+		Channel:         2,
+		CrcEn:           true,
+		CodingRate:      1,
+		Datarate:        7,
+		FreqOffsetError: -1234,
+		Payload:         []byte{0x40, 0x11, 0x22, 0x33, 0x44, 0x80, 0x01, 0x00, 0x01, 0xA6, 0x94, 0x2C, 0x5E},
+		TimingSet:       true,
+		SnrAvg:          38,
+		RssiChanAvg:     91,
+		RssiSigAvg:      93,
+		Timestamp:       0x01C9C380,
+		Crc:             0x8A17,
+		TsMetrics:       []int8{12, -3, 7, 0, -25, 14},
+	}
+
+	fsk := sx1302test.RxPacket{
+		Channel:     9,
+		CrcEn:       true,
+		Payload:     []byte{0xDE, 0xAD, 0xBE, 0xEF},
+		RssiChanAvg: 70,
+		RssiSigAvg:  70,
+		Timestamp:   0x01C9D000,
+	}
+
+	return append(lora.Encode(), fsk.Encode()...)
+}
+
+func FuzzParseRxBuffer(f *testing.F) {
+	buf := rxBuffer()
+	f.Add(buf)
+	f.Add(buf[:len(buf)-3])
+	f.Add(append([]byte{0xA5, 0xC0, 0xFF, 0x00}, buf...))
+
+	corrupted := bytes.Clone(buf)
+	corrupted[20] ^= 0x40
+	f.Add(corrupted)
+
+	f.Fuzz(func(t *testing.T, buf []byte) {
+		payloads, parsed, skipped := sx1302.ParseRxBuffer(buf)
+
+		// every byte belongs to a packet or was skipped
+		if parsed+skipped != len(buf) {
+			t.Fatalf("parsed %d and skipped %d bytes of a %d byte buffer", parsed, skipped, len(buf))
+		}
+
+		for _, payload := range payloads {
+			if len(payload) > 255 {
+				t.Fatalf("parsed a payload of %d bytes", len(payload))
+			}
+		}
+	})
+}
+
+func TestParseRxBufferResynchronises(t *testing.T) {
+	buf := rxBuffer()
+
+	payloads, _, skipped := sx1302.ParseRxBuffer(buf)
+	if len(payloads) != 2 || skipped != 0 {
+		t.Fatalf("ParseRxBuffer() = %d packets, %d skipped bytes, want 2 packets", len(payloads), skipped)
+	}
+
+	// a corrupted first packet is skipped up to the sync word of the second
+	corrupted := bytes.Clone(buf)
+	corrupted[20] ^= 0x40
+
+	payloads, _, skipped = sx1302.ParseRxBuffer(corrupted)
+	if len(payloads) != 1 || !bytes.Equal(payloads[0], []byte{0xDE, 0xAD, 0xBE, 0xEF}) {
+		t.Errorf("ParseRxBuffer() of a corrupted buffer = %X, want the fsk payload", payloads)
+	}
+
+	if want := len(buf) - (9 + 4 + 14); skipped != want {
+		t.Errorf("ParseRxBuffer() skipped %d bytes, want %d", skipped, want)
+	}
+}
+
+func TestReceiveReadsBufferAsFifo(t *testing.T) {
+	d, sim := newSimDev(t, sx1302.WithIfChainConfig(0, &model.RxIf{Enable: true, FreqHz: -200000}))
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop()
+
+	// more than one SPI transaction worth of packets, the buffer is read in several chunks
+	const count = 12
+	var injected int
+	for i := 0; i < count; i++ {
+		payload := bytes.Repeat([]byte{byte(i)}, 200)
+		pkt := sx1302test.RxPacket{Datarate: 7, Payload: payload, Timestamp: uint32(i)}.Encode()
+		injected += len(pkt)
+		sim.InjectRx(pkt)
+	}
+
+	if injected <= 1024 {
+		t.Fatalf("injected %d bytes, want more than a single transaction", injected)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var packets []model.PktRx
+	for len(packets) < count {
+		got, err := d.Receive(ctx, count)
+		if err != nil {
+			t.Fatalf("Receive() after %d packets failed: %v", len(packets), err)
+		}
+
+		packets = append(packets, got...)
+	}
+
+	if len(packets) != count {
+		t.Fatalf("Receive() = %d packets, want %d", len(packets), count)
+	}
+
+	for i, pkt := range packets {
+		want := bytes.Repeat([]byte{byte(i)}, 200)
+		if !bytes.Equal(pkt.Payload[:pkt.Size], want) {
+			t.Errorf("packet %d payload = %X, want %X", i, pkt.Payload[:pkt.Size], want)
+		}
+	}
+}
+
+func TestLoRaTimestampCorrection(t *testing.T) {
+	// values of the timestamp correction of the reference HAL
+	tests := []struct {
+		name      string
+		bandwidth model.Bandwith
+		sf        uint8
+		cr        uint8
+		crcEn     bool
+		size      int
+		service   bool
+		want      uint32
+	}{
+		{"multi-SF SF7", model.Bw125kHz, 7, model.CrLora45, true, 13, false, 698},
+		{"multi-SF SF11 low datarate", model.Bw125kHz, 11, model.CrLora46, true, 20, false, 12882},
+		{"service SF12 250kHz low datarate", model.Bw250kHz, 12, model.CrLora48, false, 10, true, 13808},
+		{"service SF5 500kHz", model.Bw500kHz, 5, model.CrLora45, false, 1, true, 62},
+		{"payload within the first symbols", model.Bw500kHz, 9, model.CrLora45, false, 0, true, 720},
+		{"undefined bandwidth", model.Bandwith(0), 7, model.CrLora45, true, 13, true, 0},
+		{"invalid spreading factor", model.Bw125kHz, 4, model.CrLora45, true, 13, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sx1302.LoRaTimestampCorrection(tt.bandwidth, tt.sf, tt.cr, tt.crcEn, tt.size, tt.service); got != tt.want {
+				t.Errorf("LoRaTimestampCorrection() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// unstableSize is a transport reporting a RX buffer size shrinking at every read
+type unstableSize struct {
+	commands.Transport
+	addr    uint16
+	size    atomic.Int32
+	enabled atomic.Bool
+}
+
+func (u *unstableSize) DevReadBurst(muxTarget uint8, addr uint16, data []byte) error {
+	if err := u.Transport.DevReadBurst(muxTarget, addr, data); err != nil {
+		return err
+	}
+
+	if u.enabled.Load() && addr <= u.addr && int(u.addr-addr)+1 < len(data) {
+		size := u.size.Add(-1)
+		data[u.addr-addr], data[u.addr-addr+1] = byte(size>>8), byte(size)
+	}
+
+	return nil
+}
+
+func TestReceiveUnstableBufferSize(t *testing.T) {
+	sim := sx1302test.NewSim()
+	sim.OnRadio = (&sx1302test.SX1250{}).Handle
+
+	ll, err := commands.NewLowLevelSPI(sim, commands.Pins{Reset: sim.ResetPin()})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	msb, _ := commands.RegRxTopRxBufferNbBytesMsbRxBufferNbBytes.Register()
+	com := &unstableSize{Transport: ll, addr: msb.Addr}
+	com.size.Store(1000)
+
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+
+	d := sx1302.NewSX1302Device(
+		sx1302.WithBoardConfig(model.NewBoardConfig()),
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithTransport(com),
+		sx1302.WithFirmware(testFirmware()),
+	)
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop()
+
+	com.enabled.Store(true)
+	_, err = d.Receive(context.Background(), 1)
+	if err == nil || !strings.Contains(err.Error(), "did not settle") {
+		t.Errorf("Receive() with a shrinking buffer size = %v, want an error", err)
+	}
+}
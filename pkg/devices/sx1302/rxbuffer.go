@@ -0,0 +1,139 @@
+package sx1302
+
+import (
+	"encoding/binary"
+)
+
+// Layout of a packet in the RX buffer: a head of rxPktHeadSize bytes starting with the sync word, the payload and a
+// tail of rxPktTailSize bytes followed by the timestamp metrics and a checksum byte
+const (
+	rxPktSyncWord0 byte = 0xA5
+	rxPktSyncWord1 byte = 0xC0
+
+	rxPktHeadSize = 9
+	rxPktTailSize = 14
+
+	// offsets of the head fields from the start of the packet
+	rxPktPayloadLength = 2
+	rxPktChannel       = 3
+	rxPktRateCrc       = 4
+	rxPktModemID       = 5
+	rxPktFreqOffset    = 6
+
+	// offsets of the tail fields from the end of the payload
+	rxPktStatus       = 9
+	rxPktSnrAvg       = 10
+	rxPktRssiChan     = 11
+	rxPktRssiSig      = 12
+	rxPktTimestamp    = 15
+	rxPktCrc          = 19
+	rxPktNumTsMetrics = 21
+	rxPktTsMetrics    = 22
+)
+
+// rxPacket is a packet of the RX buffer, decoded field by field
+type rxPacket struct {
+	channel         uint8
+	crcEn           bool
+	codingRate      uint8
+	datarate        uint8
+	modemID         uint8
+	freqOffsetError int32
+	payload         []byte
+
+	crcError    bool
+	syncError   bool
+	headerError bool
+	timingSet   bool
+
+	snrAvg      int8
+	rssiChanAvg uint8
+	rssiSigAvg  uint8
+	timestamp   uint32
+	crc         uint16
+	tsMetrics   []int8
+}
+
+// parseRxBuffer splits the content of the RX buffer into packets. Bytes not belonging to a packet with a valid sync
+// word, length and checksum are skipped up to the next sync word, skipped returns their number.
+func parseRxBuffer(buf []byte) (packets []rxPacket, skipped int) {
+	for i := 0; i < len(buf); {
+		pkt, size, ok := parseRxPacket(buf[i:])
+		if !ok {
+			// resynchronise on the next sync word
+			next := i + 1
+			for next < len(buf) && buf[next] != rxPktSyncWord0 {
+				next++
+			}
+			skipped += next - i
+			i = next
+			continue
+		}
+
+		packets = append(packets, pkt)
+		i += size
+	}
+
+	return packets, skipped
+}
+
+// parseRxPacket decodes the packet at the start of buf and returns its size in the buffer
+func parseRxPacket(buf []byte) (rxPacket, int, bool) {
+	if len(buf) < rxPktHeadSize || buf[0] != rxPktSyncWord0 || buf[1] != rxPktSyncWord1 {
+		return rxPacket{}, 0, false
+	}
+
+	payloadLength := int(buf[rxPktPayloadLength])
+	if len(buf) < rxPktHeadSize+payloadLength+rxPktTailSize {
+		return rxPacket{}, 0, false
+	}
+
+	tail := buf[payloadLength:]
+	numTsMetrics := int(tail[rxPktNumTsMetrics])
+	size := rxPktHeadSize + payloadLength + rxPktTailSize + 2*numTsMetrics
+	if len(buf) < size {
+		return rxPacket{}, 0, false
+	}
+
+	// the last byte is the sum of all bytes of the packet before it
+	var checksum byte
+	for _, b := range buf[:size-1] {
+		checksum += b
+	}
+	if checksum != buf[size-1] {
+		return rxPacket{}, 0, false
+	}
+
+	freqOffset := int32(buf[rxPktFreqOffset]) | int32(buf[rxPktFreqOffset+1])<<8 | int32(buf[rxPktFreqOffset+2]&0x0F)<<16
+	if freqOffset >= 1<<19 {
+		freqOffset -= 1 << 20
+	}
+
+	pkt := rxPacket{
+		channel:         buf[rxPktChannel],
+		crcEn:           buf[rxPktRateCrc]&0x01 != 0,
+		codingRate:      buf[rxPktRateCrc] >> 1 & 0x07,
+		datarate:        buf[rxPktRateCrc] >> 4,
+		modemID:         buf[rxPktModemID],
+		freqOffsetError: freqOffset,
+		payload:         append([]byte(nil), buf[rxPktHeadSize:rxPktHeadSize+payloadLength]...),
+
+		crcError:    tail[rxPktStatus]&0x01 != 0,
+		syncError:   tail[rxPktStatus]&0x04 != 0,
+		headerError: tail[rxPktStatus]&0x08 != 0,
+		timingSet:   tail[rxPktStatus]&0x10 != 0,
+
+		snrAvg:      int8(tail[rxPktSnrAvg]),
+		rssiChanAvg: tail[rxPktRssiChan],
+		rssiSigAvg:  tail[rxPktRssiSig],
+		timestamp:   binary.LittleEndian.Uint32(tail[rxPktTimestamp:]),
+		crc:         binary.LittleEndian.Uint16(tail[rxPktCrc:]),
+		tsMetrics:   make([]int8, 2*numTsMetrics),
+	}
+
+	for i := range pkt.tsMetrics {
+		pkt.tsMetrics[i] = int8(tail[rxPktTsMetrics+i])
+	}
+
+	return pkt, size, true
+}
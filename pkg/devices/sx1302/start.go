@@ -0,0 +1,508 @@
+package sx1302
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1250"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx125x"
+)
+
+const (
+	// chipVersion is the value of COMMON_VERSION of the supported SX1302 and SX1303 revisions
+	chipVersion int32 = 0x10
+
+	// ifChainLoRaService and ifChainFSK are the IF chains of the LoRa service and FSK demodulators, the IF chains
+	// below ifChainLoRaService are the multi-SF LoRa channels
+	ifChainLoRaService uint8 = 8
+	ifChainFSK         uint8 = 9
+
+	// rfRxBandwidth is the bandwidth around the radio center frequency in which IF chains can be placed
+	rfRxBandwidth uint32 = 1600000
+
+	// fskSampleRate is the sample rate of the FSK demodulator, its bit rate register holds fskSampleRate/datarate
+	fskSampleRate uint32 = 32000000
+
+	// radioResetTime is the time the radio is held in reset, radioStartupTime the time it needs to come up
+	radioResetTime   = 500 * time.Millisecond
+	radioStartupTime = 10 * time.Millisecond
+)
+
+// Radio front-end settings of the reference HAL
+const (
+	feRssiBbFilterAlpha  int32 = 0x03
+	feRssiDecFilterAlpha int32 = 0x07
+	feRssiDbDefault      int32 = 23
+	feRssiDecDefault     int32 = 66
+	feHostFilterGain     int32 = 0x0B
+)
+
+// FSK demodulator settings of the reference HAL
+const (
+	fskDcfreeWhitening int32 = 0x02
+	fskPktModeVariable int32 = 0x01
+	fskPktLenMax       int32 = 0xFF
+	fskErrorOsrTol     int32 = 10
+)
+
+// Positions of the two peaks of the LoRa sync word, for public (LoRaWAN) and private networks
+const (
+	syncWordPeak1Public  int32 = 6
+	syncWordPeak2Public  int32 = 8
+	syncWordPeak1Private int32 = 2
+	syncWordPeak2Private int32 = 4
+)
+
+// rfChainRegs are the registers setting up the radio of a RF chain
+var rfChainRegs = [model.MaxRfChains]struct {
+	radioEn, radioRst, sx1261Mode, clkSel            commands.RegID
+	dcNotchEn, hostFilterGain, rssiDbDef, rssiDecDef commands.RegID
+	rssiBbFilterAlpha, rssiDecFilterAlpha            commands.RegID
+	dcOffsetI, dcOffsetQ                             commands.RegID
+}{
+	{
+		radioEn:            commands.RegAgcMcuRfEnARadioEn,
+		radioRst:           commands.RegAgcMcuRfEnARadioRst,
+		sx1261Mode:         commands.RegCommonCtrl0Sx1261ModeRadioA,
+		clkSel:             commands.RegClkCtrlClkSelClkRadioASel,
+		dcNotchEn:          commands.RegRadioFeCtrl0RadioADcNotchEn,
+		hostFilterGain:     commands.RegRadioFeCtrl0RadioAHostFilterGain,
+		rssiDbDef:          commands.RegRadioFeRssiDbDefRadioARssiDbDefaultValue,
+		rssiDecDef:         commands.RegRadioFeRssiDecDefRadioARssiDecDefaultValue,
+		rssiBbFilterAlpha:  commands.RegRadioFeRssiBbFilterAlphaRadioARssiBbFilterAlpha,
+		rssiDecFilterAlpha: commands.RegRadioFeRssiDecFilterAlphaRadioARssiDecFilterAlpha,
+		dcOffsetI:          commands.RegRadioFeDcOffsetIRadioADcOffsetI,
+		dcOffsetQ:          commands.RegRadioFeDcOffsetQRadioADcOffsetQ,
+	},
+	{
+		radioEn:            commands.RegAgcMcuRfEnBRadioEn,
+		radioRst:           commands.RegAgcMcuRfEnBRadioRst,
+		sx1261Mode:         commands.RegCommonCtrl0Sx1261ModeRadioB,
+		clkSel:             commands.RegClkCtrlClkSelClkRadioBSel,
+		dcNotchEn:          commands.RegRadioFeCtrl0RadioBDcNotchEn,
+		hostFilterGain:     commands.RegRadioFeCtrl0RadioBHostFilterGain,
+		rssiDbDef:          commands.RegRadioFeRssiDbDefRadioBRssiDbDefaultValue,
+		rssiDecDef:         commands.RegRadioFeRssiDecDefRadioBRssiDecDefaultValue,
+		rssiBbFilterAlpha:  commands.RegRadioFeRssiBbFilterAlphaRadioBRssiBbFilterAlpha,
+		rssiDecFilterAlpha: commands.RegRadioFeRssiDecFilterAlphaRadioBRssiDecFilterAlpha,
+		dcOffsetI:          commands.RegRadioFeDcOffsetIRadioBDcOffsetI,
+		dcOffsetQ:          commands.RegRadioFeDcOffsetQRadioBDcOffsetQ,
+	},
+}
+
+// regWrite is a register write of a configuration sequence
+type regWrite struct {
+	id    commands.RegID
+	value int32
+}
+
+// writeRegs writes the registers of seq in order
+func (d *Dev) writeRegs(seq []regWrite) error {
+	for _, w := range seq {
+		if err := d.regs.RegWrite(w.id, w.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkConfig validates the configuration before the concentrator is touched
+func (d *Dev) checkConfig() error {
+	board := d.context.BoardConfig
+	if int(board.ClkSrc) >= len(d.context.RfChainCfg) || !d.context.RfChainCfg[board.ClkSrc].Enable {
+		return fmt.Errorf("rf chain %d clocks the concentrator (clksrc) but is not enabled, enable it with WithRfRxConfig", board.ClkSrc)
+	}
+
+	for i, rf := range d.context.RfChainCfg {
+		if !rf.Enable {
+			continue
+		}
+
+		switch rf.Type {
+		case model.RadioTypeSX1250, model.RadioTypeSX1255, model.RadioTypeSX1257:
+		default:
+			return fmt.Errorf("rf chain %d: radio type %s is not supported, use SX1250, SX1255 or SX1257", i, rf.Type)
+		}
+	}
+
+	for i, ifChain := range d.context.IfChainCfg {
+		if !ifChain.Enable {
+			continue
+		}
+
+		if int(ifChain.RFChain) >= len(d.context.RfChainCfg) || !d.context.RfChainCfg[ifChain.RFChain].Enable {
+			return fmt.Errorf("if chain %d is attached to the disabled rf chain %d", i, ifChain.RFChain)
+		}
+
+		bandwidth := ifChain.Bandwidth.Hz()
+		if bandwidth == 0 {
+			// the multi-SF channels always demodulate 125kHz
+			bandwidth = model.Bw125kHz.Hz()
+		}
+
+		if abs(ifChain.FreqHz)+int64(bandwidth/2) > int64(rfRxBandwidth/2) {
+			return fmt.Errorf("if chain %d: frequency offset %dHz with a bandwidth of %dHz exceeds the %dHz bandwidth of rf chain %d",
+				i, ifChain.FreqHz, bandwidth, rfRxBandwidth, ifChain.RFChain)
+		}
+	}
+
+	if err := d.checkTxGainLUTs(); err != nil {
+		return err
+	}
+
+	if sx1261 := d.context.SX1261Cfg; sx1261 != nil && sx1261.LbtConf.Enable {
+		if !sx1261.Enable {
+			return errors.New("lbt is enabled but the sx1261 radio is disabled, enable it in the sx1261 configuration")
+		}
+
+		if len(d.sx1261Patch.Words) == 0 {
+			return errors.New("lbt needs the firmware patch of the sx1261 radio, load it with WithSX1261")
+		}
+	}
+
+	agc, err := d.firmware.AGC(d.context.RfChainCfg[board.ClkSrc].Type)
+	if err != nil {
+		return err
+	}
+
+	if len(agc.Image) == 0 || len(d.firmware.ARB.Image) == 0 {
+		// without WithFirmware the firmware built into the binary is used
+		if d.firmware, err = commands.EmbeddedFirmware(); err != nil {
+			return fmt.Errorf("no mcu firmware configured, embed it or load it with WithFirmware: %w", err)
+		}
+	}
+
+	if d.needsCalibration() && len(d.firmware.Cal.Image) == 0 {
+		return errors.New("no calibration firmware configured, it is needed to calibrate SX1255 and SX1257 radios, load it with WithFirmware")
+	}
+
+	return nil
+}
+
+// checkVersion verifies that the concentrator answers with the version of a supported chip
+func (d *Dev) checkVersion() error {
+	// the reset put the page register back to 0
+	d.regs = commands.NewRegisters(d.com)
+
+	version, err := d.regs.RegRead(commands.RegCommonVersionVersion)
+	if err != nil {
+		return fmt.Errorf("failed to read the chip version: %w", err)
+	}
+
+	if version != chipVersion {
+		return fmt.Errorf("unsupported chip version 0x%02X, expected 0x%02X: check the SPI wiring and that the concentrator is powered and out of reset",
+			version, chipVersion)
+	}
+
+	return nil
+}
+
+// setupRadios resets every enabled radio, selects the clock source of the concentrator and sets the radios up
+func (d *Dev) setupRadios() error {
+	for i, rf := range d.context.RfChainCfg {
+		if !rf.Enable {
+			continue
+		}
+
+		if err := d.resetRadio(uint8(i), rf.Type); err != nil {
+			return fmt.Errorf("failed to reset the radio of rf chain %d: %w", i, err)
+		}
+	}
+
+	clkSrc := d.context.BoardConfig.ClkSrc
+	if err := d.selectClock(clkSrc); err != nil {
+		return fmt.Errorf("failed to select rf chain %d as clock source: %w", clkSrc, err)
+	}
+
+	for i, rf := range d.context.RfChainCfg {
+		if !rf.Enable {
+			continue
+		}
+
+		if err := d.setupRadio(uint8(i), rf); err != nil {
+			return fmt.Errorf("failed to set up the %s radio of rf chain %d, check the radio type of the rf chain: %w", rf.Type, i, err)
+		}
+	}
+
+	// hand the radio control over to the AGC
+	if err := d.regs.RegWrite(commands.RegCommonCtrl0HostRadioCtrl, 0); err != nil {
+		return fmt.Errorf("failed to release the radio control: %w", err)
+	}
+
+	return nil
+}
+
+// resetRadio powers the radio of rfChain up, pulses its reset and configures the radio interface for radioType
+func (d *Dev) resetRadio(rfChain uint8, radioType model.RadioType) error {
+	regs := rfChainRegs[rfChain]
+
+	// switch the SX1302 to the SPI clock while the radio clock is unavailable
+	if err := d.writeRegs([]regWrite{
+		{commands.RegCommonCtrl0Clk32RifCtrl, 0},
+		{regs.radioEn, 1},
+		{regs.radioRst, 1},
+	}); err != nil {
+		return err
+	}
+	time.Sleep(radioResetTime)
+
+	if err := d.regs.RegWrite(regs.radioRst, 0); err != nil {
+		return err
+	}
+	time.Sleep(radioStartupTime)
+
+	// the SX1250 is connected through the same interface as the SX1261
+	var sx1261Mode int32
+	if radioType == model.RadioTypeSX1250 {
+		sx1261Mode = 1
+	}
+
+	return d.regs.RegWrite(regs.sx1261Mode, sx1261Mode)
+}
+
+// selectClock clocks the SX1302 from the radio of rfChain
+func (d *Dev) selectClock(rfChain uint8) error {
+	seq := make([]regWrite, 0, model.MaxRfChains+2)
+	for i, regs := range rfChainRegs {
+		var sel int32
+		if uint8(i) == rfChain {
+			sel = 1
+		}
+		seq = append(seq, regWrite{regs.clkSel, sel})
+	}
+
+	return d.writeRegs(append(seq,
+		regWrite{commands.RegClkCtrlClkSelClkdivEn, 1},
+		regWrite{commands.RegCommonCtrl0Clk32RifCtrl, 1},
+	))
+}
+
+// setupRadio tunes the radio of rfChain to the center frequency of rf and starts RX
+func (d *Dev) setupRadio(rfChain uint8, rf model.RxRf) error {
+	switch rf.Type {
+	case model.RadioTypeSX1250:
+		radio, err := sx1250.New(d.com, rfChain)
+		if err != nil {
+			return err
+		}
+
+		return radio.Setup(rf.FreqHz, rf.SingleInputMode)
+
+	case model.RadioTypeSX1255, model.RadioTypeSX1257:
+		radio, err := sx125x.New(d.com, rfChain, rf.Type)
+		if err != nil {
+			return err
+		}
+
+		return radio.Setup(rfChain == d.context.BoardConfig.ClkSrc, true, rf.FreqHz)
+	}
+
+	return fmt.Errorf("unsupported radio type %s", rf.Type)
+}
+
+// configureRadioFrontEnd configures the DC notch filters and RSSI estimation of both radio interfaces
+func (d *Dev) configureRadioFrontEnd() error {
+	seq := make([]regWrite, 0, 6*model.MaxRfChains)
+	for _, regs := range rfChainRegs {
+		seq = append(seq,
+			regWrite{regs.rssiBbFilterAlpha, feRssiBbFilterAlpha},
+			regWrite{regs.rssiDecFilterAlpha, feRssiDecFilterAlpha},
+			regWrite{regs.rssiDbDef, feRssiDbDefault},
+			regWrite{regs.rssiDecDef, feRssiDecDefault},
+			regWrite{regs.dcNotchEn, 1},
+			regWrite{regs.hostFilterGain, feHostFilterGain},
+		)
+	}
+
+	return d.writeRegs(seq)
+}
+
+// configureChannelizer sets the radio and IF frequency of every IF chain and enables the correlators of the
+// enabled multi-SF channels
+func (d *Dev) configureChannelizer() error {
+	var radioSelect, channels int32
+	seq := make([]regWrite, 0, 2*model.MaxIFChains+6)
+	for i, ifChain := range d.context.IfChainCfg {
+		if !ifChain.Enable {
+			continue
+		}
+
+		msb, lsb := ifFreqToReg(ifChain.FreqHz)
+		switch uint8(i) {
+		case ifChainLoRaService:
+			seq = append(seq,
+				regWrite{commands.RegRxTopLoraServiceFskRadioSelectLoraService, int32(ifChain.RFChain)},
+				regWrite{commands.RegRxTopLoraServiceFskFreqMsbLoraService, msb},
+				regWrite{commands.RegRxTopLoraServiceFskFreqLsbLoraService, lsb},
+			)
+
+		case ifChainFSK:
+			seq = append(seq,
+				regWrite{commands.RegRxTopLoraServiceFskRadioSelectFsk, int32(ifChain.RFChain)},
+				regWrite{commands.RegRxTopLoraServiceFskFreqMsbFsk, msb},
+				regWrite{commands.RegRxTopLoraServiceFskFreqLsbFsk, lsb},
+			)
+
+		default:
+			// the FREQ_i registers of the multi-SF channels are interleaved MSB, LSB
+			offset := commands.RegID(2 * i)
+			seq = append(seq,
+				regWrite{commands.RegRxTopFreq0MsbIfFreq0 + offset, msb},
+				regWrite{commands.RegRxTopFreq0LsbIfFreq0 + offset, lsb},
+			)
+			radioSelect |= int32(ifChain.RFChain) << i
+			channels |= 1 << i
+		}
+	}
+
+	seq = append(seq,
+		regWrite{commands.RegRxTopRadioSelectRadioSelect, radioSelect},
+		regWrite{commands.RegRxTopCorrClockEnableClkEn, channels},
+		regWrite{commands.RegRxTopCorrelatorEnCorrEn, channels},
+		regWrite{commands.RegRxTopCorrelatorSfEnCorrSfEn, int32(d.context.DemodCfg.MultisfDatarate)},
+	)
+
+	return d.writeRegs(seq)
+}
+
+// configureDemodulators configures the LoRa service and FSK demodulators, the LoRa sync word and enables the
+// demodulators of the enabled IF chains
+func (d *Dev) configureDemodulators() error {
+	loraService := d.ifChainEnabled(ifChainLoRaService)
+	fsk := d.ifChainEnabled(ifChainFSK)
+
+	var multiSF bool
+	for i := uint8(0); i < ifChainLoRaService; i++ {
+		multiSF = multiSF || d.ifChainEnabled(i)
+	}
+
+	if loraService {
+		if err := d.configureLoRaService(d.context.LoraServiceCfg); err != nil {
+			return fmt.Errorf("lora service channel: %w", err)
+		}
+	}
+
+	if fsk {
+		if err := d.configureFSK(d.context.FSKCfg); err != nil {
+			return fmt.Errorf("fsk channel: %w", err)
+		}
+	}
+
+	peak1, peak2 := syncWordPeak1Private, syncWordPeak2Private
+	if d.context.BoardConfig.LoRaWanPublic {
+		peak1, peak2 = syncWordPeak1Public, syncWordPeak2Public
+	}
+
+	if err := d.writeRegs([]regWrite{
+		{commands.RegRxTopFrameSynch0Sf5Peak1PosSf5, peak1},
+		{commands.RegRxTopFrameSynch1Sf5Peak2PosSf5, peak2},
+		{commands.RegRxTopFrameSynch0Sf6Peak1PosSf6, peak1},
+		{commands.RegRxTopFrameSynch1Sf6Peak2PosSf6, peak2},
+		{commands.RegRxTopFrameSynch0Sf7to12Peak1PosSf7to12, peak1},
+		{commands.RegRxTopFrameSynch1Sf7to12Peak2PosSf7to12, peak2},
+		{commands.RegRxTopLoraServiceFskFrameSynch0Peak1Pos, peak1},
+		{commands.RegRxTopLoraServiceFskFrameSynch1Peak2Pos, peak2},
+	}); err != nil {
+		return fmt.Errorf("sync word: %w", err)
+	}
+
+	return d.writeRegs([]regWrite{
+		{commands.RegCommonGenConcentratorModemEnable, boolToReg(multiSF)},
+		{commands.RegCommonGenMbwssfModemEnable, boolToReg(loraService)},
+		{commands.RegCommonGenFskModemEnable, boolToReg(fsk)},
+		{commands.RegCommonGenGlobalEn, 1},
+	})
+}
+
+// configureLoRaService configures the single-SF LoRa demodulator of the LoRa service channel
+func (d *Dev) configureLoRaService(conf *model.RxIf) error {
+	return d.writeRegs([]regWrite{
+		{commands.RegRxTopLoraServiceCfg0RateSf, int32(conf.Datarate)},
+		{commands.RegRxTopLoraServiceCfg0ModemBw, int32(conf.Bandwidth)},
+		{commands.RegRxTopLoraServiceCfg1ImplicitHeader, boolToReg(conf.ImplicitHdr)},
+		{commands.RegRxTopLoraServiceCfg1CrcEn, boolToReg(conf.ImplicitCrcEn)},
+		{commands.RegRxTopLoraServiceCfg1CodingRate, int32(conf.ImplicitCoderate)},
+		{commands.RegRxTopLoraServiceCfg2PayloadLength, int32(conf.ImplicitPayloadLength)},
+		{commands.RegRxTopLoraServiceCfg3ModemEn, 1},
+	})
+}
+
+// configureFSK configures the FSK demodulator
+func (d *Dev) configureFSK(conf *model.RxIf) error {
+	if conf.Datarate < model.DrFskMin || conf.Datarate > model.DrFskMax {
+		return fmt.Errorf("invalid datarate %s, must be between %s and %s", conf.Datarate, model.DrFskMin, model.DrFskMax)
+	}
+
+	if conf.SyncWordSize < 1 || conf.SyncWordSize > 8 {
+		return fmt.Errorf("invalid sync word size %d, must be between 1 and 8 bytes", conf.SyncWordSize)
+	}
+
+	bitRate := fskSampleRate / uint32(conf.Datarate)
+	seq := []regWrite{
+		{commands.RegRxTopFskCfg0Bw, int32(conf.Bandwidth)},
+		{commands.RegRxTopFskCfg0Psize, int32(conf.SyncWordSize - 1)},
+		{commands.RegRxTopFskCfg0CrcEn, 1},
+		{commands.RegRxTopFskCfg0CrcIbm, 0},
+		{commands.RegRxTopFskCfg1DcfreeEnc, fskDcfreeWhitening},
+		{commands.RegRxTopFskCfg1PktMode, fskPktModeVariable},
+		{commands.RegRxTopFskCfg1AdrsComp, 0},
+		{commands.RegRxTopFskBitRateMsbBitRate, int32(bitRate >> 8 & 0xFF)},
+		{commands.RegRxTopFskBitRateLsbBitRate, int32(bitRate & 0xFF)},
+		{commands.RegRxTopFskPktLenPktLen, fskPktLenMax},
+		{commands.RegRxTopFskAutoAfcOnAutoAfcOn, 1},
+		{commands.RegRxTopFskErrorOsrTolErrorOsrTol, fskErrorOsrTol},
+	}
+
+	// the sync word is aligned left in the 8 byte reference pattern
+	pattern := conf.SyncWord << (8 * (8 - uint64(conf.SyncWordSize)))
+	for i := commands.RegID(0); i < 8; i++ {
+		seq = append(seq, regWrite{commands.RegRxTopFskRefPatternByte0FskRefPattern + i, int32(pattern >> (8 * i) & 0xFF)})
+	}
+
+	return d.writeRegs(seq)
+}
+
+// startTimestampCounter starts the timestamp counter and latches it on the rising edge of the GPS PPS
+func (d *Dev) startTimestampCounter() error {
+	return d.writeRegs([]regWrite{
+		{commands.RegTimestampGpsCtrlGpsPol, 1},
+		{commands.RegTimestampGpsCtrlGpsEn, 1},
+		{commands.RegTimestampTimestampCtrlEnable, 1},
+	})
+}
+
+// ifChainEnabled returns whether the IF chain ifChain is configured and enabled
+func (d *Dev) ifChainEnabled(ifChain uint8) bool {
+	return int(ifChain) < len(d.context.IfChainCfg) && d.context.IfChainCfg[ifChain].Enable
+}
+
+// ifFreqToReg converts the IF frequency freqHz to the MSB and LSB of the channelizer frequency registers
+func ifFreqToReg(freqHz int32) (int32, int32) {
+	freq := int32(int64(freqHz) << 5 / 15625)
+	return freq >> 8, freq & 0xFF
+}
+
+// ifRegToFreq converts the MSB and LSB of the channelizer frequency registers to the IF frequency in Hz
+func ifRegToFreq(msb, lsb int32) int32 {
+	return int32(int64(msb<<8|lsb) * 15625 / 32)
+}
+
+func boolToReg(b bool) int32 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func abs(v int32) int64 {
+	if v < 0 {
+		return -int64(v)
+	}
+
+	return int64(v)
+}
@@ -0,0 +1,158 @@
+package sx1302
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1250"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx125x"
+)
+
+// txDrainTimeout bounds the wait for a packet being emitted when stopping, the longest LoRa packet takes ~9s
+const txDrainTimeout = 10 * time.Second
+
+// Stop waits for packets being emitted, halts the AGC and ARB MCUs, puts the radios to sleep and releases the
+// transport, its GPIO pins and the SPI ports it opened. Received packets not yet returned are dropped. A stopped
+// concentrator is started again with Start.
+//
+// Stop does nothing if the concentrator is not started. It is safe for concurrent use with Start, e.g. from a
+// goroutine handling signals. The concentrator is released even if a step fails, the errors of all failed steps
+// are returned.
+func (d *Dev) Stop() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.context.IsStarted {
+		return nil
+	}
+
+	close(d.stopped)
+	d.counterDone.Wait()
+
+	err := d.halt()
+	d.context.IsStarted = false
+
+	// Receive holds rxMu until the transport is closed or it sees stopped, it takes regMu after rxMu
+	d.rxMu.Lock()
+	d.rxPending = nil
+	d.rxMu.Unlock()
+
+	return err
+}
+
+// halt runs the steps of Stop accessing the concentrator
+func (d *Dev) halt() error {
+	d.regMu.Lock()
+	defer d.regMu.Unlock()
+
+	var errs []error
+	if err := d.drainTx(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if d.sx1261 != nil {
+		if err := d.sx1261.Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop the sx1261 radio: %w", err))
+		}
+	}
+
+	if err := d.haltMCUs(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to halt the mcus: %w", err))
+	}
+
+	if err := d.sleepRadios(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := d.disconnect(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// drainTx waits until no RF chain is emitting a packet. Scheduled packets are dropped by halting the MCUs
+func (d *Dev) drainTx() error {
+	deadline := time.Now().Add(txDrainTimeout)
+	for i, rf := range d.context.RfChainCfg {
+		if !rf.Enable || !rf.TxEnable {
+			continue
+		}
+
+		for {
+			status, err := d.regs.RegRead(txFsmStatusRegs[i])
+			if err != nil {
+				return fmt.Errorf("failed to read the tx status of rf chain %d: %w", i, err)
+			}
+
+			if txStatus(status) != model.TxStatusEmitting {
+				break
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf("rf chain %d is still emitting after %s", i, txDrainTimeout)
+			}
+			time.Sleep(txPollInterval)
+		}
+	}
+
+	return nil
+}
+
+// haltMCUs holds the AGC and ARB MCUs in reset and stops latching the timestamp counter on the PPS
+func (d *Dev) haltMCUs() error {
+	return d.writeRegs([]regWrite{
+		{commands.RegAgcMcuCtrlMcuClear, 1},
+		{commands.RegArbMcuCtrlMcuClear, 1},
+		{commands.RegTimestampGpsCtrlGpsEn, 0},
+	})
+}
+
+// sleepRadios takes the radio control back from the halted AGC, puts every enabled radio to sleep and disables it
+func (d *Dev) sleepRadios() error {
+	if err := d.regs.RegWrite(commands.RegCommonCtrl0HostRadioCtrl, 1); err != nil {
+		return fmt.Errorf("failed to take the radio control: %w", err)
+	}
+
+	for i, rf := range d.context.RfChainCfg {
+		if !rf.Enable {
+			continue
+		}
+
+		if err := d.sleepRadio(uint8(i), rf.Type); err != nil {
+			return fmt.Errorf("failed to put the radio of rf chain %d to sleep: %w", i, err)
+		}
+
+		if err := d.regs.RegWrite(rfChainRegs[i].radioEn, 0); err != nil {
+			return fmt.Errorf("failed to disable the radio of rf chain %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// sleepRadio puts the radio of rfChain to sleep
+func (d *Dev) sleepRadio(rfChain uint8, radioType model.RadioType) error {
+	switch radioType {
+	case model.RadioTypeSX1250:
+		radio, err := sx1250.New(d.com, rfChain)
+		if err != nil {
+			return err
+		}
+
+		return radio.Sleep()
+
+	case model.RadioTypeSX1255, model.RadioTypeSX1257:
+		radio, err := sx125x.New(d.com, rfChain, radioType)
+		if err != nil {
+			return err
+		}
+
+		return radio.Sleep()
+	}
+
+	return fmt.Errorf("unsupported radio type %s", radioType)
+}
@@ -0,0 +1,169 @@
+package sx1302_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// closeCounter is a transport counting the calls of Close
+type closeCounter struct {
+	commands.Transport
+	closed int
+}
+
+func (c *closeCounter) Close() error {
+	c.closed++
+	return c.Transport.Close()
+}
+
+func TestRestartWithSPIPort(t *testing.T) {
+	d, sim := newSimDev(t)
+
+	for i := 0; i < 2; i++ {
+		sim.ClearWrites()
+		if err := d.Start(); err != nil {
+			t.Fatalf("Start() %d failed: %v", i+1, err)
+		}
+
+		// every start runs on the port of WithSPIPort instead of opening BoardConf.ComPath
+		if len(sim.Writes()) == 0 {
+			t.Errorf("Start() %d did not reach the simulator", i+1)
+		}
+
+		if err := d.Stop(); err != nil {
+			t.Fatalf("Stop() %d failed: %v", i+1, err)
+		}
+	}
+}
+
+func TestRestartWithDialer(t *testing.T) {
+	sim := sx1302test.NewSim()
+	sim.OnRadio = (&sx1302test.SX1250{}).Handle
+
+	var dialed []*closeCounter
+	dial := commands.SPIDialer(sim, commands.Pins{Reset: sim.ResetPin()})
+
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+
+	d := sx1302.NewSX1302Device(
+		sx1302.WithBoardConfig(model.NewBoardConfig()),
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithDialer(func(board *model.BoardConf) (commands.Transport, error) {
+			com, err := dial(board)
+			if err != nil {
+				return nil, err
+			}
+
+			dialed = append(dialed, &closeCounter{Transport: com})
+			return dialed[len(dialed)-1], nil
+		}),
+		sx1302.WithFirmware(testFirmware()),
+	)
+
+	for i := 0; i < 2; i++ {
+		if err := d.Start(); err != nil {
+			t.Fatalf("Start() %d failed: %v", i+1, err)
+		}
+
+		if err := d.Stop(); err != nil {
+			t.Fatalf("Stop() %d failed: %v", i+1, err)
+		}
+	}
+
+	// every start dials its own transport, which is closed by the following stop
+	if len(dialed) != 2 {
+		t.Fatalf("Start() dialed %d transports, want 2", len(dialed))
+	}
+
+	for i, com := range dialed {
+		if com.closed != 1 {
+			t.Errorf("Stop() closed transport %d %d times, want once", i+1, com.closed)
+		}
+	}
+}
+
+func TestFailedStartKeepsTransport(t *testing.T) {
+	sim := sx1302test.NewSim()
+	sim.OnRadio = (&sx1302test.SX1250{}).Handle
+
+	ll, err := commands.NewLowLevelSPI(sim, commands.Pins{Reset: sim.ResetPin()})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+	com := &closeCounter{Transport: ll}
+
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+
+	d := sx1302.NewSX1302Device(
+		sx1302.WithBoardConfig(model.NewBoardConfig()),
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithTransport(com),
+		sx1302.WithFirmware(testFirmware()),
+	)
+
+	// the AGC reports a firmware version the driver does not expect
+	sim.AGCVersion = commands.AGCFirmwareVersionSX1250 + 1
+	if err := d.Start(); err == nil {
+		t.Fatal("Start() with a wrong agc firmware version succeeded")
+	}
+
+	if com.closed != 0 {
+		t.Errorf("failed Start() closed the transport of WithTransport %d times", com.closed)
+	}
+
+	sim.AGCVersion = commands.AGCFirmwareVersionSX1250
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() after a failed Start() failed: %v", err)
+	}
+
+	if err := d.Stop(); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+
+	if com.closed != 0 {
+		t.Errorf("Stop() closed the transport of WithTransport %d times", com.closed)
+	}
+}
+
+func TestStopDropsPendingPackets(t *testing.T) {
+	d, sim := newSimDev(t, sx1302.WithIfChainConfig(0, &model.RxIf{Enable: true, FreqHz: -200000}))
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		sim.InjectRx(sx1302test.RxPacket{Datarate: 7, Payload: []byte{byte(i)}, Timestamp: uint32(i)}.Encode())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	packets, err := d.Receive(ctx, 1)
+	if err != nil || len(packets) != 1 {
+		t.Fatalf("Receive() = %d packets, %v, want 1 packet", len(packets), err)
+	}
+
+	if err := d.Stop(); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() after Stop() failed: %v", err)
+	}
+	defer d.Stop()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if packets, err := d.Receive(ctx, 1); err == nil {
+		t.Errorf("Receive() after a restart = %v, want the packet received before Stop() dropped", packets)
+	}
+}
@@ -0,0 +1,313 @@
+package sx1250
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// Opcode is a command of the SX1250
+type Opcode uint8
+
+// Commands of the SX1250
+const (
+	OpcodeCalibrate               Opcode = 0x89
+	OpcodeCalibrateImage          Opcode = 0x98
+	OpcodeClrIrqStatus            Opcode = 0x02
+	OpcodeGetDeviceErrors         Opcode = 0x17
+	OpcodeGetIrqStatus            Opcode = 0x12
+	OpcodeGetPacketStatus         Opcode = 0x14
+	OpcodeGetRxBufferStatus       Opcode = 0x13
+	OpcodeGetStatus               Opcode = 0xC0
+	OpcodeReadBuffer              Opcode = 0x1E
+	OpcodeReadRegister            Opcode = 0x1D
+	OpcodeSetBufferBaseAddress    Opcode = 0x8F
+	OpcodeSetDioIrqParams         Opcode = 0x08
+	OpcodeSetFs                   Opcode = 0xC1
+	OpcodeSetModulationParams     Opcode = 0x8B
+	OpcodeSetPaConfig             Opcode = 0x95
+	OpcodeSetPacketParams         Opcode = 0x8C
+	OpcodeSetPacketType           Opcode = 0x8A
+	OpcodeSetRegulatorMode        Opcode = 0x96
+	OpcodeSetRfFrequency          Opcode = 0x86
+	OpcodeSetRfSwitchMode         Opcode = 0x9D
+	OpcodeSetRx                   Opcode = 0x82
+	OpcodeSetSleep                Opcode = 0x84
+	OpcodeSetStandby              Opcode = 0x80
+	OpcodeSetTx                   Opcode = 0x83
+	OpcodeSetTxContinuousWave     Opcode = 0xD1
+	OpcodeSetTxContinuousPreamble Opcode = 0xD2
+	OpcodeSetTxParams             Opcode = 0x8E
+	OpcodeStopTimerOnPreamble     Opcode = 0x9F
+	OpcodeWriteBuffer             Opcode = 0x0E
+	OpcodeWriteRegister           Opcode = 0x0D
+)
+
+// StandbyMode selects the clock running while the SX1250 is in standby
+type StandbyMode uint8
+
+const (
+	// StandbyRC runs the radio from its RC oscillator
+	StandbyRC StandbyMode = 0x00
+
+	// StandbyXOSC runs the radio from the crystal oscillator
+	StandbyXOSC StandbyMode = 0x01
+)
+
+// ChipMode is the operating mode reported in the status byte of the SX1250
+type ChipMode uint8
+
+// Operating modes of the SX1250
+const (
+	ChipModeStandbyRC   ChipMode = 0x02
+	ChipModeStandbyXOSC ChipMode = 0x03
+	ChipModeFS          ChipMode = 0x04
+	ChipModeRX          ChipMode = 0x05
+	ChipModeTX          ChipMode = 0x06
+)
+
+func (m ChipMode) String() string {
+	switch m {
+	case ChipModeStandbyRC:
+		return "STDBY_RC"
+	case ChipModeStandbyXOSC:
+		return "STDBY_XOSC"
+	case ChipModeFS:
+		return "FS"
+	case ChipModeRX:
+		return "RX"
+	case ChipModeTX:
+		return "TX"
+	}
+
+	return fmt.Sprintf("unknown (0x%X)", uint8(m))
+}
+
+const (
+	// CalibrateAll runs the calibration of all blocks (RC64k, RC13M, PLL, ADC pulse, ADC bulk N/P and image)
+	CalibrateAll uint8 = 0x7F
+
+	// RxContinuous is the SetRx timeout keeping the radio in RX until told otherwise
+	RxContinuous uint32 = 0xFFFFFF
+
+	// xtalFreq is the frequency of the SX1250 crystal in Hz
+	xtalFreq uint64 = 32000000
+
+	// modeSettleTime is the time to wait after a mode change or a calibration
+	modeSettleTime = 10 * time.Millisecond
+)
+
+// SX1250 registers written during the setup
+const (
+	regBitrate       uint16 = 0x06A1
+	regDioOutEnable  uint16 = 0x0580
+	regDioDrive      uint16 = 0x0582
+	regDioInEnable   uint16 = 0x0583
+	regDioPullUp     uint16 = 0x0584
+	regDioPullDown   uint16 = 0x0585
+	regFpgaMode      uint16 = 0x0587
+	regFixGain       uint16 = 0x08B6
+	regFreqOffset    uint16 = 0x088F
+	regRxSingleInput uint16 = 0x08E2
+
+	// fpgaModeRx routes the radio I/Q samples to the SX1302
+	fpgaModeRx uint8 = 0x0B
+)
+
+// Radio is a SX1250 radio behind the SPI mux of a SX1302
+type Radio struct {
+	com       commands.Transport
+	rfChain   uint8
+	muxTarget uint8
+}
+
+// New creates the driver of the SX1250 connected to the RF chain rfChain of the SX1302 reachable through com
+func New(com commands.Transport, rfChain uint8) (*Radio, error) {
+	if rfChain >= model.MaxRfChains {
+		return nil, wrapf("invalid rf chain %d", rfChain)
+	}
+
+	return &Radio{
+		com:       com,
+		rfChain:   rfChain,
+		muxTarget: model.SpiMuxTargetRadioA + rfChain,
+	}, nil
+}
+
+// WriteCommand sends the command op with its parameters
+func (r *Radio) WriteCommand(op Opcode, params ...byte) error {
+	if err := r.com.DevTransfer(r.muxTarget, append([]byte{byte(op)}, params...), nil); err != nil {
+		return wrapf("radio %d: command 0x%02X failed: %v", r.rfChain, byte(op), err)
+	}
+
+	return nil
+}
+
+// ReadCommand sends the command op and fills buf with the bytes clocked out by the radio after the opcode.
+// buf holds the parameters of the command on entry.
+func (r *Radio) ReadCommand(op Opcode, buf []byte) error {
+	w := append([]byte{byte(op)}, buf...)
+	read := make([]byte, len(w))
+	if err := r.com.DevTransfer(r.muxTarget, w, read); err != nil {
+		return wrapf("radio %d: command 0x%02X failed: %v", r.rfChain, byte(op), err)
+	}
+
+	copy(buf, read[1:])
+	return nil
+}
+
+// WriteRegister writes data to the consecutive registers starting at address
+func (r *Radio) WriteRegister(address uint16, data ...byte) error {
+	return r.WriteCommand(OpcodeWriteRegister, append([]byte{byte(address >> 8), byte(address)}, data...)...)
+}
+
+// ReadRegister fills buf from the consecutive registers starting at address
+func (r *Radio) ReadRegister(address uint16, buf []byte) error {
+	// address followed by a NOP before the register data
+	tmp := make([]byte, 3+len(buf))
+	tmp[0], tmp[1] = byte(address>>8), byte(address)
+	if err := r.ReadCommand(OpcodeReadRegister, tmp); err != nil {
+		return err
+	}
+
+	copy(buf, tmp[3:])
+	return nil
+}
+
+// Status returns the operating mode of the radio
+func (r *Radio) Status() (ChipMode, error) {
+	buf := []byte{0x00}
+	if err := r.ReadCommand(OpcodeGetStatus, buf); err != nil {
+		return 0, err
+	}
+
+	return ChipMode(buf[0] >> 4 & 0x07), nil
+}
+
+// SetStandby puts the radio in standby, clocked as selected by mode, and verifies the mode was entered
+func (r *Radio) SetStandby(mode StandbyMode) error {
+	if err := r.WriteCommand(OpcodeSetStandby, byte(mode)); err != nil {
+		return err
+	}
+	time.Sleep(modeSettleTime)
+
+	expected := ChipModeStandbyRC
+	if mode == StandbyXOSC {
+		expected = ChipModeStandbyXOSC
+	}
+
+	chipMode, err := r.Status()
+	if err != nil {
+		return err
+	}
+
+	if chipMode != expected {
+		return wrapf("radio %d: failed to enter %s mode, radio is in %s mode", r.rfChain, expected, chipMode)
+	}
+
+	return nil
+}
+
+// Calibrate runs the calibration of the blocks selected by params, CalibrateAll for a full calibration
+func (r *Radio) Calibrate(params uint8) error {
+	if err := r.WriteCommand(OpcodeCalibrate, params); err != nil {
+		return err
+	}
+	time.Sleep(modeSettleTime)
+
+	return nil
+}
+
+// Sleep puts the radio in sleep mode with a cold start, it needs a new Setup to be used again
+func (r *Radio) Sleep() error {
+	return r.WriteCommand(OpcodeSetSleep, 0x00)
+}
+
+// SetRfFrequency programs the PLL to freqHz
+func (r *Radio) SetRfFrequency(freqHz uint32) error {
+	freq := FreqToReg(freqHz)
+	return r.WriteCommand(OpcodeSetRfFrequency, byte(freq>>24), byte(freq>>16), byte(freq>>8), byte(freq))
+}
+
+// SetRx puts the radio in RX mode for timeout periods of 15.625us, RxContinuous to stay in RX
+func (r *Radio) SetRx(timeout uint32) error {
+	return r.WriteCommand(OpcodeSetRx, byte(timeout>>16), byte(timeout>>8), byte(timeout))
+}
+
+// SetSingleInputMode configures the RX input of the radio as single ended instead of differential
+func (r *Radio) SetSingleInputMode() error {
+	return r.WriteRegister(regRxSingleInput, 0x0D)
+}
+
+// Setup calibrates the radio, tunes it to freqHz and starts RX so the radio clocks the SX1302
+func (r *Radio) Setup(freqHz uint32, singleInputMode bool) error {
+	if err := r.SetStandby(StandbyRC); err != nil {
+		return err
+	}
+
+	if err := r.Calibrate(CalibrateAll); err != nil {
+		return err
+	}
+
+	if err := r.SetStandby(StandbyXOSC); err != nil {
+		return err
+	}
+
+	// Set the bitrate to its maximum, lowering the TX to FS switch time
+	if err := r.writeRegisters(regBitrate, 0x01, 0x00, 0x00); err != nil {
+		return err
+	}
+
+	// Configure the DIOs for RX: minimum drive strength, inputs disabled, no pull-up/down, outputs enabled
+	for _, reg := range []uint16{regDioDrive, regDioInEnable, regDioPullUp, regDioPullDown, regDioOutEnable} {
+		if err := r.WriteRegister(reg, 0x00); err != nil {
+			return err
+		}
+	}
+
+	if err := r.WriteRegister(regFixGain, 0x2A); err != nil {
+		return err
+	}
+
+	if err := r.SetRfFrequency(freqHz); err != nil {
+		return err
+	}
+
+	if err := r.WriteRegister(regFreqOffset, 0x00, 0x00, 0x00); err != nil {
+		return err
+	}
+
+	if err := r.SetRx(RxContinuous); err != nil {
+		return err
+	}
+
+	if singleInputMode {
+		if err := r.SetSingleInputMode(); err != nil {
+			return err
+		}
+	}
+
+	return r.WriteRegister(regFpgaMode, fpgaModeRx)
+}
+
+// writeRegisters writes data to consecutive registers starting at address, one command per register
+func (r *Radio) writeRegisters(address uint16, data ...byte) error {
+	for i, b := range data {
+		if err := r.WriteRegister(address+uint16(i), b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FreqToReg converts freqHz to the value of the PLL frequency register
+func FreqToReg(freqHz uint32) uint32 {
+	return uint32(uint64(freqHz) * (1 << 25) / xtalFreq)
+}
+
+func wrapf(format string, a ...interface{}) error {
+	return fmt.Errorf("sx1250: "+format, a...)
+}
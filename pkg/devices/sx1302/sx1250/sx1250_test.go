@@ -0,0 +1,145 @@
+package sx1250_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1250"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// newRadio creates the driver of the SX1250 on rfChain of a simulator emulating SX1250 radios
+func newRadio(t *testing.T, rfChain uint8) (*sx1250.Radio, *sx1302test.Sim) {
+	t.Helper()
+
+	sim := sx1302test.NewSim()
+	sim.OnRadio = (&sx1302test.SX1250{}).Handle
+
+	ll, err := commands.NewLowLevelSPI(sim, commands.Pins{Reset: sim.ResetPin()})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	radio, err := sx1250.New(ll, rfChain)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	return radio, sim
+}
+
+// checkFrames compares the frames sent to the radio of rfChain, without the mux target, with want
+func checkFrames(t *testing.T, sim *sx1302test.Sim, rfChain int, want [][]byte) {
+	t.Helper()
+
+	got := sim.RadioFrames(rfChain)
+	if len(got) != len(want) {
+		t.Errorf("radio received %d frames, want %d", len(got), len(want))
+	}
+
+	for i := 0; i < min(len(got), len(want)); i++ {
+		if !bytes.Equal(got[i][1:], want[i]) {
+			t.Errorf("frame %d = % X, want % X", i, got[i][1:], want[i])
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	// sequence of the reference HAL for a radio tuned to 868.5MHz
+	setup := [][]byte{
+		{0x80, 0x00},
+		{0xC0, 0x00},
+		{0x89, 0x7F},
+		{0x80, 0x01},
+		{0xC0, 0x00},
+		{0x0D, 0x06, 0xA1, 0x01},
+		{0x0D, 0x06, 0xA2, 0x00},
+		{0x0D, 0x06, 0xA3, 0x00},
+		{0x0D, 0x05, 0x82, 0x00},
+		{0x0D, 0x05, 0x83, 0x00},
+		{0x0D, 0x05, 0x84, 0x00},
+		{0x0D, 0x05, 0x85, 0x00},
+		{0x0D, 0x05, 0x80, 0x00},
+		{0x0D, 0x08, 0xB6, 0x2A},
+		{0x86, 0x36, 0x48, 0x00, 0x00},
+		{0x0D, 0x08, 0x8F, 0x00, 0x00, 0x00},
+		{0x82, 0xFF, 0xFF, 0xFF},
+	}
+	fpgaModeRx := []byte{0x0D, 0x05, 0x87, 0x0B}
+	singleInput := []byte{0x0D, 0x08, 0xE2, 0x0D}
+
+	tests := []struct {
+		name        string
+		rfChain     uint8
+		singleInput bool
+		want        [][]byte
+	}{
+		{"differential", 0, false, append(append([][]byte{}, setup...), fpgaModeRx)},
+		{"single input", 1, true, append(append([][]byte{}, setup...), singleInput, fpgaModeRx)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			radio, sim := newRadio(t, tt.rfChain)
+			if err := radio.Setup(868500000, tt.singleInput); err != nil {
+				t.Fatalf("Setup() failed: %v", err)
+			}
+
+			checkFrames(t, sim, int(tt.rfChain), tt.want)
+			if other := sim.RadioFrames(1 - int(tt.rfChain)); len(other) != 0 {
+				t.Errorf("Setup() sent %d frames to the other radio", len(other))
+			}
+		})
+	}
+}
+
+func TestSetupStandbyFailure(t *testing.T) {
+	radio, sim := newRadio(t, 0)
+
+	// a radio answering with zeros never reports the standby mode
+	sim.OnRadio = nil
+	if err := radio.Setup(868500000, false); err == nil {
+		t.Fatal("Setup() succeeded with a radio not entering standby")
+	}
+
+	checkFrames(t, sim, 0, [][]byte{{0x80, 0x00}, {0xC0, 0x00}})
+}
+
+func TestCalibrate(t *testing.T) {
+	radio, sim := newRadio(t, 0)
+
+	if err := radio.Calibrate(sx1250.CalibrateAll); err != nil {
+		t.Fatalf("Calibrate() failed: %v", err)
+	}
+
+	if err := radio.SetStandby(sx1250.StandbyXOSC); err != nil {
+		t.Fatalf("SetStandby() failed: %v", err)
+	}
+
+	checkFrames(t, sim, 0, [][]byte{{0x89, 0x7F}, {0x80, 0x01}, {0xC0, 0x00}})
+}
+
+func TestFreqToReg(t *testing.T) {
+	tests := []struct {
+		freqHz uint32
+		want   uint32
+	}{
+		{32000000, 1 << 25},
+		{868500000, 0x36480000},
+		{915000000, 0x39300000},
+		{433175000, 0x1B12CCCC},
+	}
+
+	for _, tt := range tests {
+		if got := sx1250.FreqToReg(tt.freqHz); got != tt.want {
+			t.Errorf("FreqToReg(%d) = 0x%08X, want 0x%08X", tt.freqHz, got, tt.want)
+		}
+	}
+}
+
+func TestNewInvalidRfChain(t *testing.T) {
+	if _, err := sx1250.New(nil, 2); err == nil {
+		t.Error("New() accepted rf chain 2")
+	}
+}
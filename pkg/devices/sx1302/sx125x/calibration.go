@@ -0,0 +1,23 @@
+package sx125x
+
+import (
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// ApplyTxOffsets stores the TX offsets calibrated for the mixer gain of every entry of lut in OffsetI and OffsetQ.
+// The carrier leakage depends on the mixer gain, the calibration firmware measures it with the DAC gain
+// commands.CalTxDacGain used by all entries of the reference TX gain LUTs of the SX1255 and SX1257.
+func ApplyTxOffsets(lut *model.TxGainLUT, offsets [commands.CalMixGains]commands.IQOffset) error {
+	for i := range lut.LUT {
+		gain := &lut.LUT[i]
+		if int(gain.MixGain) >= len(offsets) {
+			return wrapf("tx gain lut entry %d: invalid mixer gain %d (max %d)", i, gain.MixGain, len(offsets)-1)
+		}
+
+		offset := offsets[gain.MixGain]
+		gain.OffsetI, gain.OffsetQ = offset.I, offset.Q
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package sx125x_test
+
+import (
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx125x"
+)
+
+func TestApplyTxOffsets(t *testing.T) {
+	var offsets [commands.CalMixGains]commands.IQOffset
+	for gain := range offsets {
+		offsets[gain] = commands.IQOffset{I: int8(gain), Q: -int8(gain)}
+	}
+
+	lut := &model.TxGainLUT{LUT: []model.TXGain{
+		{RfPower: 12, DacGain: 3, MixGain: 10, OffsetI: 99, OffsetQ: 99},
+		{RfPower: 14, DacGain: 3, MixGain: 10},
+		{RfPower: 16, DacGain: 3, MixGain: 14},
+		{RfPower: 27, PaGain: 3, DacGain: 3, MixGain: 15},
+	}}
+
+	if err := sx125x.ApplyTxOffsets(lut, offsets); err != nil {
+		t.Fatalf("ApplyTxOffsets() failed: %v", err)
+	}
+
+	// entries sharing a mixer gain share its offsets, whatever their PA gain
+	for i, gain := range lut.LUT {
+		if want := offsets[gain.MixGain]; gain.OffsetI != want.I || gain.OffsetQ != want.Q {
+			t.Errorf("entry %d offsets = (%d, %d), want (%d, %d)", i, gain.OffsetI, gain.OffsetQ, want.I, want.Q)
+		}
+	}
+}
+
+func TestApplyTxOffsetsInvalidMixGain(t *testing.T) {
+	lut := &model.TxGainLUT{LUT: []model.TXGain{{DacGain: 3, MixGain: commands.CalMixGains}}}
+
+	if err := sx125x.ApplyTxOffsets(lut, [commands.CalMixGains]commands.IQOffset{}); err == nil {
+		t.Error("ApplyTxOffsets() of a mixer gain out of range succeeded")
+	}
+}
@@ -0,0 +1,263 @@
+package sx125x
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// Registers of the SX1255 and SX1257
+const (
+	RegMode       uint8 = 0x00
+	RegFrfRxMsb   uint8 = 0x01
+	RegFrfRxMid   uint8 = 0x02
+	RegFrfRxLsb   uint8 = 0x03
+	RegFrfTxMsb   uint8 = 0x04
+	RegFrfTxMid   uint8 = 0x05
+	RegFrfTxLsb   uint8 = 0x06
+	RegVersion    uint8 = 0x07
+	RegTxGain     uint8 = 0x08
+	RegTxBw       uint8 = 0x0A
+	RegTxDacBw    uint8 = 0x0B
+	RegRxAnaGain  uint8 = 0x0C
+	RegRxBw       uint8 = 0x0D
+	RegRxPllBw    uint8 = 0x0E
+	RegClkSelect  uint8 = 0x10
+	RegModeStatus uint8 = 0x11
+	RegXoscSX1257 uint8 = 0x26
+	RegXoscSX1255 uint8 = 0x28
+)
+
+// Values of RegMode
+const (
+	ModeSleep   uint8 = 0x00
+	ModeStandby uint8 = 0x01
+	ModeRx      uint8 = 0x03
+	ModeTx      uint8 = 0x0D
+)
+
+// Default radio settings, see the SX1255/SX1257 datasheets
+const (
+	txDacClkSel   = 0  // 0:int, 1:ext
+	txDacGain     = 2  // 3:0, 2:-3, 1:-6, 0:-9 dBFS
+	txMixGain     = 14 // -38 + 2*TxMixGain dB
+	txPllBw       = 1  // 0:75, 1:150, 2:225, 3:300 kHz
+	txAnaBw       = 0  // 17.5 / 2*(41-TxAnaBw) MHz
+	txDacBw       = 5  // 24 + 8*TxDacBw Nb FIR taps
+	rxLnaGain     = 1  // 1 to 6, 1 highest gain
+	rxBbGain      = 15 // 0 to 15, 15 highest gain
+	lnaZin        = 0  // 0:50, 1:200 Ohms
+	rxAdcBw       = 7  // 0 to 7, 2:100<BW<200, 5:200<BW<400, 7:400<BW kHz SSB
+	rxAdcTrim     = 6  // 0 to 7, 6 for 32MHz ref, 5 for 36MHz ref
+	rxBbBw        = 0  // 0:750, 1:500, 2:375, 3:250 kHz SSB
+	rxPllBw       = 0  // 0:75, 1:150, 2:225, 3:300 kHz
+	adcTemp       = 0  // ADC temperature measurement mode
+	xoscGmStartup = 13
+	xoscDisable   = 2 // bit0:regulator, bit1:core(gm), bit2:amplifier
+
+	// clkOutEnable enables the clock output towards the SX1302 in RegClkSelect
+	clkOutEnable = 0x02
+
+	// pllLockMask is the RX PLL lock bit of RegModeStatus
+	pllLockMask = 0x02
+
+	// addressMask selects the 7 bit register address, the MSB is the R/W bit
+	addressMask uint8 = 0x7F
+
+	// writeAccess is the R/W bit value of a write access
+	writeAccess uint8 = 0x80
+)
+
+const (
+	// frac32MHz is the irreducible fraction of the 32MHz reference used for the PLL register computation
+	frac32MHz uint64 = 15625
+
+	// pllLockAttempts is the number of attempts to lock the PLL before giving up
+	pllLockAttempts = 5
+
+	// pllLockTime is the time to wait for the PLL to lock after each attempt
+	pllLockTime = time.Millisecond
+)
+
+// Radio is a SX1255 or SX1257 radio behind the SPI mux of a SX1302
+type Radio struct {
+	com       commands.Transport
+	rfChain   uint8
+	muxTarget uint8
+	radioType model.RadioType
+}
+
+// New creates the driver of the SX1255 or SX1257 connected to the RF chain rfChain of the SX1302 reachable through com
+func New(com commands.Transport, rfChain uint8, radioType model.RadioType) (*Radio, error) {
+	if rfChain >= model.MaxRfChains {
+		return nil, wrapf("invalid rf chain %d", rfChain)
+	}
+
+	if radioType != model.RadioTypeSX1255 && radioType != model.RadioTypeSX1257 {
+		return nil, wrapf("radio type %s is not a SX125x", radioType)
+	}
+
+	return &Radio{
+		com:       com,
+		rfChain:   rfChain,
+		muxTarget: model.SpiMuxTargetRadioA + rfChain,
+		radioType: radioType,
+	}, nil
+}
+
+// WriteReg writes value to the register at address
+func (r *Radio) WriteReg(address uint8, value uint8) error {
+	if err := r.com.DevTransfer(r.muxTarget, []byte{writeAccess | address&addressMask, value}, nil); err != nil {
+		return wrapf("radio %d: failed to write register 0x%02X: %v", r.rfChain, address, err)
+	}
+
+	return nil
+}
+
+// ReadReg reads the register at address
+func (r *Radio) ReadReg(address uint8) (uint8, error) {
+	read := make([]byte, 2)
+	if err := r.com.DevTransfer(r.muxTarget, []byte{address & addressMask, 0x00}, read); err != nil {
+		return 0, wrapf("radio %d: failed to read register 0x%02X: %v", r.rfChain, address, err)
+	}
+
+	return read[1], nil
+}
+
+// Version returns the silicon revision of the radio
+func (r *Radio) Version() (uint8, error) {
+	return r.ReadReg(RegVersion)
+}
+
+// Setup configures the radio. clkOut enables the clock output of the radio which clocks the SX1302, enable configures
+// the gains and starts RX at freqHz, otherwise the radio is kept in standby.
+func (r *Radio) Setup(clkOut bool, enable bool, freqHz uint32) error {
+	clkSelect := uint8(txDacClkSel)
+	if clkOut {
+		clkSelect |= clkOutEnable
+	}
+
+	if err := r.WriteReg(RegClkSelect, clkSelect); err != nil {
+		return err
+	}
+
+	xosc := RegXoscSX1257
+	if r.radioType == model.RadioTypeSX1255 {
+		xosc = RegXoscSX1255
+	}
+
+	if err := r.WriteReg(xosc, xoscGmStartup+xoscDisable*16); err != nil {
+		return err
+	}
+
+	if !enable {
+		return nil
+	}
+
+	settings := []struct {
+		reg   uint8
+		value uint8
+	}{
+		{RegTxGain, txMixGain + txDacGain*16},
+		{RegTxBw, txAnaBw + txPllBw*32},
+		{RegTxDacBw, txDacBw},
+		{RegRxAnaGain, lnaZin + rxBbGain*2 + rxLnaGain*32},
+		{RegRxBw, rxBbBw + rxAdcTrim*4 + rxAdcBw*32},
+		{RegRxPllBw, adcTemp + rxPllBw*2},
+	}
+
+	for _, s := range settings {
+		if err := r.WriteReg(s.reg, s.value); err != nil {
+			return err
+		}
+	}
+
+	if err := r.SetRxFrequency(freqHz); err != nil {
+		return err
+	}
+
+	return r.startRx()
+}
+
+// Sleep puts the radio in sleep mode, it needs a new Setup to be used again
+func (r *Radio) Sleep() error {
+	return r.WriteReg(RegMode, ModeSleep)
+}
+
+// SetRxFrequency programs the RX PLL to freqHz
+func (r *Radio) SetRxFrequency(freqHz uint32) error {
+	return r.writeFrequency(RegFrfRxMsb, freqHz)
+}
+
+// SetTxFrequency programs the TX PLL to freqHz
+func (r *Radio) SetTxFrequency(freqHz uint32) error {
+	return r.writeFrequency(RegFrfTxMsb, freqHz)
+}
+
+// SetTxGain programs the DAC and mixer gain of gain
+func (r *Radio) SetTxGain(gain model.TXGain) error {
+	if gain.DacGain > 3 || gain.MixGain > 15 {
+		return wrapf("radio %d: invalid tx gain, dac gain %d (max 3), mixer gain %d (max 15)", r.rfChain, gain.DacGain, gain.MixGain)
+	}
+
+	return r.WriteReg(RegTxGain, gain.MixGain+gain.DacGain*16)
+}
+
+// writeFrequency writes the PLL value of freqHz to the three registers starting at msb
+func (r *Radio) writeFrequency(msb uint8, freqHz uint32) error {
+	freq := PLLFreqToReg(r.radioType, freqHz)
+	for i := uint8(0); i < 3; i++ {
+		if err := r.WriteReg(msb+i, uint8(freq>>(16-8*i))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startRx puts the radio in RX mode and waits for the PLL to lock
+func (r *Radio) startRx() error {
+	for attempt := 0; attempt < pllLockAttempts; attempt++ {
+		if err := r.WriteReg(RegMode, ModeStandby); err != nil {
+			return err
+		}
+
+		if err := r.WriteReg(RegMode, ModeRx); err != nil {
+			return err
+		}
+
+		time.Sleep(pllLockTime)
+
+		status, err := r.ReadReg(RegModeStatus)
+		if err != nil {
+			return err
+		}
+
+		if status&pllLockMask != 0 {
+			return nil
+		}
+	}
+
+	return wrapf("radio %d: failed to lock the PLL after %d attempts", r.rfChain, pllLockAttempts)
+}
+
+// PLLFreqToReg converts freqHz to the 24 bit value of the PLL frequency registers of radioType
+func PLLFreqToReg(radioType model.RadioType, freqHz uint32) uint32 {
+	// the SX1255 PLL runs at half the step of the SX1257 PLL
+	shift := 8
+	if radioType == model.RadioTypeSX1255 {
+		shift = 7
+	}
+
+	step := frac32MHz << shift
+	partInt := uint64(freqHz) / step
+	partFrac := (uint64(freqHz) % step) << (16 - shift) / frac32MHz
+
+	return uint32(partInt<<16|partFrac) & 0xFFFFFF
+}
+
+func wrapf(format string, a ...interface{}) error {
+	return fmt.Errorf("sx125x: "+format, a...)
+}
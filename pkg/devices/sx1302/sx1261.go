@@ -0,0 +1,223 @@
+package sx1302
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"periph.io/x/conn/v3/spi"
+	"periph.io/x/conn/v3/spi/spireg"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1261"
+)
+
+// WithSX1261Config configures the SX1261 companion radio used for listen-before-talk and spectral scan
+func WithSX1261Config(conf *model.SX1261Conf) SX1302Config {
+	return func(d *Dev) {
+		if d.context.IsStarted {
+			log.Fatal("gateway is already running. Please stop it before changing configuration")
+		}
+
+		if conf.LbtConf.Enable {
+			if conf.LbtConf.NbChannel < 1 || int(conf.LbtConf.NbChannel) > model.LBTChannelCountMax {
+				log.Fatalf("invalid number of lbt channels %d, must be between 1 and %d", conf.LbtConf.NbChannel, model.LBTChannelCountMax)
+			}
+
+			if len(conf.LbtConf.Channels) < int(conf.LbtConf.NbChannel) {
+				log.Fatalf("%d lbt channels enabled but only %d configured", conf.LbtConf.NbChannel, len(conf.LbtConf.Channels))
+			}
+
+			for i, ch := range conf.LbtConf.Channels[:conf.LbtConf.NbChannel] {
+				if err := checkLBTChannel(ch); err != nil {
+					log.Fatalf("lbt channel %d: %v", i, err)
+				}
+			}
+		}
+
+		d.context.SX1261Cfg = conf
+
+		log.WithFields(log.Fields{
+			"enable":      conf.Enable,
+			"spi_path":    conf.SpiPath,
+			"rssi_offset": conf.RssiOffset,
+			"lbt_enable":  conf.LbtConf.Enable,
+			"lbt_nb_chan": conf.LbtConf.NbChannel,
+		}).Info("SX1261 configuration loaded")
+	}
+}
+
+// WithSX1261 attaches the SX1261 to the SPI port spiPort and loads the firmware patch providing listen-before-talk and
+// spectral scan. With a nil spiPort the SX1261 of a SPI board is opened on SX1261Conf.SpiPath, the one of a USB board
+// is reached through the SPI mux of the concentrator.
+func WithSX1261(spiPort spi.Port, patch sx1261.Patch) SX1302Config {
+	return func(d *Dev) {
+		if d.context.IsStarted {
+			log.Fatal("gateway is already running. Please stop it before changing configuration")
+		}
+
+		d.sx1261Port = spiPort
+		d.sx1261Patch = patch
+	}
+}
+
+// SpectralScanStart starts a spectral scan of nbScan RSSI samples on the 125kHz channel at freqHz
+func (d *Dev) SpectralScanStart(freqHz uint32, nbScan uint16) error {
+	radio, err := d.sx1261Radio()
+	if err != nil {
+		return err
+	}
+
+	if err := radio.SetRxParams(freqHz, model.Bw125kHz); err != nil {
+		return err
+	}
+
+	return radio.SpectralScanStart(nbScan)
+}
+
+// SpectralScanStatus returns the state of the last spectral scan
+func (d *Dev) SpectralScanStatus() (model.SpectralScanStatus, error) {
+	radio, err := d.sx1261Radio()
+	if err != nil {
+		return model.SpectralScanStatusNone, err
+	}
+
+	return radio.SpectralScanStatus()
+}
+
+// SpectralScanAbort stops an ongoing spectral scan
+func (d *Dev) SpectralScanAbort() error {
+	radio, err := d.sx1261Radio()
+	if err != nil {
+		return err
+	}
+
+	return radio.Stop()
+}
+
+// SpectralScanResults returns the RSSI histogram of the completed spectral scan, see sx1261.Radio.SpectralScanResults
+func (d *Dev) SpectralScanResults() ([sx1261.SpectralScanResultSize]int16, [sx1261.SpectralScanResultSize]uint16, error) {
+	radio, err := d.sx1261Radio()
+	if err != nil {
+		return [sx1261.SpectralScanResultSize]int16{}, [sx1261.SpectralScanResultSize]uint16{}, err
+	}
+
+	return radio.SpectralScanResults()
+}
+
+// sx1261Radio returns the SX1261, connecting it and loading its firmware patch on first use
+func (d *Dev) sx1261Radio() (*sx1261.Radio, error) {
+	if d.sx1261 != nil {
+		return d.sx1261, nil
+	}
+
+	conf := d.context.SX1261Cfg
+	if conf == nil || !conf.Enable {
+		return nil, errors.New("sx1261 radio is disabled")
+	}
+
+	var radio *sx1261.Radio
+	switch {
+	case d.sx1261Port != nil:
+		var err error
+		if radio, err = sx1261.NewSPI(d.sx1261Port, conf.RssiOffset); err != nil {
+			return nil, err
+		}
+
+	case d.context.BoardConfig.ComType == model.ComSPI:
+		port, err := spireg.Open(conf.SpiPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the spi port %s: %w", conf.SpiPath, err)
+		}
+		d.sx1261Owned = port
+
+		if radio, err = sx1261.NewSPI(port, conf.RssiOffset); err != nil {
+			return nil, err
+		}
+
+	case d.com != nil:
+		radio = sx1261.NewMux(d.com, conf.RssiOffset)
+
+	default:
+		return nil, errors.New("no transport configured for the sx1261 radio")
+	}
+
+	if len(d.sx1261Patch.Words) > 0 {
+		if err := radio.LoadPatch(d.sx1261Patch); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := radio.Setup(); err != nil {
+		return nil, err
+	}
+
+	d.sx1261 = radio
+	return radio, nil
+}
+
+// lbtEnabled returns whether listen-before-talk gates the TX of the concentrator
+func (d *Dev) lbtEnabled() bool {
+	conf := d.context.SX1261Cfg
+	return conf != nil && conf.Enable && conf.LbtConf.Enable
+}
+
+// checkLBTChannel validates the bandwidth and scan time of an LBT channel
+func checkLBTChannel(ch model.LBTChanConf) error {
+	if ch.FreqHz == 0 {
+		return errors.New("no frequency configured")
+	}
+
+	switch model.Bandwith(ch.Bandwidth) {
+	case model.Bw125kHz, model.Bw250kHz, model.Bw500kHz:
+	default:
+		return fmt.Errorf("invalid bandwidth 0x%02X", ch.Bandwidth)
+	}
+
+	switch ch.ScanTimeUs {
+	case model.ScanTime12Us, model.ScanTime5000Us:
+	default:
+		return fmt.Errorf("unsupported scan time %s", ch.ScanTimeUs)
+	}
+
+	return nil
+}
+
+// lbtChannel returns the LBT channel pkt is sent on: the channel at the frequency of pkt at least as wide as pkt
+func (d *Dev) lbtChannel(pkt model.PktTx) (model.LBTChanConf, error) {
+	lbt := d.context.SX1261Cfg.LbtConf
+	for _, ch := range lbt.Channels[:lbt.NbChannel] {
+		if ch.FreqHz == pkt.FreqHz && pkt.Bandwidth <= ch.Bandwidth {
+			return ch, nil
+		}
+	}
+
+	return model.LBTChanConf{}, fmt.Errorf("no lbt channel configured for %dHz with bandwidth 0x%02X", pkt.FreqHz, pkt.Bandwidth)
+}
+
+// lbtStart has the SX1261 sense the channel of pkt, following lgw_lbt_start of the reference HAL. The AGC only lets
+// the emission start while the SX1261 signals a free channel.
+func (d *Dev) lbtStart(pkt model.PktTx) error {
+	ch, err := d.lbtChannel(pkt)
+	if err != nil {
+		return err
+	}
+
+	radio, err := d.sx1261Radio()
+	if err != nil {
+		return err
+	}
+
+	if err := radio.SetRxParams(ch.FreqHz, model.Bandwith(ch.Bandwidth)); err != nil {
+		return err
+	}
+
+	if err := radio.LBTStart(ch.ScanTimeUs, d.context.SX1261Cfg.LbtConf.RssiTarget); err != nil {
+		return err
+	}
+
+	// the channel is free once it was sensed for the whole scan time
+	time.Sleep(time.Duration(ch.ScanTimeUs) * time.Microsecond)
+	return nil
+}
@@ -0,0 +1,406 @@
+package sx1261
+
+import (
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// Opcode is a command of the SX1261
+type Opcode uint8
+
+// Commands of the SX1261. OpcodeLBTStart, OpcodeSpectralScanStart and OpcodePatchUpdate are provided by the firmware
+// patch.
+const (
+	OpcodeCalibrate            Opcode = 0x89
+	OpcodeGetRssiInst          Opcode = 0x15
+	OpcodeGetStatus            Opcode = 0xC0
+	OpcodeReadRegister         Opcode = 0x1D
+	OpcodeSetBufferBaseAddress Opcode = 0x8F
+	OpcodeSetFs                Opcode = 0xC1
+	OpcodeSetModulationParams  Opcode = 0x8B
+	OpcodeSetPacketParams      Opcode = 0x8C
+	OpcodeSetPacketType        Opcode = 0x8A
+	OpcodeSetRfFrequency       Opcode = 0x86
+	OpcodeSetRx                Opcode = 0x82
+	OpcodeSetStandby           Opcode = 0x80
+	OpcodeWriteRegister        Opcode = 0x0D
+	OpcodeLBTStart             Opcode = 0x9A
+	OpcodeSpectralScanStart    Opcode = 0x9B
+	OpcodePatchUpdate          Opcode = 0xD9
+)
+
+// SX1261 registers
+const (
+	regPatchUpdateEnable uint16 = 0x0610
+	regPatchVersion      uint16 = 0x0153
+	regRssiAverage       uint16 = 0x089B
+	regSensiAdjust       uint16 = 0x08AC
+	regSpectralStatus    uint16 = 0x07CD
+	regSpectralResults   uint16 = 0x0401
+
+	// pramAddr is the address of the program RAM receiving the firmware patch
+	pramAddr uint16 = 0x8000
+)
+
+const (
+	// standbyRC is the parameter of SetStandby running the radio from its RC oscillator
+	standbyRC uint8 = 0x00
+
+	// chipModeStandbyRC is the chip mode reported in the status byte while in STDBY_RC
+	chipModeStandbyRC uint8 = 0x02
+
+	// packetTypeLoRa is the parameter of SetPacketType selecting LoRa
+	packetTypeLoRa uint8 = 0x01
+
+	// packetTypeGFSK is the parameter of SetPacketType selecting GFSK
+	packetTypeGFSK uint8 = 0x00
+
+	// xtalFreq is the frequency of the SX1261 crystal in Hz
+	xtalFreq uint64 = 32000000
+
+	// scanInterval is the interval between two RSSI samples in units of 8.2us minus one
+	scanInterval uint8 = 11
+
+	// lbtGpio is the DIO of the SX1261 signalling a free channel to the SX1302
+	lbtGpio uint8 = 1
+
+	// SpectralScanResultSize is the number of RSSI levels of the spectral scan histogram
+	SpectralScanResultSize = 33
+
+	// spectralScanLevelStep is the width in dB of a level of the spectral scan histogram
+	spectralScanLevelStep = 4
+
+	// modeSettleTime is the time to wait after a mode change
+	modeSettleTime = time.Millisecond
+
+	// rssiSettleTime is the time for the RSSI to be valid after entering RX
+	rssiSettleTime = 200 * time.Microsecond
+)
+
+// Bus is the SPI connection to the SX1261. spi.Conn implements it.
+type Bus interface {
+	Tx(w, r []byte) error
+}
+
+// Patch is the firmware patch of the SX1261 providing LBT and spectral scan, as published by Semtech with the HAL
+type Patch struct {
+	// Words is the content of the program RAM
+	Words []uint32
+
+	// Version is the version string reported by the radio once the patch is applied, e.g. "2D06"
+	Version string
+}
+
+// Radio is a SX1261 radio used for listen-before-talk and spectral scan
+type Radio struct {
+	bus        Bus
+	rssiOffset int8
+}
+
+// NewSPI creates the driver of the SX1261 attached to its own SPI port
+func NewSPI(spiPort spi.Port, rssiOffset int8) (*Radio, error) {
+	c, err := spiPort.Connect(2*physic.MegaHertz, spi.Mode0, 8)
+	if err != nil {
+		return nil, wrapf("failed to connect to spi port: %v", err)
+	}
+
+	return New(c, rssiOffset), nil
+}
+
+// NewMux creates the driver of the SX1261 reachable through the SPI mux of com, as on USB concentrators
+func NewMux(com commands.Transport, rssiOffset int8) *Radio {
+	return New(muxBus{com: com}, rssiOffset)
+}
+
+// New creates the driver of the SX1261 on bus. rssiOffset is added to all RSSI values read from the radio.
+func New(bus Bus, rssiOffset int8) *Radio {
+	return &Radio{
+		bus:        bus,
+		rssiOffset: rssiOffset,
+	}
+}
+
+// WriteCommand sends the command op with its parameters
+func (r *Radio) WriteCommand(op Opcode, params ...byte) error {
+	if err := r.bus.Tx(append([]byte{byte(op)}, params...), nil); err != nil {
+		return wrapf("command 0x%02X failed: %v", byte(op), err)
+	}
+
+	return nil
+}
+
+// ReadCommand sends the command op and fills buf with the bytes clocked out by the radio after the opcode.
+// buf holds the parameters of the command on entry.
+func (r *Radio) ReadCommand(op Opcode, buf []byte) error {
+	w := append([]byte{byte(op)}, buf...)
+	read := make([]byte, len(w))
+	if err := r.bus.Tx(w, read); err != nil {
+		return wrapf("command 0x%02X failed: %v", byte(op), err)
+	}
+
+	copy(buf, read[1:])
+	return nil
+}
+
+// WriteRegister writes data to the consecutive registers starting at address
+func (r *Radio) WriteRegister(address uint16, data ...byte) error {
+	return r.WriteCommand(OpcodeWriteRegister, append([]byte{byte(address >> 8), byte(address)}, data...)...)
+}
+
+// ReadRegister fills buf from the consecutive registers starting at address
+func (r *Radio) ReadRegister(address uint16, buf []byte) error {
+	// address followed by a NOP before the register data
+	tmp := make([]byte, 3+len(buf))
+	tmp[0], tmp[1] = byte(address>>8), byte(address)
+	if err := r.ReadCommand(OpcodeReadRegister, tmp); err != nil {
+		return err
+	}
+
+	copy(buf, tmp[3:])
+	return nil
+}
+
+// LoadPatch uploads the firmware patch into the program RAM of the radio and verifies the version it reports.
+// The radio must be in STDBY_RC mode, as it is after a reset.
+func (r *Radio) LoadPatch(patch Patch) error {
+	if err := r.checkStandby(); err != nil {
+		return err
+	}
+
+	if err := r.WriteRegister(regPatchUpdateEnable, 0x10); err != nil {
+		return err
+	}
+
+	for i, word := range patch.Words {
+		address := pramAddr + uint16(4*i)
+		if err := r.WriteRegister(address, byte(word>>24), byte(word>>16), byte(word>>8), byte(word)); err != nil {
+			return err
+		}
+	}
+
+	if err := r.WriteRegister(regPatchUpdateEnable, 0x00); err != nil {
+		return err
+	}
+
+	if err := r.WriteCommand(OpcodePatchUpdate); err != nil {
+		return err
+	}
+
+	version, err := r.PatchVersion()
+	if err != nil {
+		return err
+	}
+
+	if version != patch.Version {
+		return wrapf("firmware patch version mismatch, radio reports %q, expected %q", version, patch.Version)
+	}
+
+	return nil
+}
+
+// PatchVersion returns the version string of the firmware patch running on the radio
+func (r *Radio) PatchVersion() (string, error) {
+	buf := make([]byte, 4)
+	if err := r.ReadRegister(regPatchVersion, buf); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+// Setup puts the radio in standby and prepares it for RSSI measurements
+func (r *Radio) Setup() error {
+	if err := r.WriteCommand(OpcodeSetStandby, standbyRC); err != nil {
+		return err
+	}
+	time.Sleep(modeSettleTime)
+
+	if err := r.checkStandby(); err != nil {
+		return err
+	}
+
+	if err := r.WriteCommand(OpcodeSetBufferBaseAddress, 0x80, 0x80); err != nil {
+		return err
+	}
+
+	// sensitivity adjustment
+	return r.WriteRegister(regSensiAdjust, 0xCB)
+}
+
+// SetRxParams tunes the radio to freqHz with a receiver of the given bandwidth and starts RX
+func (r *Radio) SetRxParams(freqHz uint32, bandwidth model.Bandwith) error {
+	if err := r.WriteCommand(OpcodeSetFs); err != nil {
+		return err
+	}
+
+	freq := FreqToReg(freqHz)
+	if err := r.WriteCommand(OpcodeSetRfFrequency, byte(freq>>24), byte(freq>>16), byte(freq>>8), byte(freq)); err != nil {
+		return err
+	}
+
+	// average the RSSI over 8 samples
+	if err := r.WriteRegister(regRssiAverage, 0x05<<2); err != nil {
+		return err
+	}
+
+	switch bandwidth {
+	case model.Bw125kHz, model.Bw250kHz, model.Bw500kHz:
+		if err := r.WriteCommand(OpcodeSetPacketType, packetTypeLoRa); err != nil {
+			return err
+		}
+
+		// SF7, CR 4/5, no low datarate optimisation; the LoRa bandwidth codes match model.Bandwith
+		if err := r.WriteCommand(OpcodeSetModulationParams, 0x07, byte(bandwidth), 0x01, 0x00); err != nil {
+			return err
+		}
+
+		// 8 symbols preamble, explicit header, 255 bytes payload, no CRC, standard IQ
+		if err := r.WriteCommand(OpcodeSetPacketParams, 0x00, 0x08, 0x00, 0xFF, 0x00, 0x00); err != nil {
+			return err
+		}
+
+	default:
+		if err := r.WriteCommand(OpcodeSetPacketType, packetTypeGFSK); err != nil {
+			return err
+		}
+
+		// 50kbps, no shaping, 234.3kHz receiver bandwidth, 25kHz deviation
+		if err := r.WriteCommand(OpcodeSetModulationParams, 0x00, 0x50, 0x00, 0x00, 0x1A, 0x00, 0x01, 0x99); err != nil {
+			return err
+		}
+	}
+
+	// stay in RX until told otherwise
+	if err := r.WriteCommand(OpcodeSetRx, 0xFF, 0xFF, 0xFF); err != nil {
+		return err
+	}
+	time.Sleep(rssiSettleTime)
+
+	return nil
+}
+
+// RSSI returns the instantaneous RSSI in dBm, corrected by the RSSI offset of the board
+func (r *Radio) RSSI() (float32, error) {
+	buf := make([]byte, 2)
+	if err := r.ReadCommand(OpcodeGetRssiInst, buf); err != nil {
+		return 0, err
+	}
+
+	return -float32(buf[1])/2 + float32(r.rssiOffset), nil
+}
+
+// LBTStart starts listen-before-talk on the channel programmed by SetRxParams. The radio signals the SX1302 that the
+// channel is free while the RSSI stayed below thresholdDBm during scanTime.
+func (r *Radio) LBTStart(scanTime model.ScanTime, thresholdDBm int8) error {
+	var nbScan uint16
+	switch scanTime {
+	case model.ScanTime12Us:
+		nbScan = 24
+	case model.ScanTime5000Us:
+		nbScan = 715
+	default:
+		return wrapf("unsupported lbt scan time %s", scanTime)
+	}
+
+	// the threshold is given to the radio in -0.5dBm units, before the board RSSI offset
+	threshold := -2 * (int(thresholdDBm) - int(r.rssiOffset))
+	if threshold < 0 || threshold > 0xFF {
+		return wrapf("lbt threshold %ddBm out of range", thresholdDBm)
+	}
+
+	return r.WriteCommand(OpcodeLBTStart, scanInterval, byte(nbScan>>8), byte(nbScan), byte(threshold), lbtGpio)
+}
+
+// Stop puts the radio back in standby, stopping listen-before-talk or an ongoing spectral scan
+func (r *Radio) Stop() error {
+	if err := r.WriteCommand(OpcodeSetStandby, standbyRC); err != nil {
+		return err
+	}
+	time.Sleep(modeSettleTime)
+
+	return nil
+}
+
+// SpectralScanStart starts a spectral scan of nbScan RSSI samples on the channel programmed by SetRxParams
+func (r *Radio) SpectralScanStart(nbScan uint16) error {
+	if nbScan == 0 {
+		return wrapf("spectral scan of 0 samples")
+	}
+
+	return r.WriteCommand(OpcodeSpectralScanStart, byte(nbScan>>8), byte(nbScan), scanInterval)
+}
+
+// SpectralScanStatus returns the state of the spectral scan
+func (r *Radio) SpectralScanStatus() (model.SpectralScanStatus, error) {
+	buf := make([]byte, 1)
+	if err := r.ReadRegister(regSpectralStatus, buf); err != nil {
+		return model.SpectralScanStatusNone, err
+	}
+
+	switch buf[0] {
+	case 0x00:
+		return model.SpectralScanStatusNone, nil
+	case 0x0F:
+		return model.SpectralScanStatusOngoing, nil
+	case 0xF0:
+		return model.SpectralScanStatusAborted, nil
+	case 0xFF:
+		return model.SpectralScanStatusCompleted, nil
+	}
+
+	return model.SpectralScanStatusNone, wrapf("unknown spectral scan status 0x%02X", buf[0])
+}
+
+// SpectralScanResults returns the histogram of the completed spectral scan. levels holds the RSSI level in dBm of
+// every bin, counts the number of samples at that level.
+func (r *Radio) SpectralScanResults() (levels [SpectralScanResultSize]int16, counts [SpectralScanResultSize]uint16, err error) {
+	buf := make([]byte, 2*SpectralScanResultSize)
+	if err := r.ReadRegister(regSpectralResults, buf); err != nil {
+		return levels, counts, err
+	}
+
+	for i := range counts {
+		levels[i] = int16(-spectralScanLevelStep*i + int(r.rssiOffset))
+		counts[i] = uint16(buf[2*i+1])<<8 | uint16(buf[2*i])
+	}
+
+	return levels, counts, nil
+}
+
+// checkStandby verifies the radio is in STDBY_RC mode
+func (r *Radio) checkStandby() error {
+	buf := []byte{0x00}
+	if err := r.ReadCommand(OpcodeGetStatus, buf); err != nil {
+		return err
+	}
+
+	if mode := buf[0] >> 4 & 0x07; mode != chipModeStandbyRC {
+		return wrapf("radio is in chip mode 0x%X instead of STDBY_RC", mode)
+	}
+
+	return nil
+}
+
+// FreqToReg converts freqHz to the value of the PLL frequency register
+func FreqToReg(freqHz uint32) uint32 {
+	return uint32(uint64(freqHz) * (1 << 25) / xtalFreq)
+}
+
+// muxBus reaches the SX1261 through the SPI mux of a concentrator
+type muxBus struct {
+	com commands.Transport
+}
+
+func (b muxBus) Tx(w, r []byte) error {
+	return b.com.DevTransfer(model.SpiMuxTargetSX1261, w, r)
+}
+
+func wrapf(format string, a ...interface{}) error {
+	return fmt.Errorf("sx1261: "+format, a...)
+}
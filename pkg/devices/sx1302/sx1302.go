@@ -2,19 +2,57 @@ package sx1302
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
-	"periph.io/x/conn/v3/gpio"
 	"periph.io/x/conn/v3/spi"
 
 	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
 	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1261"
 )
 
 // Dev is an handle to an sx1302 LoRa HAT.
 type Dev struct {
-	context  model.LgwContext
-	LowLevel *commands.LowLevel
+	// mu serializes Start, Stop and Send
+	mu sync.Mutex
+
+	context model.LgwContext
+	com     commands.Transport
+	regs    *commands.Registers
+
+	// dial opens the transport of every Start, see WithDialer
+	dial commands.Dialer
+
+	firmware commands.FirmwareSet
+
+	// rxMu serializes Receive, which keeps the packets fetched beyond its limit in rxPending
+	rxMu      sync.Mutex
+	rxPending []model.PktRx
+
+	// regMu serializes the register accesses of the RX path with Send and Stop, it guards counter
+	regMu   sync.Mutex
+	counter timestampCounter
+
+	// stopped is closed by Stop, it ends the goroutine refreshing counter and the RX loops of the started concentrator
+	stopped     chan struct{}
+	counterDone sync.WaitGroup
+
+	// packetBuffer and overflowPolicy configure the channel of Packets
+	packetBuffer   int
+	overflowPolicy OverflowPolicy
+	packetCounters packetCounters
+
+	// txGainLUTSet marks the rf chains whose TX gain LUT was configured with WithTxGainLUT, the others follow the
+	// default of their radio type
+	txGainLUTSet [model.MaxRfChains]bool
+
+	// sx1261Port connects the SX1261 given by WithSX1261, sx1261Owned is the port opened from SX1261Conf.SpiPath
+	sx1261      *sx1261.Radio
+	sx1261Port  spi.Port
+	sx1261Owned spi.PortCloser
+	sx1261Patch sx1261.Patch
 }
 
 // SX1302Config is the function option for the Options pattern
@@ -22,7 +60,9 @@ type SX1302Config func(*Dev)
 
 func NewSX1302Device(opts ...SX1302Config) *Dev {
 	d := &Dev{
-		context: *model.NewLgwContextWithDefaults(),
+		context:      *model.NewLgwContextWithDefaults(),
+		dial:         commands.Dial,
+		packetBuffer: defaultPacketBuffer,
 	}
 
 	// Loop through each option
@@ -77,10 +117,16 @@ func WithRfRxConfig(rfChain uint8, conf *model.RxRf) SX1302Config {
 			log.Fatal("invalid radio center frequency. Please check the if it has been given in Hz")
 		}
 
-		if len(d.context.RfChainCfg) < int(rfChain) {
-			d.context.RfChainCfg[rfChain] = *conf
-		} else {
-			d.context.RfChainCfg = append(d.context.RfChainCfg, *conf)
+		for len(d.context.RfChainCfg) <= int(rfChain) {
+			d.context.RfChainCfg = append(d.context.RfChainCfg, model.RxRf{Enable: false})
+		}
+		d.context.RfChainCfg[rfChain] = *conf
+
+		if !d.txGainLUTSet[rfChain] {
+			for len(d.context.TxGainLUT) <= int(rfChain) {
+				d.context.TxGainLUT = append(d.context.TxGainLUT, model.TxGainLUT{})
+			}
+			d.context.TxGainLUT[rfChain] = model.NewTxGainLUTWithDefaults(conf.Type)
 		}
 
 		log.WithFields(log.Fields{
@@ -96,31 +142,311 @@ func WithRfRxConfig(rfChain uint8, conf *model.RxRf) SX1302Config {
 	}
 }
 
-func WithSPIPort(spiPort spi.Port, resetPin gpio.PinOut, irqPin gpio.PinIn) SX1302Config {
+// WithIfChainConfig configures the IF chain ifChain. IF chains 0 to 7 are the multi-SF LoRa channels, IF chain 8 is
+// the LoRa service channel and IF chain 9 the FSK channel.
+func WithIfChainConfig(ifChain uint8, conf *model.RxIf) SX1302Config {
+	return func(d *Dev) {
+		if d.context.IsStarted {
+			log.Fatal("gateway is already running. Please stop it before changing configuration")
+		}
+
+		if int(ifChain) >= model.MaxIFChains {
+			log.Fatalf("if-chain %d is not a valid if-chain number", ifChain)
+		}
+
+		// if chain is disabled -> nothing to do
+		if !conf.Enable {
+			log.WithFields(log.Fields{
+				"if_chain": ifChain,
+			}).Info("IF-Chain disabled")
+			return
+		}
+
+		if conf.RFChain >= model.MaxRfChains {
+			log.Fatalf("if-chain %d is attached to the invalid rf-chain %d", ifChain, conf.RFChain)
+		}
+
+		switch ifChain {
+		case ifChainLoRaService:
+			switch conf.Bandwidth {
+			case model.Bw125kHz, model.Bw250kHz, model.Bw500kHz:
+			default:
+				log.Fatalf("invalid bandwidth %s for the lora service if-chain", conf.Bandwidth)
+			}
+
+			if conf.Datarate < model.DrLoraSf5 || conf.Datarate > model.DrLoraSf12 {
+				log.Fatalf("invalid datarate %s for the lora service if-chain", conf.Datarate)
+			}
+
+			d.context.LoraServiceCfg = conf
+
+		case ifChainFSK:
+			switch conf.Bandwidth {
+			case model.Bw125kHz, model.Bw250kHz, model.Bw500kHz:
+			default:
+				log.Fatalf("invalid bandwidth %s for the fsk if-chain", conf.Bandwidth)
+			}
+
+			if conf.Datarate < model.DrFskMin || conf.Datarate > model.DrFskMax {
+				log.Fatalf("invalid datarate %s for the fsk if-chain", conf.Datarate)
+			}
+
+			if conf.SyncWordSize < 1 || conf.SyncWordSize > 8 {
+				log.Fatalf("invalid fsk sync word size %d, must be between 1 and 8 bytes", conf.SyncWordSize)
+			}
+
+			d.context.FSKCfg = conf
+		}
+
+		for len(d.context.IfChainCfg) <= int(ifChain) {
+			d.context.IfChainCfg = append(d.context.IfChainCfg, model.RxIf{Enable: false})
+		}
+		d.context.IfChainCfg[ifChain] = *conf
+
+		log.WithFields(log.Fields{
+			"if_chain":  ifChain,
+			"enable":    conf.Enable,
+			"rf_chain":  conf.RFChain,
+			"freq_hz":   conf.FreqHz,
+			"bandwidth": conf.Bandwidth,
+			"datarate":  conf.Datarate,
+		}).Info("IF configuration loaded")
+	}
+}
+
+// WithDemodConfig configures the spreading factors demodulated by the multi-SF LoRa channels
+func WithDemodConfig(conf *model.Demod) SX1302Config {
+	return func(d *Dev) {
+		if d.context.IsStarted {
+			log.Fatal("gateway is already running. Please stop it before changing configuration")
+		}
+
+		d.context.DemodCfg = conf
+
+		log.WithFields(log.Fields{
+			"multisf_datarate": fmt.Sprintf("0x%02X", conf.MultisfDatarate),
+		}).Info("Demodulator configuration loaded")
+	}
+}
+
+// WithSPIPort communicates with the sx1302 through the SPI port spiPort, using the GPIO pins wired to the board.
+// The port is connected by the first Start and stays connected across Stop, it is never closed by the concentrator.
+func WithSPIPort(spiPort spi.Port, pins commands.Pins) SX1302Config {
+	return WithDialer(commands.SPIDialer(spiPort, pins))
+}
+
+// WithTransport communicates with the sx1302 through the transport com. Every Start uses the transport, it is never
+// closed by the concentrator.
+func WithTransport(com commands.Transport) SX1302Config {
+	return WithDialer(commands.TransportDialer(com))
+}
+
+// WithDialer opens the transport to the sx1302 with dial on every Start, in place of commands.Dial opening the port
+// BoardConf.ComPath. The transport is closed by Stop.
+func WithDialer(dial commands.Dialer) SX1302Config {
 	return func(d *Dev) {
-		raw, err := commands.NewLowLevelSPI(spiPort, resetPin, irqPin)
-		if err != nil {
-			log.WithError(err).Fatal("failed to create low_level spi driver")
+		if d.context.IsStarted {
+			log.Fatal("gateway is already running. Please stop it before changing configuration")
 		}
 
-		if err := raw.Init(); err != nil {
-			log.WithError(err).Fatal("failed to initialize low_level spi driver")
+		d.dial = dial
+	}
+}
+
+// WithFirmware provides the firmware of the AGC and ARB MCUs in place of the firmware built into the binary, see
+// commands.LoadFirmwareDir and commands.EmbeddedFirmware
+func WithFirmware(set commands.FirmwareSet) SX1302Config {
+	return func(d *Dev) {
+		if d.context.IsStarted {
+			log.Fatal("gateway is already running. Please stop it before changing configuration")
 		}
 
-		d.LowLevel = raw
+		d.firmware = set
 	}
 }
 
-// Start starts the sx1302 board
+// Start brings the concentrator up, following lgw_start of the reference HAL: it resets the SX1302, checks the
+// chip version and, with fine timestamping, the chip model, sets up the radios and selects the clock source,
+// configures the IF chains and demodulators, starts the AGC and ARB firmware and the timestamp counter.
+//
+// Start does nothing if the concentrator is already started. It is safe for concurrent use with Stop.
 func (d *Dev) Start() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	if d.context.IsStarted {
-		return errors.New("gateway is already running. Please stop it before changing configuration")
+		return nil
 	}
 
-	// ToDo: Support USB as well
-	if d.context.BoardConfig.ComType != model.ComSPI {
-		log.WithField("com_type", d.context.BoardConfig.ComType).Fatal("only SPI is supported as of now")
+	if err := d.start(); err != nil {
+		// release the concentrator so a later Start begins from a clean state
+		if cerr := d.disconnect(); cerr != nil {
+			log.WithError(cerr).Warn("failed to release the concentrator")
+		}
+		return err
 	}
 
+	d.stopped = make(chan struct{})
+	d.counterDone.Add(1)
+	go func() {
+		defer d.counterDone.Done()
+		d.refreshCounter(d.stopped)
+	}()
+
+	d.context.IsStarted = true
 	return nil
 }
+
+// start runs the bring-up sequence of Start
+func (d *Dev) start() error {
+	if err := d.checkConfig(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := d.connect(); err != nil {
+		return err
+	}
+
+	if err := d.com.Reset(); err != nil {
+		return fmt.Errorf("failed to reset the concentrator, check the wiring of the reset pin: %w", err)
+	}
+
+	if err := d.checkVersion(); err != nil {
+		return err
+	}
+
+	if err := d.checkFineTimestamp(); err != nil {
+		return err
+	}
+
+	if err := d.setupRadios(); err != nil {
+		return err
+	}
+
+	if err := d.calibrateRadios(); err != nil {
+		return err
+	}
+
+	if err := d.configure(); err != nil {
+		return err
+	}
+
+	if err := d.startMCUs(); err != nil {
+		return err
+	}
+
+	if err := d.startTimestampCounter(); err != nil {
+		return fmt.Errorf("failed to start the timestamp counter: %w", err)
+	}
+	d.counter = timestampCounter{}
+
+	if d.context.SX1261Cfg != nil && d.context.SX1261Cfg.Enable {
+		if _, err := d.sx1261Radio(); err != nil {
+			return fmt.Errorf("failed to set up the sx1261 radio: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// configure writes the configuration of the radio front-end, the if chains, the demodulators and the timestamp
+// mode. The writes are queued in bulk mode and sent in as few transactions as possible.
+func (d *Dev) configure() (err error) {
+	if err := d.regs.SetWriteMode(model.ComWriteModeBulk); err != nil {
+		return fmt.Errorf("failed to switch to bulk writes: %w", err)
+	}
+	defer func() {
+		if flushErr := d.regs.SetWriteMode(model.ComWriteModeSingle); flushErr != nil && err == nil {
+			err = fmt.Errorf("failed to write the configuration: %w", flushErr)
+		}
+	}()
+
+	if err := d.configureRadioFrontEnd(); err != nil {
+		return fmt.Errorf("failed to configure the radio front-end: %w", err)
+	}
+
+	if err := d.configureChannelizer(); err != nil {
+		return fmt.Errorf("failed to configure the if chains: %w", err)
+	}
+
+	if err := d.configureDemodulators(); err != nil {
+		return fmt.Errorf("failed to configure the demodulators: %w", err)
+	}
+
+	if err := d.configureTimestampMode(); err != nil {
+		return fmt.Errorf("failed to configure the timestamp mode: %w", err)
+	}
+
+	return nil
+}
+
+// startMCUs loads the AGC and ARB firmware and runs their start handshake
+func (d *Dev) startMCUs() error {
+	// the AGC firmware is selected by the radio type of the rf chain clocking the concentrator
+	radioType := d.context.RfChainCfg[d.context.BoardConfig.ClkSrc].Type
+	agc, err := d.firmware.AGC(radioType)
+	if err != nil {
+		return err
+	}
+
+	if err := d.regs.LoadFirmware(commands.MCUAgc, agc); err != nil {
+		return fmt.Errorf("failed to load the agc firmware: %w", err)
+	}
+
+	if err := d.regs.LoadFirmware(commands.MCUArb, d.firmware.ARB); err != nil {
+		return fmt.Errorf("failed to load the arb firmware: %w", err)
+	}
+
+	agcConf := commands.AGCConfig{
+		RadioType:  radioType,
+		FullDuplex: d.context.BoardConfig.FullDuplex,
+		LBTEnable:  d.lbtEnabled(),
+	}
+
+	if err := d.regs.AGCStart(agc.Version, agcConf); err != nil {
+		return fmt.Errorf("failed to start the agc firmware: %w", err)
+	}
+
+	if err := d.regs.ARBStart(d.firmware.ARB.Version, d.context.FineTimestampCfg); err != nil {
+		return fmt.Errorf("failed to start the arb firmware: %w", err)
+	}
+
+	return nil
+}
+
+// connect opens the transport of the concentrator
+func (d *Dev) connect() error {
+	com, err := d.dial(d.context.BoardConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the concentrator: %w", err)
+	}
+
+	d.com = com
+	d.regs = commands.NewRegisters(com)
+	return nil
+}
+
+// disconnect closes the transport and the SX1261 port opened by Start
+func (d *Dev) disconnect() error {
+	var errs []error
+	if d.com != nil {
+		if err := d.com.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close the transport: %w", err))
+		}
+	}
+
+	if d.sx1261Owned != nil {
+		if err := d.sx1261Owned.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close the sx1261 spi port: %w", err))
+		}
+	}
+
+	d.com, d.regs, d.sx1261Owned = nil, nil, nil
+
+	// an SX1261 behind the SPI mux was reached through the closed transport, the one on SpiPath through the closed port
+	if d.sx1261Port == nil {
+		d.sx1261 = nil
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,213 @@
+package sx1302_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// testFirmware returns a firmware set of random images with the versions reported by the simulator
+func testFirmware() commands.FirmwareSet {
+	rng := rand.New(rand.NewSource(1))
+	image := func() []byte {
+		img := make([]byte, commands.MCUMemSize)
+		rng.Read(img)
+		return img
+	}
+
+	return commands.FirmwareSet{
+		AGCSX1250: commands.Firmware{Image: image(), Version: commands.AGCFirmwareVersionSX1250},
+		ARB:       commands.Firmware{Image: image(), Version: commands.ARBFirmwareVersion},
+		AGCSX125x: commands.Firmware{Image: image(), Version: commands.AGCFirmwareVersionSX125x},
+		Cal:       commands.Firmware{Image: image(), Version: commands.CalFirmwareVersion},
+	}
+}
+
+// newSimDev returns a device with an SX1250 on rf chain 0 attached to a new simulator
+func newSimDev(t *testing.T, opts ...sx1302.SX1302Config) (*sx1302.Dev, *sx1302test.Sim) {
+	t.Helper()
+
+	sim := sx1302test.NewSim()
+	sim.OnRadio = (&sx1302test.SX1250{}).Handle
+
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+
+	d := sx1302.NewSX1302Device(append([]sx1302.SX1302Config{
+		sx1302.WithBoardConfig(model.NewBoardConfig()),
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithSPIPort(sim, commands.Pins{Reset: sim.ResetPin()}),
+		sx1302.WithFirmware(testFirmware()),
+	}, opts...)...)
+
+	return d, sim
+}
+
+func TestRxLoopEndsOnStop(t *testing.T) {
+	d, _ := newSimDev(t)
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- d.RxLoop(context.Background(), func() (int, error) { return 0, nil })
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if err := d.Stop(); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("RxLoop() = %v after Stop, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RxLoop() did not end after Stop")
+	}
+}
+
+func TestStartQueuesConfiguration(t *testing.T) {
+	var buf bytes.Buffer
+	sim := sx1302test.NewSim()
+	sim.OnRadio = (&sx1302test.SX1250{}).Handle
+	rec := &sx1302test.Recorder{Port: sim, W: &buf}
+
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+
+	d := sx1302.NewSX1302Device(
+		sx1302.WithBoardConfig(model.NewBoardConfig()),
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithIfChainConfig(0, &model.RxIf{Enable: true, FreqHz: -200000}),
+		sx1302.WithSPIPort(rec, commands.Pins{Reset: sim.ResetPin()}),
+		sx1302.WithFirmware(testFirmware()),
+	)
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop()
+
+	// the bit fields of the radio front-end and the demodulators are read-modify-written from the shadowed bytes,
+	// each byte is read once at most
+	reads := make(map[string]int)
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var tr sx1302test.Transaction
+		if err := json.Unmarshal(scanner.Bytes(), &tr); err != nil {
+			t.Fatalf("invalid recording line %q: %v", scanner.Text(), err)
+		}
+
+		location, _, _ := strings.Cut(tr.Decoded, " -> ")
+		if strings.HasPrefix(location, "R ") && (strings.Contains(location, " RADIO_FE_") || strings.Contains(location, " RX_TOP_")) {
+			reads[location]++
+		}
+	}
+
+	for location, n := range reads {
+		if n > 1 {
+			t.Errorf("Start() read %s %d times, want once", location[2:], n)
+		}
+	}
+}
+
+// startTrace returns the writes of a recording, abbreviating the data of bursts to their size and checksum
+func startTrace(t *testing.T, recording []byte) string {
+	t.Helper()
+
+	var trace strings.Builder
+	scanner := bufio.NewScanner(bytes.NewReader(recording))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var tr sx1302test.Transaction
+		if err := json.Unmarshal(scanner.Bytes(), &tr); err != nil {
+			t.Fatalf("invalid recording line %q: %v", scanner.Text(), err)
+		}
+
+		// reads depend on how often a status was polled
+		if strings.HasPrefix(tr.Decoded, "R ") {
+			continue
+		}
+
+		line := tr.Decoded
+		if w, _ := hex.DecodeString(tr.W); len(w) > 3+16 && strings.HasPrefix(line, "W ") {
+			line = fmt.Sprintf("%s = %d bytes crc32 %08X", line[:strings.Index(line, " = ")], len(w)-3, crc32.ChecksumIEEE(w[3:]))
+		}
+
+		fmt.Fprintln(&trace, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read the recording: %v", err)
+	}
+
+	return trace.String()
+}
+
+func TestStartTrace(t *testing.T) {
+	var buf bytes.Buffer
+	sim := sx1302test.NewSim()
+	sim.OnRadio = (&sx1302test.SX1250{}).Handle
+	rec := &sx1302test.Recorder{Port: sim, W: &buf}
+
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+
+	d := sx1302.NewSX1302Device(
+		sx1302.WithBoardConfig(model.NewBoardConfig()),
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithIfChainConfig(0, &model.RxIf{Enable: true, FreqHz: -200000}),
+		sx1302.WithSPIPort(rec, commands.Pins{Reset: sim.ResetPin()}),
+		sx1302.WithFirmware(testFirmware()),
+	)
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer d.Stop()
+
+	got := startTrace(t, buf.Bytes())
+	golden := filepath.Join("testdata", "start.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update %s: %v", golden, err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", golden, err)
+	}
+
+	if got != string(want) {
+		gotLines, wantLines := strings.Split(got, "\n"), strings.Split(string(want), "\n")
+		for i := 0; i < min(len(gotLines), len(wantLines)); i++ {
+			if gotLines[i] != wantLines[i] {
+				t.Fatalf("Start() write %d = %q, want %q (run with -update after checking the change)", i+1, gotLines[i], wantLines[i])
+			}
+		}
+		t.Fatalf("Start() wrote %d lines, want %d (run with -update after checking the change)", len(gotLines), len(wantLines))
+	}
+}
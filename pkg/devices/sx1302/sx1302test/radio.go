@@ -0,0 +1,86 @@
+package sx1302test
+
+import (
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1250"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx125x"
+)
+
+// sx125xVersion is the silicon revision reported by the emulated SX125x radios
+const sx125xVersion uint8 = 0x21
+
+// SX1250 emulates the command interface of the SX1250 radios behind the SPI mux. Every byte clocked out after the
+// opcode is the status byte, reporting the mode entered by the last SetStandby, SetFs, SetRx or SetTx command.
+// Register reads return zeros. Use Handle as Sim.OnRadio.
+type SX1250 struct {
+	mode [model.MaxRfChains]sx1250.ChipMode
+}
+
+// Handle implements RadioHandler
+func (r *SX1250) Handle(rfChain int, frame []byte, resp []byte) {
+	if len(frame) < 2 {
+		return
+	}
+
+	mode := &r.mode[rfChain]
+	if *mode == 0 {
+		*mode = sx1250.ChipModeStandbyRC
+	}
+
+	// the status is clocked out while the opcode and its parameters are sent
+	for i := 1; i < len(resp); i++ {
+		resp[i] = byte(*mode) << 4
+	}
+
+	// register data follows the address and a NOP
+	if sx1250.Opcode(frame[1]) == sx1250.OpcodeReadRegister && len(resp) > 5 {
+		clear(resp[5:])
+	}
+
+	switch sx1250.Opcode(frame[1]) {
+	case sx1250.OpcodeSetStandby:
+		*mode = sx1250.ChipModeStandbyRC
+		if len(frame) > 2 && sx1250.StandbyMode(frame[2]) == sx1250.StandbyXOSC {
+			*mode = sx1250.ChipModeStandbyXOSC
+		}
+	case sx1250.OpcodeSetFs:
+		*mode = sx1250.ChipModeFS
+	case sx1250.OpcodeSetRx:
+		*mode = sx1250.ChipModeRX
+	case sx1250.OpcodeSetTx:
+		*mode = sx1250.ChipModeTX
+	}
+}
+
+// SX125x emulates the register file of the SX1255 or SX1257 radios behind the SPI mux. Entering RX mode locks the
+// RX PLL, entering TX mode locks the TX PLL. Use Handle as Sim.OnRadio.
+type SX125x struct {
+	regs [model.MaxRfChains][128]byte
+}
+
+// Reg returns the value of the register at address of the radio of rfChain
+func (r *SX125x) Reg(rfChain int, address uint8) uint8 {
+	return r.regs[rfChain][address&0x7F]
+}
+
+// Handle implements RadioHandler
+func (r *SX125x) Handle(rfChain int, frame []byte, resp []byte) {
+	if len(frame) < 3 {
+		return
+	}
+
+	regs := &r.regs[rfChain]
+	regs[sx125x.RegVersion] = sx125xVersion
+
+	address := frame[1] & 0x7F
+	if frame[1]&0x80 == 0 {
+		resp[2] = regs[address]
+		return
+	}
+
+	regs[address] = frame[2]
+	if address == sx125x.RegMode {
+		// bit 1 enables the RX path, bit 2 the TX path
+		regs[sx125x.RegModeStatus] = frame[2]>>1&0x01<<1 | frame[2]>>2&0x01
+	}
+}
@@ -0,0 +1,358 @@
+package sx1302test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// Transaction is a single SPI transaction as recorded by Recorder. Packets sent with TxPackets are recorded as
+// separate transactions, so a replay does not depend on how writes were grouped.
+type Transaction struct {
+	Time    time.Time `json:"time"`
+	W       string    `json:"w"`
+	R       string    `json:"r,omitempty"`
+	Decoded string    `json:"decoded"`
+}
+
+// Recorder wraps a SPI port and records every transaction as a line of JSON to W
+type Recorder struct {
+	sync.Mutex
+
+	// Port is the recorded SPI port
+	Port spi.PortCloser
+
+	// W receives the recorded transactions
+	W io.Writer
+
+	enc *json.Encoder
+}
+
+// String implements conn.Resource
+func (r *Recorder) String() string {
+	return fmt.Sprintf("record(%s)", r.Port)
+}
+
+// Close implements spi.PortCloser
+func (r *Recorder) Close() error {
+	return r.Port.Close()
+}
+
+// LimitSpeed implements spi.PortCloser
+func (r *Recorder) LimitSpeed(f physic.Frequency) error {
+	return r.Port.LimitSpeed(f)
+}
+
+// Connect implements spi.Port
+func (r *Recorder) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	c, err := r.Port.Connect(f, mode, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	r.enc = json.NewEncoder(r.W)
+	return &recordConn{r: r, c: c}, nil
+}
+
+// record writes the transaction w and the bytes read r to the recording
+func (r *Recorder) record(w, read []byte) error {
+	t := Transaction{
+		Time:    time.Now(),
+		W:       hex.EncodeToString(w),
+		R:       hex.EncodeToString(read),
+		Decoded: Decode(w, read),
+	}
+
+	return r.enc.Encode(t)
+}
+
+// recordConn is the spi.Conn returned by Recorder.Connect
+type recordConn struct {
+	r *Recorder
+	c spi.Conn
+}
+
+func (c *recordConn) String() string {
+	return c.r.String()
+}
+
+func (c *recordConn) Duplex() conn.Duplex {
+	return c.c.Duplex()
+}
+
+func (c *recordConn) MaxTxSize() int {
+	if l, ok := c.c.(conn.Limits); ok {
+		return l.MaxTxSize()
+	}
+
+	return 0
+}
+
+func (c *recordConn) Tx(w, read []byte) error {
+	c.r.Lock()
+	defer c.r.Unlock()
+
+	if err := c.c.Tx(w, read); err != nil {
+		return err
+	}
+
+	return c.r.record(w, read)
+}
+
+func (c *recordConn) TxPackets(packets []spi.Packet) error {
+	c.r.Lock()
+	defer c.r.Unlock()
+
+	if err := c.c.TxPackets(packets); err != nil {
+		return err
+	}
+
+	for _, p := range packets {
+		if err := c.r.record(p.W, p.R); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Replayer implements spi.PortCloser and answers with the responses of a recording made by Recorder.
+// Any transaction diverging from the recording fails with an error describing both transactions.
+type Replayer struct {
+	sync.Mutex
+
+	// Transactions to replay
+	Transactions []Transaction
+
+	// Count is the number of transactions replayed so far
+	Count int
+
+	connected bool
+}
+
+// NewReplayer loads the recording written by Recorder from rd
+func NewReplayer(rd io.Reader) (*Replayer, error) {
+	p := &Replayer{}
+
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var t Transaction
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			return nil, fmt.Errorf("sx1302replay: line %d: %w", line, err)
+		}
+		p.Transactions = append(p.Transactions, t)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("sx1302replay: %w", err)
+	}
+
+	return p, nil
+}
+
+// String implements conn.Resource
+func (p *Replayer) String() string {
+	return "sx1302replay"
+}
+
+// Close verifies that all recorded transactions were replayed
+func (p *Replayer) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.Count != len(p.Transactions) {
+		return fmt.Errorf("sx1302replay: replayed %d of %d transactions, next expected %s", p.Count, len(p.Transactions), p.Transactions[p.Count].Decoded)
+	}
+
+	return nil
+}
+
+// LimitSpeed implements spi.PortCloser
+func (p *Replayer) LimitSpeed(f physic.Frequency) error {
+	return nil
+}
+
+// Connect implements spi.Port
+func (p *Replayer) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.connected {
+		return nil, fmt.Errorf("sx1302replay: Connect cannot be called twice")
+	}
+
+	p.connected = true
+	return p, nil
+}
+
+// Duplex implements conn.Conn
+func (p *Replayer) Duplex() conn.Duplex {
+	return conn.Full
+}
+
+// Tx implements conn.Conn
+func (p *Replayer) Tx(w, r []byte) error {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.tx(w, r)
+}
+
+// TxPackets implements spi.Conn
+func (p *Replayer) TxPackets(packets []spi.Packet) error {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, packet := range packets {
+		if err := p.tx(packet.W, packet.R); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tx compares the transaction with the next recorded one and answers with the recorded response
+func (p *Replayer) tx(w, r []byte) error {
+	if p.Count >= len(p.Transactions) {
+		return fmt.Errorf("sx1302replay: unexpected transaction #%d %s after the end of the recording", p.Count, Decode(w, nil))
+	}
+
+	t := p.Transactions[p.Count]
+	expected, err := hex.DecodeString(t.W)
+	if err != nil {
+		return fmt.Errorf("sx1302replay: transaction #%d: %w", p.Count, err)
+	}
+
+	if !bytes.Equal(expected, w) {
+		return fmt.Errorf("sx1302replay: transaction #%d diverged: got %s, recorded %s", p.Count, Decode(w, nil), t.Decoded)
+	}
+
+	response, err := hex.DecodeString(t.R)
+	if err != nil {
+		return fmt.Errorf("sx1302replay: transaction #%d: %w", p.Count, err)
+	}
+
+	if len(response) != len(r) {
+		return fmt.Errorf("sx1302replay: transaction #%d read %d bytes, recorded %d bytes", p.Count, len(r), len(response))
+	}
+
+	copy(r, response)
+	p.Count++
+	return nil
+}
+
+// memRegions are the memory regions of the SX1302 address space, named in decoded transactions
+var memRegions = []struct {
+	name  string
+	start uint16
+	size  int
+}{
+	{"AGC_MEM", commands.AGCMemAddr, commands.MCUMemSize},
+	{"ARB_MEM", commands.ARBMemAddr, commands.MCUMemSize},
+	{"RX_BUFFER", commands.RxBufferAddr, commands.RxBufferSize},
+	{"TX_BUFFER_A", commands.TxBufferAddrA, commands.TxBufferSize},
+	{"TX_BUFFER_B", commands.TxBufferAddrB, commands.TxBufferSize},
+}
+
+// regNames maps the address of every register byte to the names of the registers stored in it
+var regNames = func() map[uint16][]string {
+	names := map[uint16][]string{}
+	for _, reg := range commands.RegisterMap() {
+		for i := range fieldMasks(reg) {
+			addr := reg.Addr + uint16(i)
+			names[addr] = append(names[addr], reg.Name)
+		}
+	}
+
+	for addr := range names {
+		sort.Strings(names[addr])
+	}
+
+	return names
+}()
+
+// Decode describes the SPI transaction w in terms of the SX1302 register map. read holds the bytes clocked in
+// and may be nil.
+func Decode(w, read []byte) string {
+	if len(w) < 1 {
+		return "empty"
+	}
+
+	switch w[0] {
+	case model.SpiMuxTargetRadioA, model.SpiMuxTargetRadioB:
+		name := "RADIO_A"
+		if w[0] == model.SpiMuxTargetRadioB {
+			name = "RADIO_B"
+		}
+		if len(read) == len(w) {
+			return fmt.Sprintf("%s %X -> %X", name, w[1:], read[1:])
+		}
+		return fmt.Sprintf("%s %X", name, w[1:])
+
+	case model.SpiMuxTargetSX1261:
+		return fmt.Sprintf("SX1261 %X", w[1:])
+
+	case model.SpiMuxTargetSX1302:
+	default:
+		return fmt.Sprintf("unknown mux target 0x%02X %X", w[0], w[1:])
+	}
+
+	if len(w) < 4 {
+		return fmt.Sprintf("truncated %X", w)
+	}
+
+	addr := uint16(w[1]&0x7F)<<8 | uint16(w[2])
+	if w[1]&0x80 != 0 {
+		return fmt.Sprintf("W %s = %X", location(addr, len(w)-3), w[3:])
+	}
+
+	if len(read) == len(w) && len(w) > 4 {
+		return fmt.Sprintf("R %s -> %X", location(addr, len(w)-4), read[4:])
+	}
+
+	return fmt.Sprintf("R %s", location(addr, len(w)-4))
+}
+
+// location names the size bytes starting at addr
+func location(addr uint16, size int) string {
+	for _, region := range memRegions {
+		if addr >= region.start && int(addr) < int(region.start)+region.size {
+			return fmt.Sprintf("0x%04X %s+0x%X [%d]", addr, region.name, addr-region.start, size)
+		}
+	}
+
+	names, ok := regNames[addr]
+	if !ok {
+		return fmt.Sprintf("0x%04X [%d]", addr, size)
+	}
+
+	if size == 1 {
+		return fmt.Sprintf("0x%04X %s", addr, strings.Join(names, "|"))
+	}
+
+	return fmt.Sprintf("0x%04X %s... [%d]", addr, strings.Join(names, "|"), size)
+}
+
+var _ spi.PortCloser = &Recorder{}
+var _ spi.PortCloser = &Replayer{}
+var _ spi.Conn = &Replayer{}
@@ -0,0 +1,95 @@
+package sx1302test_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"periph.io/x/conn/v3/gpio/gpiotest"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// session writes gain to a register and returns the value read back
+func session(t *testing.T, regs *commands.Registers, gain int32) (int32, error) {
+	t.Helper()
+
+	if err := regs.RegWrite(commands.RegRadioFeCtrl0RadioAHostFilterGain, gain); err != nil {
+		return 0, err
+	}
+
+	return regs.RegRead(commands.RegRadioFeCtrl0RadioAHostFilterGain)
+}
+
+// record runs a session on a simulator and returns the recording
+func record(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	sim := sx1302test.NewSim()
+	rec := &sx1302test.Recorder{Port: sim, W: &buf}
+
+	ll, err := commands.NewLowLevelSPI(rec, commands.Pins{Reset: sim.ResetPin()})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	if got, err := session(t, commands.NewRegisters(ll), 0x0B); err != nil || got != 0x0B {
+		t.Fatalf("recorded session read %d, %v, want 11", got, err)
+	}
+
+	return buf.Bytes()
+}
+
+// replay loads recording into a Replayer and connects a register access to it
+func replay(t *testing.T, recording []byte) (*commands.Registers, *sx1302test.Replayer) {
+	t.Helper()
+
+	p, err := sx1302test.NewReplayer(bytes.NewReader(recording))
+	if err != nil {
+		t.Fatalf("NewReplayer() failed: %v", err)
+	}
+
+	ll, err := commands.NewLowLevelSPI(p, commands.Pins{Reset: &gpiotest.Pin{N: "RESET"}})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	return commands.NewRegisters(ll), p
+}
+
+func TestRecordReplay(t *testing.T) {
+	recording := record(t)
+	if !strings.Contains(string(recording), "RADIO_FE_CTRL0_RADIO_A_HOST_FILTER_GAIN") {
+		t.Errorf("recording does not name the accessed register:\n%s", recording)
+	}
+
+	regs, p := replay(t, recording)
+	got, err := session(t, regs, 0x0B)
+	if err != nil {
+		t.Fatalf("replayed session failed: %v", err)
+	}
+
+	if got != 0x0B {
+		t.Errorf("replayed session read %d, want 11", got)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Errorf("Close() = %v, want all transactions replayed", err)
+	}
+}
+
+func TestReplayDivergence(t *testing.T) {
+	recording := record(t)
+
+	regs, p := replay(t, recording)
+	_, err := session(t, regs, 0x0C)
+	if err == nil || !strings.Contains(err.Error(), "diverged") {
+		t.Fatalf("session writing another value = %v, want a divergence error", err)
+	}
+
+	if err := p.Close(); err == nil {
+		t.Error("Close() after a divergence reported all transactions replayed")
+	}
+}
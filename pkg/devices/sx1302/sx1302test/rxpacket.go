@@ -0,0 +1,77 @@
+package sx1302test
+
+import (
+	"encoding/binary"
+)
+
+// RxPacket describes a packet as stored by the SX1302 in its RX buffer. Encode it and pass it to Sim.InjectRx to
+// simulate a reception.
+type RxPacket struct {
+	Channel    uint8
+	CrcEn      bool
+	CodingRate uint8
+	Datarate   uint8
+	ModemID    uint8
+
+	// FreqOffsetError is the 20 bit signed frequency offset measured by the demodulator
+	FreqOffsetError int32
+	Payload         []byte
+
+	CrcError    bool
+	SyncError   bool
+	HeaderError bool
+	TimingSet   bool
+
+	// SnrAvg is the SNR in steps of 0.25dB
+	SnrAvg      int8
+	RssiChanAvg uint8
+	RssiSigAvg  uint8
+
+	// Timestamp is the value of the 32MHz timestamp counter latched for the packet
+	Timestamp uint32
+	Crc       uint16
+
+	// TsMetrics are the fine timestamp metrics, two per metric
+	TsMetrics []int8
+}
+
+// Encode returns the packet framed as in the RX buffer, including the sync word and checksum
+func (p RxPacket) Encode() []byte {
+	var rateCrc byte
+	if p.CrcEn {
+		rateCrc |= 0x01
+	}
+	rateCrc |= p.CodingRate & 0x07 << 1
+	rateCrc |= p.Datarate << 4
+
+	var status byte
+	for bit, set := range map[byte]bool{0x01: p.CrcError, 0x04: p.SyncError, 0x08: p.HeaderError, 0x10: p.TimingSet} {
+		if set {
+			status |= bit
+		}
+	}
+
+	buf := []byte{
+		0xA5, 0xC0,
+		byte(len(p.Payload)),
+		p.Channel,
+		rateCrc,
+		p.ModemID,
+		byte(p.FreqOffsetError), byte(p.FreqOffsetError >> 8), byte(p.FreqOffsetError>>16) & 0x0F,
+	}
+	buf = append(buf, p.Payload...)
+	buf = append(buf, status, byte(p.SnrAvg), p.RssiChanAvg, p.RssiSigAvg, 0x00, 0x00)
+	buf = binary.LittleEndian.AppendUint32(buf, p.Timestamp)
+	buf = binary.LittleEndian.AppendUint16(buf, p.Crc)
+	buf = append(buf, byte(len(p.TsMetrics)/2))
+	for _, m := range p.TsMetrics {
+		buf = append(buf, byte(m))
+	}
+
+	var checksum byte
+	for _, b := range buf {
+		checksum += b
+	}
+
+	return append(buf, checksum)
+}
@@ -0,0 +1,687 @@
+package sx1302test
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpiotest"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+const (
+	// addressSpace is the size of the 15 bit address space of the SX1302
+	addressSpace = 0x8000
+
+	// otpModelIDAddr is the OTP byte holding the chip model ID
+	otpModelIDAddr = 0xD0
+
+	// ModelIDSX1302 is the chip model ID of a SX1302
+	ModelIDSX1302 uint8 = 0x02
+
+	// ModelIDSX1303 is the chip model ID of a SX1303
+	ModelIDSX1303 uint8 = 0x03
+
+	// agcStatusStarted and arbStatusStarted are reported by the MCUs once their firmware started
+	agcStatusStarted = 0x01
+	arbStatusStarted = 0x01
+
+	// calNotifyStart and calNotifyRead start the calibration and read back one of its results
+	calNotifyStart = 0x01
+	calNotifyRead  = 0x02
+
+	// calStatusFinished and calStatusRegAccess are set in the status reported at the end of the calibration, together
+	// with the radio access, RX and TX bits of each radio shifted by the rf chain
+	calStatusFinished  = 0x80
+	calStatusRegAccess = 0x01
+	calStatusRadio     = 0x02
+	calStatusRx        = 0x08
+	calStatusTx        = 0x20
+
+	// calCmdRx and calCmdTx select the radios calibrated by the calibration command, shifted by the rf chain
+	calCmdRx = 0x01
+	calCmdTx = 0x04
+
+	// calReadValid marks the echo of a result read back
+	calReadValid = 0x80
+
+	// txTrigImmediate, txTrigDelayed and txTrigGps are the trigger bits of the TX state machine
+	txTrigImmediate = 0x01
+	txTrigDelayed   = 0x02
+	txTrigGps       = 0x04
+
+	// txStatusEmitting is the status of the TX state machine once triggered immediately, txStatusDelayed and
+	// txStatusGps once armed for a counter value, respectively the PPS
+	txStatusEmitting = 0x30
+	txStatusDelayed  = 0x91
+	txStatusGps      = 0x92
+)
+
+// agcStep is a step of the AGC handshake: the firmware acknowledges a notification received in status from with
+// status to
+type agcStep struct {
+	from, to int32
+}
+
+// agcHandshake maps the notification written to the last AGC mailbox to the step it runs, following the firmware
+// used by sx1302_agc_start of the reference HAL. The SX125x firmware has no PA configuration step, its PA start
+// delay step follows the channel thresholds.
+var agcHandshake = map[byte]agcStep{
+	0x80: {0x01, 0x02},
+	0x20: {0x02, 0x03},
+	0x03: {0x03, 0x04},
+	0x04: {0x04, 0x05},
+	0x05: {0x05, 0x06},
+	0x06: {0x06, 0x07},
+	0x07: {0x07, 0x08},
+	0x08: {0x08, 0x09},
+	0x09: {0x09, 0x0A},
+	0x0A: {0x0A, 0x0B},
+	0x0B: {0x0B, 0x0F},
+}
+
+// Write is a register write seen by the simulator
+type Write struct {
+	Addr  uint16
+	Value byte
+}
+
+func (w Write) String() string {
+	return fmt.Sprintf("0x%04X=0x%02X", w.Addr, w.Value)
+}
+
+// RadioHandler answers a SPI frame sent to the radio behind SPI mux target rfChain+1.
+// resp has the length of frame and is sent back to the host.
+type RadioHandler func(rfChain int, frame []byte, resp []byte)
+
+// Sim is a register-level simulation of a SX1302 concentrator. It implements spi.PortCloser and spi.Conn, decodes
+// the SPI mux framing and keeps a register file initialised with the reset values of the register map.
+// Addresses of registers reachable from all pages are shared, all other addresses are banked by the page selected
+// through COMMON_PAGE_PAGE.
+type Sim struct {
+	sync.Mutex
+
+	// ModelID is the chip model ID reported through the OTP
+	ModelID uint8
+
+	// MaxTx is the maximum transfer size reported through conn.Limits, 0 for unlimited
+	MaxTx int
+
+	// OnRadio answers SPI frames sent to the radios. Radio frames are answered with zeros if unset
+	OnRadio RadioHandler
+
+	// AGCVersion and ARBVersion are the versions reported by the emulated MCU firmwares
+	AGCVersion uint8
+	ARBVersion uint8
+
+	// CalFirmware is the image of the calibration firmware, the AGC runs the calibration protocol when started with it
+	CalFirmware []byte
+
+	// CalVersion is the version reported by the emulated calibration firmware
+	CalVersion uint8
+
+	// CalResults are the offsets found by the emulated calibration firmware for the radio of each rf chain
+	CalResults [model.MaxRfChains]commands.CalResult
+
+	// CalFailures are the bits cleared from the status reported at the end of the calibration
+	CalFailures uint8
+
+	mem       [addressSpace]byte
+	pages     map[int8]*[addressSpace]byte
+	shared    map[uint16]bool
+	roMask    [addressSpace]byte
+	calRun    bool
+	calCmd    int32
+	rx        []byte
+	writes    []Write
+	txCount   int
+	radio     [model.MaxRfChains][][]byte
+	connected bool
+}
+
+// NewSim creates a simulated SX1302 with all registers at their reset value
+func NewSim() *Sim {
+	s := &Sim{
+		ModelID:    ModelIDSX1302,
+		AGCVersion: commands.AGCFirmwareVersionSX1250,
+		ARBVersion: commands.ARBFirmwareVersion,
+		CalVersion: commands.CalFirmwareVersion,
+		shared:     make(map[uint16]bool),
+	}
+
+	for _, reg := range commands.RegisterMap() {
+		if reg.Page == commands.PageAll {
+			for i := range fieldMasks(reg) {
+				s.shared[reg.Addr+uint16(i)] = true
+			}
+		}
+
+		if reg.ReadOnly {
+			for i, mask := range fieldMasks(reg) {
+				s.roMask[int(reg.Addr)+i] |= mask
+			}
+		}
+	}
+	s.reset()
+
+	return s
+}
+
+// Reset puts all registers back to their reset value and empties the RX buffer
+func (s *Sim) Reset() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.reset()
+}
+
+// ResetPin returns a pin to be used as reset pin of the concentrator. Releasing the reset resets the simulator.
+func (s *Sim) ResetPin() gpio.PinIO {
+	return &resetPin{Pin: gpiotest.Pin{N: "SX1302_RESET"}, sim: s}
+}
+
+// Reg returns the value of register id
+func (s *Sim) Reg(id commands.RegID) int32 {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.reg(id)
+}
+
+// SetReg sets register id to value, including read-only registers
+func (s *Sim) SetReg(id commands.RegID, value int32) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.setReg(id, value)
+}
+
+// Mem returns a copy of size bytes of the address space of page 0 starting at addr
+func (s *Sim) Mem(addr uint16, size int) []byte {
+	return s.PageMem(0, addr, size)
+}
+
+// PageMem returns a copy of size bytes of the address space of page starting at addr
+func (s *Sim) PageMem(page int8, addr uint16, size int) []byte {
+	s.Lock()
+	defer s.Unlock()
+
+	out := make([]byte, size)
+	for i := range out {
+		out[i] = s.bank(page, addr+uint16(i))[(int(addr)+i)%addressSpace]
+	}
+
+	return out
+}
+
+// Page returns the page currently selected through COMMON_PAGE_PAGE
+func (s *Sim) Page() int8 {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.page()
+}
+
+// InjectRx appends data to the RX buffer, as if the concentrator received packets
+func (s *Sim) InjectRx(data []byte) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.rx = append(s.rx, data...)
+	s.updateRxCount()
+}
+
+// Writes returns the register writes seen since the last call of ClearWrites
+func (s *Sim) Writes() []Write {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([]Write(nil), s.writes...)
+}
+
+// ClearWrites empties the log of register writes and resets the count of transactions
+func (s *Sim) ClearWrites() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.writes = nil
+	s.txCount = 0
+}
+
+// Transactions returns the number of SPI transactions, calls of Tx or TxPackets, seen since the last call of
+// ClearWrites
+func (s *Sim) Transactions() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.txCount
+}
+
+// RadioFrames returns the SPI frames sent to the radio of rfChain
+func (s *Sim) RadioFrames(rfChain int) [][]byte {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([][]byte(nil), s.radio[rfChain]...)
+}
+
+// String implements conn.Resource
+func (s *Sim) String() string {
+	return "sx1302sim"
+}
+
+// Close implements spi.PortCloser
+func (s *Sim) Close() error {
+	return nil
+}
+
+// LimitSpeed implements spi.PortCloser
+func (s *Sim) LimitSpeed(f physic.Frequency) error {
+	return nil
+}
+
+// Connect implements spi.Port
+func (s *Sim) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.connected {
+		return nil, fmt.Errorf("sx1302sim: Connect cannot be called twice")
+	}
+
+	s.connected = true
+	return s, nil
+}
+
+// Duplex implements conn.Conn
+func (s *Sim) Duplex() conn.Duplex {
+	return conn.Full
+}
+
+// MaxTxSize implements conn.Limits
+func (s *Sim) MaxTxSize() int {
+	return s.MaxTx
+}
+
+// Tx implements conn.Conn
+func (s *Sim) Tx(w, r []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.txCount++
+	return s.tx(w, r)
+}
+
+// TxPackets implements spi.Conn
+func (s *Sim) TxPackets(packets []spi.Packet) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.txCount++
+	for _, p := range packets {
+		if err := s.tx(p.W, p.R); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tx decodes a single SPI transaction
+func (s *Sim) tx(w, r []byte) error {
+	if s.MaxTx > 0 && len(w) > s.MaxTx {
+		return fmt.Errorf("sx1302sim: transaction of %d bytes exceeds %d bytes", len(w), s.MaxTx)
+	}
+
+	if len(r) != 0 && len(r) != len(w) {
+		return fmt.Errorf("sx1302sim: read buffer of %d bytes for a write of %d bytes", len(r), len(w))
+	}
+
+	if len(w) < 1 {
+		return fmt.Errorf("sx1302sim: empty transaction")
+	}
+
+	switch muxTarget := w[0]; muxTarget {
+	case model.SpiMuxTargetSX1302:
+		return s.txSX1302(w, r)
+
+	case model.SpiMuxTargetRadioA, model.SpiMuxTargetRadioB:
+		rfChain := int(muxTarget - model.SpiMuxTargetRadioA)
+		s.radio[rfChain] = append(s.radio[rfChain], append([]byte(nil), w...))
+
+		resp := make([]byte, len(w))
+		if s.OnRadio != nil {
+			s.OnRadio(rfChain, w, resp)
+		}
+		copy(r, resp)
+		return nil
+	}
+
+	return fmt.Errorf("sx1302sim: unknown spi mux target 0x%02X", w[0])
+}
+
+// txSX1302 executes a register read or write of the SX1302
+func (s *Sim) txSX1302(w, r []byte) error {
+	if len(w) < 4 {
+		return fmt.Errorf("sx1302sim: truncated transaction of %d bytes", len(w))
+	}
+
+	addr := int(w[1]&0x7F)<<8 | int(w[2])
+	if w[1]&0x80 != 0 {
+		for i, b := range w[3:] {
+			s.write(uint16(addr+i), b)
+		}
+		return nil
+	}
+
+	// a read clocks out a dummy byte before the data
+	if len(w) < 5 {
+		return fmt.Errorf("sx1302sim: truncated read of %d bytes", len(w))
+	}
+
+	for i := range w[4:] {
+		var b byte
+		if addr == int(commands.RxBufferAddr) {
+			b = s.popRx()
+		} else {
+			b = s.read(uint16(addr + i))
+		}
+
+		if len(r) != 0 {
+			r[4+i] = b
+		}
+	}
+
+	return nil
+}
+
+// popRx returns the next byte of the RX buffer. The RX buffer is a FIFO at RxBufferAddr, every byte clocked out of a
+// read starting at RxBufferAddr pops it, whatever the length of the read.
+func (s *Sim) popRx() byte {
+	if len(s.rx) == 0 {
+		return 0
+	}
+
+	b := s.rx[0]
+	s.rx = s.rx[1:]
+	s.updateRxCount()
+	return b
+}
+
+// read returns the byte at addr
+func (s *Sim) read(addr uint16) byte {
+	addr %= addressSpace
+	return s.bank(s.page(), addr)[addr]
+}
+
+// write stores value at addr, keeping read-only bits and logging the write
+func (s *Sim) write(addr uint16, value byte) {
+	addr %= addressSpace
+	s.writes = append(s.writes, Write{Addr: addr, Value: value})
+
+	page := s.page()
+	mem := s.bank(page, addr)
+	ro := s.roMask[addr]
+	old := mem[addr]
+	mem[addr] = mem[addr]&ro | value&^ro
+
+	// the emulated logic sits behind the registers of page 0
+	if page == 0 || s.shared[addr] {
+		s.onWrite(addr, old)
+	}
+}
+
+// page returns the page selected through COMMON_PAGE_PAGE
+func (s *Sim) page() int8 {
+	return int8(s.reg(commands.RegCommonPagePage))
+}
+
+// bank returns the register file holding addr when page is selected
+func (s *Sim) bank(page int8, addr uint16) *[addressSpace]byte {
+	if page == 0 || page == commands.PageAll || s.shared[addr%addressSpace] {
+		return &s.mem
+	}
+
+	mem, ok := s.pages[page]
+	if !ok {
+		mem = new([addressSpace]byte)
+		s.pages[page] = mem
+	}
+
+	return mem
+}
+
+// onWrite emulates the side effects of a register write, old is the value at addr before the write
+func (s *Sim) onWrite(addr uint16, old byte) {
+	switch addr {
+	case regAddr(commands.RegOtpByteAddrAddr):
+		if s.mem[addr] == otpModelIDAddr {
+			s.setReg(commands.RegOtpRdDataRdData, int32(s.ModelID))
+		}
+
+	case regAddr(commands.RegAgcMcuCtrlMcuClear):
+		if s.reg(commands.RegAgcMcuCtrlMcuClear) == 0 && s.reg(commands.RegAgcMcuCtrlHostProg) == 0 {
+			// the firmware starts and publishes its version
+			image := s.mem[commands.AGCMemAddr : int(commands.AGCMemAddr)+commands.MCUMemSize]
+			s.calRun = len(s.CalFirmware) != 0 && bytes.Equal(image, s.CalFirmware)
+
+			version := s.AGCVersion
+			if s.calRun {
+				version = s.CalVersion
+			}
+			s.setReg(commands.RegAgcMcuMcuAgcStatusMcuAgcStatus, agcStatusStarted)
+			s.setReg(commands.RegAgcMcuMcuMailBoxRdDataByte0McuMailBoxRdData, int32(version))
+		} else {
+			s.setReg(commands.RegAgcMcuMcuAgcStatusMcuAgcStatus, 0)
+		}
+
+	case regAddr(commands.RegAgcMcuMcuMailBoxWrDataByte3McuMailBoxWrData):
+		if s.calRun {
+			s.calNotify(s.mem[addr])
+			return
+		}
+
+		step, ok := agcHandshake[s.mem[addr]]
+		if ok && s.mem[addr] == 0x0A && s.AGCVersion == commands.AGCFirmwareVersionSX125x {
+			step.from = 0x09
+		}
+
+		// a notification out of sequence is ignored and times out the handshake
+		if !ok || s.reg(commands.RegAgcMcuMcuAgcStatusMcuAgcStatus) != step.from {
+			return
+		}
+
+		// the firmware acknowledges the step and echoes the parameters it applied
+		for i := commands.RegID(0); i < 3; i++ {
+			value := s.reg(commands.RegAgcMcuMcuMailBoxWrDataByte0McuMailBoxWrData + i)
+			s.setReg(commands.RegAgcMcuMcuMailBoxRdDataByte0McuMailBoxRdData+i, value)
+		}
+		s.setReg(commands.RegAgcMcuMcuAgcStatusMcuAgcStatus, step.to)
+
+	case regAddr(commands.RegArbMcuCtrlMcuClear):
+		if s.reg(commands.RegArbMcuCtrlMcuClear) == 0 && s.reg(commands.RegArbMcuCtrlHostProg) == 0 {
+			s.setReg(commands.RegArbMcuMcuArbStatusMcuArbStatus, arbStatusStarted)
+			s.setReg(commands.RegArbMcuArbDebugSts0ArbDebugSts0, int32(s.ARBVersion))
+		} else {
+			s.setReg(commands.RegArbMcuMcuArbStatusMcuArbStatus, 0)
+		}
+
+	case regAddr(commands.RegArbMcuArbDebugCfg1ArbDebugCfg1):
+		if s.mem[addr] == 1 {
+			s.setReg(commands.RegArbMcuMcuArbStatusMcuArbStatus, 0)
+		}
+
+	case regAddr(commands.RegTxTopATxTrigTxTrigImmediate):
+		s.txTrigger(commands.RegTxTopATxFsmStatusTxStatus, s.mem[addr]&^old)
+
+	case regAddr(commands.RegTxTopBTxTrigTxTrigImmediate):
+		s.txTrigger(commands.RegTxTopBTxFsmStatusTxStatus, s.mem[addr]&^old)
+	}
+}
+
+// txTrigger moves the TX state machine reporting in status on the trigger bits set by a write. The emission never
+// ends on its own, the status is set back to free through SetReg.
+func (s *Sim) txTrigger(status commands.RegID, set byte) {
+	switch {
+	case set&txTrigImmediate != 0:
+		s.setReg(status, txStatusEmitting)
+	case set&txTrigDelayed != 0:
+		s.setReg(status, txStatusDelayed)
+	case set&txTrigGps != 0:
+		s.setReg(status, txStatusGps)
+	}
+}
+
+// calNotify runs the calibration or reads back one of its results when notified by the host
+func (s *Sim) calNotify(notify byte) {
+	status := s.reg(commands.RegAgcMcuMcuAgcStatusMcuAgcStatus)
+	switch {
+	case notify == calNotifyStart && status == agcStatusStarted:
+		s.calCmd = s.reg(commands.RegAgcMcuMcuMailBoxWrDataByte0McuMailBoxWrData)
+
+		status = calStatusFinished | calStatusRegAccess
+		for i := 0; i < int(model.MaxRfChains); i++ {
+			if s.calCmd&(calCmdRx<<i) != 0 {
+				status |= (calStatusRadio | calStatusRx) << i
+			}
+			if s.calCmd&(calCmdTx<<i) != 0 {
+				status |= calStatusTx << i
+			}
+		}
+		s.setReg(commands.RegAgcMcuMcuAgcStatusMcuAgcStatus, status&^int32(s.CalFailures))
+
+	case notify == calNotifyRead && status&calStatusFinished != 0:
+		rfChain := s.reg(commands.RegAgcMcuMcuMailBoxWrDataByte0McuMailBoxWrData)
+		index := s.reg(commands.RegAgcMcuMcuMailBoxWrDataByte1McuMailBoxWrData)
+		if rfChain >= int32(model.MaxRfChains) || index > commands.CalMixGains {
+			return
+		}
+
+		result := s.CalResults[rfChain]
+		offset := result.Rx
+		if index > 0 {
+			offset = result.Tx[index-1]
+		}
+
+		s.setReg(commands.RegAgcMcuMcuMailBoxRdDataByte0McuMailBoxRdData, int32(uint8(offset.I)))
+		s.setReg(commands.RegAgcMcuMcuMailBoxRdDataByte1McuMailBoxRdData, int32(uint8(offset.Q)))
+		s.setReg(commands.RegAgcMcuMcuMailBoxRdDataByte2McuMailBoxRdData, rfChain)
+		s.setReg(commands.RegAgcMcuMcuMailBoxRdDataByte3McuMailBoxRdData, index|calReadValid)
+	}
+}
+
+// CalCommand returns the calibration command received by the emulated calibration firmware since the last reset
+func (s *Sim) CalCommand() uint8 {
+	s.Lock()
+	defer s.Unlock()
+
+	return uint8(s.calCmd)
+}
+
+// reset puts the register file back to its reset value
+func (s *Sim) reset() {
+	s.mem = [addressSpace]byte{}
+	s.pages = make(map[int8]*[addressSpace]byte)
+	s.rx = nil
+	s.calRun = false
+	s.calCmd = 0
+
+	for id, reg := range commands.RegisterMap() {
+		if reg.Default != 0 {
+			s.setReg(commands.RegID(id), reg.Default)
+		}
+	}
+}
+
+// updateRxCount reflects the number of bytes in the RX buffer in the RX_BUFFER_NB_BYTES registers
+func (s *Sim) updateRxCount() {
+	n := min(len(s.rx), commands.RxBufferSize)
+	s.setReg(commands.RegRxTopRxBufferNbBytesMsbRxBufferNbBytes, int32(n>>8))
+	s.setReg(commands.RegRxTopRxBufferNbBytesLsbRxBufferNbBytes, int32(n&0xFF))
+}
+
+// reg decodes the value of register id from the register file
+func (s *Sim) reg(id commands.RegID) int32 {
+	reg := mustRegister(id)
+	mem := s.bank(reg.Page, reg.Addr)
+
+	var raw uint32
+	for i := len(fieldMasks(reg)) - 1; i >= 0; i-- {
+		raw = raw<<8 | uint32(mem[int(reg.Addr)+i])
+	}
+
+	raw = raw >> reg.Offs & (1<<reg.Leng - 1)
+	if reg.Sign && raw&(1<<(reg.Leng-1)) != 0 {
+		raw |= ^uint32(0) << reg.Leng
+	}
+
+	return int32(raw)
+}
+
+// setReg encodes value into the register file without side effects
+func (s *Sim) setReg(id commands.RegID, value int32) {
+	reg := mustRegister(id)
+	mem := s.bank(reg.Page, reg.Addr)
+
+	raw := (uint32(value) & (1<<reg.Leng - 1)) << reg.Offs
+	for i, mask := range fieldMasks(reg) {
+		addr := int(reg.Addr) + i
+		mem[addr] = mem[addr]&^mask | byte(raw>>(8*i))&mask
+	}
+}
+
+// fieldMasks returns the bit mask of the register for every byte it spans
+func fieldMasks(reg commands.Register) []byte {
+	field := uint64(1<<reg.Leng-1) << reg.Offs
+	masks := make([]byte, (int(reg.Offs)+int(reg.Leng)+7)/8)
+	for i := range masks {
+		masks[i] = byte(field >> (8 * i))
+	}
+
+	return masks
+}
+
+// regAddr returns the address of register id
+func regAddr(id commands.RegID) uint16 {
+	return mustRegister(id).Addr
+}
+
+// mustRegister returns the description of register id and panics for an unknown id
+func mustRegister(id commands.RegID) commands.Register {
+	reg, err := id.Register()
+	if err != nil {
+		panic(err)
+	}
+
+	return reg
+}
+
+// resetPin resets the simulator when the reset is released
+type resetPin struct {
+	gpiotest.Pin
+	sim *Sim
+}
+
+// Out implements gpio.PinOut
+func (p *resetPin) Out(l gpio.Level) error {
+	released := p.Read() == gpio.High && l == gpio.Low
+	if err := p.Pin.Out(l); err != nil {
+		return err
+	}
+
+	if released {
+		p.sim.Reset()
+	}
+
+	return nil
+}
+
+var _ spi.PortCloser = &Sim{}
+var _ spi.Conn = &Sim{}
+var _ conn.Limits = &Sim{}
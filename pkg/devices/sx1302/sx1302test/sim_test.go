@@ -0,0 +1,125 @@
+package sx1302test_test
+
+import (
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// newLowLevel connects a LowLevel to a new simulator
+func newLowLevel(t *testing.T) (*commands.LowLevel, *sx1302test.Sim) {
+	t.Helper()
+
+	sim := sx1302test.NewSim()
+	ll, err := commands.NewLowLevelSPI(sim, commands.Pins{Reset: sim.ResetPin()})
+	if err != nil {
+		t.Fatalf("NewLowLevelSPI() failed: %v", err)
+	}
+
+	return ll, sim
+}
+
+func TestSimRegisterRoundTrip(t *testing.T) {
+	ll, sim := newLowLevel(t)
+	regs := commands.NewRegisters(ll)
+
+	tests := []struct {
+		id    commands.RegID
+		value int32
+	}{
+		{commands.RegRadioFeCtrl0RadioAHostFilterGain, 0x0B},
+		{commands.RegRxTopFreq0MsbIfFreq0, -3},
+		{commands.RegRxTopFskCfg0Psize, 2},
+	}
+
+	for _, tt := range tests {
+		if err := regs.RegWrite(tt.id, tt.value); err != nil {
+			t.Fatalf("RegWrite(%d, %d) failed: %v", tt.id, tt.value, err)
+		}
+
+		if got := sim.Reg(tt.id); got != tt.value {
+			t.Errorf("simulator register %d = %d, want %d", tt.id, got, tt.value)
+		}
+
+		got, err := regs.RegRead(tt.id)
+		if err != nil {
+			t.Fatalf("RegRead(%d) failed: %v", tt.id, err)
+		}
+
+		if got != tt.value {
+			t.Errorf("RegRead(%d) = %d, want %d", tt.id, got, tt.value)
+		}
+	}
+
+	// read-only registers keep their value
+	sim.SetReg(commands.RegOtpRdDataRdData, 0x5A)
+	if err := regs.RegWrite(commands.RegOtpRdDataRdData, 0x00); err == nil {
+		t.Error("RegWrite() accepted a write to a read-only register")
+	}
+
+	otp, err := commands.RegOtpRdDataRdData.Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ll.DevWrite(model.SpiMuxTargetSX1302, otp.Addr, 0x00); err != nil {
+		t.Fatalf("DevWrite() failed: %v", err)
+	}
+
+	if got := sim.Reg(commands.RegOtpRdDataRdData); got != 0x5A {
+		t.Errorf("read-only register = 0x%02X, want 0x5A", got)
+	}
+}
+
+func TestSimPages(t *testing.T) {
+	ll, sim := newLowLevel(t)
+	regs := commands.NewRegisters(ll)
+
+	const addr = 0x0100
+	write := func(page int32, value byte) {
+		t.Helper()
+
+		if err := regs.RegWrite(commands.RegCommonPagePage, page); err != nil {
+			t.Fatalf("RegWrite(COMMON_PAGE_PAGE, %d) failed: %v", page, err)
+		}
+
+		if err := ll.DevWrite(model.SpiMuxTargetSX1302, addr, value); err != nil {
+			t.Fatalf("DevWrite() failed: %v", err)
+		}
+	}
+
+	write(0, 0xA0)
+	write(1, 0xA1)
+	write(2, 0xA2)
+
+	if got := sim.Page(); got != 2 {
+		t.Errorf("Page() = %d, want 2", got)
+	}
+
+	for page, want := range []byte{0xA0, 0xA1, 0xA2} {
+		if got := sim.PageMem(int8(page), addr, 1)[0]; got != want {
+			t.Errorf("page %d holds 0x%02X, want 0x%02X", page, got, want)
+		}
+	}
+
+	// the page register itself is reachable from every page
+	got, err := ll.DevRead(model.SpiMuxTargetSX1302, 0x5600)
+	if err != nil {
+		t.Fatalf("DevRead() failed: %v", err)
+	}
+
+	if got != 2 {
+		t.Errorf("COMMON_PAGE_PAGE read from page 2 = %d, want 2", got)
+	}
+
+	// a register of page 0 is reached again after switching back
+	if err := regs.RegWrite(commands.RegRadioFeCtrl0RadioAHostFilterGain, 0x0B); err != nil {
+		t.Fatalf("RegWrite() failed: %v", err)
+	}
+
+	if sim.Page() != 0 || sim.Reg(commands.RegRadioFeCtrl0RadioAHostFilterGain) != 0x0B {
+		t.Errorf("RegWrite() of a page 0 register on page 2 did not switch back to page 0")
+	}
+}
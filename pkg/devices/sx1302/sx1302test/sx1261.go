@@ -0,0 +1,136 @@
+package sx1302test
+
+import (
+	"fmt"
+	"sync"
+
+	"periph.io/x/conn/v3"
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1261"
+)
+
+// Registers and modes of the SX1261 known to the emulation
+const (
+	sx1261RegPatchVersion       = 0x0153
+	sx1261ModeStandbyRC   uint8 = 0x02
+	sx1261ModeFS          uint8 = 0x04
+	sx1261ModeRX          uint8 = 0x05
+	sx1261ModeLBT         uint8 = 0x06
+)
+
+// SX1261 emulates the command interface of a SX1261 attached to its own SPI port. Every byte clocked out after the
+// opcode is the status byte, register reads return the register file. Applying a firmware patch publishes
+// PatchVersion.
+type SX1261 struct {
+	sync.Mutex
+
+	// PatchVersion is reported once a firmware patch was applied
+	PatchVersion string
+
+	mode   uint8
+	regs   [0x10000]byte
+	frames [][]byte
+}
+
+// Frames returns the SPI frames received by the radio
+func (r *SX1261) Frames() [][]byte {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([][]byte(nil), r.frames...)
+}
+
+// String implements conn.Resource
+func (r *SX1261) String() string {
+	return "sx1261sim"
+}
+
+// Close implements spi.PortCloser
+func (r *SX1261) Close() error {
+	return nil
+}
+
+// LimitSpeed implements spi.PortCloser
+func (r *SX1261) LimitSpeed(f physic.Frequency) error {
+	return nil
+}
+
+// Connect implements spi.Port
+func (r *SX1261) Connect(f physic.Frequency, mode spi.Mode, bits int) (spi.Conn, error) {
+	return r, nil
+}
+
+// Duplex implements conn.Conn
+func (r *SX1261) Duplex() conn.Duplex {
+	return conn.Full
+}
+
+// TxPackets implements spi.Conn
+func (r *SX1261) TxPackets(packets []spi.Packet) error {
+	for _, p := range packets {
+		if err := r.Tx(p.W, p.R); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Tx implements conn.Conn
+func (r *SX1261) Tx(w, read []byte) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if len(w) < 1 {
+		return fmt.Errorf("sx1261sim: empty transaction")
+	}
+
+	if len(read) != 0 && len(read) != len(w) {
+		return fmt.Errorf("sx1261sim: read buffer of %d bytes for a write of %d bytes", len(read), len(w))
+	}
+
+	r.frames = append(r.frames, append([]byte(nil), w...))
+	if r.mode == 0 {
+		r.mode = sx1261ModeStandbyRC
+	}
+
+	resp := make([]byte, len(w))
+	for i := 1; i < len(resp); i++ {
+		resp[i] = r.mode << 4
+	}
+
+	params := w[1:]
+	switch sx1261.Opcode(w[0]) {
+	case sx1261.OpcodeReadRegister:
+		if len(params) > 3 {
+			address := uint16(params[0])<<8 | uint16(params[1])
+			for i := range params[3:] {
+				resp[4+i] = r.regs[address+uint16(i)]
+			}
+		}
+
+	case sx1261.OpcodeWriteRegister:
+		if len(params) > 2 {
+			address := uint16(params[0])<<8 | uint16(params[1])
+			copy(r.regs[address:], params[2:])
+		}
+
+	case sx1261.OpcodePatchUpdate:
+		copy(r.regs[sx1261RegPatchVersion:], r.PatchVersion)
+
+	case sx1261.OpcodeSetStandby:
+		r.mode = sx1261ModeStandbyRC
+	case sx1261.OpcodeSetFs:
+		r.mode = sx1261ModeFS
+	case sx1261.OpcodeSetRx:
+		r.mode = sx1261ModeRX
+	}
+
+	copy(read, resp)
+	return nil
+}
+
+var _ spi.PortCloser = &SX1261{}
+var _ spi.Conn = &SX1261{}
@@ -0,0 +1,574 @@
+package sx1302
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+const (
+	// txStartDelay is the delay in microseconds between the trigger of the TX state machine and the start of the
+	// emission. Timestamped packets are triggered txStartDelay before CountUs.
+	txStartDelay uint32 = 1500
+
+	// txMarginDelay is the time in microseconds needed to load and arm a timestamped packet
+	txMarginDelay uint32 = 1000
+
+	// txMaxAdvance is the longest time in microseconds a packet can be scheduled ahead, it stays clear of the wrap
+	// of the 32MHz trigger counter after ~134s
+	txMaxAdvance uint32 = 120000000
+
+	// txClockFreq is the clock of the TX modulators, the frequency registers count in steps of txClockFreq / 2^18
+	txClockFreq uint64 = 32000000
+
+	// txPollInterval is the interval the TX state machines are polled at while waiting for an emission
+	txPollInterval = 10 * time.Millisecond
+)
+
+// Preamble lengths of the reference HAL, in symbols for LoRa and bytes for FSK
+const (
+	loraPreambleStd uint16 = 8
+	loraPreambleMin uint16 = 6
+	fskPreambleStd  uint16 = 5
+	fskPreambleMin  uint16 = 3
+)
+
+// TX modem settings, selecting the modem feeding the radio
+const (
+	txModulationLoRa int32 = 0x00
+	txModulationFSK  int32 = 0x01
+	txIfSrcLoRa      int32 = 0x01
+	txIfSrcFSK       int32 = 0x02
+)
+
+// txFsmStatusRegs are the TX state machine status registers of the RF chains
+var txFsmStatusRegs = [model.MaxRfChains]commands.RegID{
+	commands.RegTxTopATxFsmStatusTxStatus,
+	commands.RegTxTopBTxFsmStatusTxStatus,
+}
+
+// txChainOffset is the distance of the TX_TOP_B registers to the identical TX_TOP_A registers
+const txChainOffset = commands.RegTxTopBTxTrigTxTrigImmediate - commands.RegTxTopATxTrigTxTrigImmediate
+
+// TxTooLateError is returned by Send if a timestamped packet cannot be armed before its time
+type TxTooLateError struct {
+	CountUs uint64
+	Now     uint64
+}
+
+func (e *TxTooLateError) Error() string {
+	return fmt.Sprintf("too late to send the packet at %d us, the counter is at %d us", e.CountUs, e.Now)
+}
+
+// TxTooEarlyError is returned by Send if a timestamped packet is scheduled too far ahead
+type TxTooEarlyError struct {
+	CountUs uint64
+	Now     uint64
+}
+
+func (e *TxTooEarlyError) Error() string {
+	return fmt.Sprintf("too early to send the packet at %d us, the counter is at %d us", e.CountUs, e.Now)
+}
+
+// TxBusyError is returned by Send if the RF chain is emitting or has scheduled another packet
+type TxBusyError struct {
+	RfChain uint8
+	Status  int32
+}
+
+func (e *TxBusyError) Error() string {
+	return fmt.Sprintf("rf chain %d is busy, tx status 0x%02X", e.RfChain, e.Status)
+}
+
+// TxResult is the outcome of a packet sent with SendNotify
+type TxResult struct {
+	RfChain uint8
+
+	// RfPower is the power of the TX gain LUT entry used for the packet, in dBm
+	RfPower int8
+
+	// StartUs is the counter value in microseconds at which the emission started, StartUs64 its extended value
+	StartUs   uint32
+	StartUs64 uint64
+
+	// Err is set if the end of the emission could not be observed, e.g. because the concentrator was stopped
+	Err error
+}
+
+// Send loads pkt into the TX buffer of its RF chain and arms the TX state machine. Depending on TxMode the packet is
+// sent immediately, when the counter reaches CountUs64 (CountUs if CountUs64 is 0) or on the next GPS PPS. The TX
+// gain is the entry of the TX gain LUT of the RF chain with the highest power at or below RfPower, or the lowest
+// entry if RfPower is below all entries.
+//
+// Send returns a *TxBusyError if the RF chain is emitting or has a packet scheduled, and a *TxTooLateError or
+// *TxTooEarlyError if a timestamped packet cannot be scheduled.
+func (d *Dev) Send(pkt model.PktTx) error {
+	_, _, err := d.send(pkt)
+	return err
+}
+
+// SendNotify sends pkt like Send and delivers the outcome on the returned channel once the RF chain finished the
+// emission, then the channel is closed. The result carries the counter value the emission started at: CountUs64 for
+// timestamped packets, otherwise the counter at the trigger, respectively the counter latched on the PPS, plus the
+// start delay of the TX state machine.
+//
+// The RF chain is polled until the emission finished, ctx is done or the concentrator is stopped.
+func (d *Dev) SendNotify(ctx context.Context, pkt model.PktTx) (<-chan TxResult, error) {
+	startUs, rfPower, err := d.send(pkt)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan TxResult, 1)
+	go func() {
+		defer close(ch)
+		ch <- d.waitTx(ctx, pkt, TxResult{RfChain: pkt.RfChain, RfPower: rfPower, StartUs: uint32(startUs), StartUs64: startUs})
+	}()
+
+	return ch, nil
+}
+
+// TxStatus returns the status of the TX state machine of rfChain
+func (d *Dev) TxStatus(rfChain uint8) (model.TxStatus, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.context.IsStarted {
+		return model.TxStatusUnknown, errors.New("concentrator is not started")
+	}
+
+	if err := d.checkTxChain(rfChain); err != nil {
+		return model.TxStatusUnknown, err
+	}
+
+	d.regMu.Lock()
+	defer d.regMu.Unlock()
+
+	status, err := d.regs.RegRead(txFsmStatusRegs[rfChain])
+	if err != nil {
+		return model.TxStatusUnknown, fmt.Errorf("failed to read the tx status of rf chain %d: %w", rfChain, err)
+	}
+
+	return txStatus(status), nil
+}
+
+// send sends pkt and returns the counter value in microseconds the emission starts at, 0 if it is triggered by the PPS,
+// and the power of the TX gain used
+func (d *Dev) send(pkt model.PktTx) (uint64, int8, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.context.IsStarted {
+		return 0, 0, errors.New("concentrator is not started")
+	}
+
+	if err := d.checkTxPacket(&pkt); err != nil {
+		return 0, 0, err
+	}
+
+	d.regMu.Lock()
+	defer d.regMu.Unlock()
+
+	status, err := d.regs.RegRead(txFsmStatusRegs[pkt.RfChain])
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read the tx status of rf chain %d: %w", pkt.RfChain, err)
+	}
+
+	switch txStatus(status) {
+	case model.TxStatusScheduled, model.TxStatusEmitting:
+		return 0, 0, &TxBusyError{RfChain: pkt.RfChain, Status: status}
+	}
+
+	var startUs uint64
+	switch pkt.TxMode {
+	case model.TxModeTimestamped:
+		if err := d.checkTxTime(&pkt); err != nil {
+			return 0, 0, err
+		}
+		startUs = pkt.CountUs64
+
+	case model.TxModeImmediate:
+		// read before the trigger, the few register writes in between are negligible to the start delay
+		now, err := d.counterUs()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read the timestamp counter: %w", err)
+		}
+		startUs = now + uint64(txStartDelay)
+	}
+
+	gain, ok := d.context.TxGainLUT[pkt.RfChain].Select(pkt.RfPower)
+	if !ok {
+		log.WithFields(log.Fields{
+			"rf_chain":  pkt.RfChain,
+			"requested": pkt.RfPower,
+			"rf_power":  gain.RfPower,
+		}).Warn("Requested tx power is below the tx gain lut, sending with its lowest power")
+	}
+
+	if d.lbtEnabled() {
+		if err := d.lbtStart(pkt); err != nil {
+			return 0, 0, fmt.Errorf("listen-before-talk: %w", err)
+		}
+	}
+
+	if err := d.configureTx(pkt, gain); err != nil {
+		return 0, 0, fmt.Errorf("failed to configure rf chain %d: %w", pkt.RfChain, err)
+	}
+
+	if err := d.loadTxBuffer(pkt.RfChain, pkt.Payload[:pkt.Size]); err != nil {
+		return 0, 0, fmt.Errorf("failed to load the tx buffer of rf chain %d: %w", pkt.RfChain, err)
+	}
+
+	if err := d.triggerTx(pkt); err != nil {
+		return 0, 0, fmt.Errorf("failed to trigger rf chain %d: %w", pkt.RfChain, err)
+	}
+
+	return startUs, gain.RfPower, nil
+}
+
+// waitTx polls the RF chain of pkt until its emission finished. Packets triggered by the PPS take the start counter
+// from the PPS latch as soon as they left the scheduled state.
+func (d *Dev) waitTx(ctx context.Context, pkt model.PktTx, result TxResult) TxResult {
+	latchPPS := pkt.TxMode == model.TxModeOnGPS
+
+	ticker := time.NewTicker(txPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			result.Err = ctx.Err()
+			return result
+		case <-ticker.C:
+		}
+
+		status, err := d.TxStatus(pkt.RfChain)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+
+		if latchPPS && (status == model.TxStatusEmitting || status == model.TxStatusFree) {
+			ppsUs, err := d.PPSCounterUs()
+			if err != nil {
+				result.Err = fmt.Errorf("failed to read the pps counter: %w", err)
+				return result
+			}
+			result.StartUs64 = ppsUs + uint64(txStartDelay)
+			result.StartUs = uint32(result.StartUs64)
+			latchPPS = false
+		}
+
+		if status == model.TxStatusFree {
+			return result
+		}
+	}
+}
+
+// checkTxPacket validates pkt against the configuration and sets the default preamble
+func (d *Dev) checkTxPacket(pkt *model.PktTx) error {
+	if err := d.checkTxChain(pkt.RfChain); err != nil {
+		return err
+	}
+
+	switch pkt.TxMode {
+	case model.TxModeImmediate, model.TxModeTimestamped, model.TxModeOnGPS:
+	default:
+		return fmt.Errorf("invalid tx mode %d", pkt.TxMode)
+	}
+
+	if pkt.Size > 255 {
+		return fmt.Errorf("invalid payload size %d, must be at most 255 bytes", pkt.Size)
+	}
+
+	switch pkt.Modulation {
+	case model.ModLora:
+		switch model.Bandwith(pkt.Bandwidth) {
+		case model.Bw125kHz, model.Bw250kHz, model.Bw500kHz:
+		default:
+			return fmt.Errorf("invalid lora bandwidth 0x%02X", pkt.Bandwidth)
+		}
+
+		if model.DataRate(pkt.Datarate) < model.DrLoraSf5 || model.DataRate(pkt.Datarate) > model.DrLoraSf12 {
+			return fmt.Errorf("invalid lora datarate %d", pkt.Datarate)
+		}
+
+		if pkt.Coderate < model.CrLora45 || pkt.Coderate > model.CrLora48 {
+			return fmt.Errorf("invalid lora coding rate %d", pkt.Coderate)
+		}
+
+		if pkt.Preamble == 0 {
+			pkt.Preamble = loraPreambleStd
+		}
+		pkt.Preamble = max(pkt.Preamble, loraPreambleMin)
+
+	case model.ModFsk:
+		if model.DataRate(pkt.Datarate) < model.DrFskMin || model.DataRate(pkt.Datarate) > model.DrFskMax {
+			return fmt.Errorf("invalid fsk datarate %s, must be between %s and %s", model.DataRate(pkt.Datarate), model.DrFskMin, model.DrFskMax)
+		}
+
+		if pkt.FDev == 0 {
+			return errors.New("invalid fsk frequency deviation of 0kHz")
+		}
+
+		if pkt.Preamble == 0 {
+			pkt.Preamble = fskPreambleStd
+		}
+		pkt.Preamble = max(pkt.Preamble, fskPreambleMin)
+
+	default:
+		return fmt.Errorf("unsupported modulation 0x%02X", pkt.Modulation)
+	}
+
+	return nil
+}
+
+// checkTxChain checks that rfChain is enabled for TX
+func (d *Dev) checkTxChain(rfChain uint8) error {
+	if int(rfChain) >= len(d.context.RfChainCfg) || !d.context.RfChainCfg[rfChain].Enable {
+		return fmt.Errorf("rf chain %d is not enabled", rfChain)
+	}
+
+	if !d.context.RfChainCfg[rfChain].TxEnable {
+		return fmt.Errorf("rf chain %d is not enabled for tx", rfChain)
+	}
+
+	return nil
+}
+
+// checkTxTime checks that the timestamped pkt can be armed in time and is not scheduled too far ahead. It sets both
+// CountUs and CountUs64, a CountUs without CountUs64 is taken as the closest counter value.
+func (d *Dev) checkTxTime(pkt *model.PktTx) error {
+	now, err := d.counterUs()
+	if err != nil {
+		return fmt.Errorf("failed to read the timestamp counter: %w", err)
+	}
+
+	if pkt.CountUs64 == 0 {
+		pkt.CountUs64 = model.ExtendCountUs(now, pkt.CountUs)
+	}
+	pkt.CountUs = uint32(pkt.CountUs64)
+
+	switch {
+	case pkt.CountUs64 < now+uint64(txStartDelay+txMarginDelay):
+		return &TxTooLateError{CountUs: pkt.CountUs64, Now: now}
+	case pkt.CountUs64 > now+uint64(txMaxAdvance):
+		return &TxTooEarlyError{CountUs: pkt.CountUs64, Now: now}
+	}
+
+	return nil
+}
+
+// configureTx writes gain and the frequency and modulation settings of pkt to the TX registers of its RF chain
+func (d *Dev) configureTx(pkt model.PktTx, gain model.TXGain) error {
+	rf := d.context.RfChainCfg[pkt.RfChain]
+
+	seq := []regWrite{
+		{commands.RegTxTopAAgcTxBwAgcTxPaGain, int32(gain.PaGain)},
+		{commands.RegTxTopATxRffeIfIqGainIqGain, int32(gain.DigGain)},
+	}
+
+	freqHz := pkt.FreqHz
+	switch rf.Type {
+	case model.RadioTypeSX1250:
+		seq = append(seq, regWrite{commands.RegTxTopAAgcTxPwrAgcDriveTxPwr, int32(gain.PwrIdx)})
+
+	case model.RadioTypeSX1255, model.RadioTypeSX1257:
+		seq = append(seq,
+			regWrite{commands.RegTxTopAAgcTxPwrAgcDriveTxPwr, int32(gain.DacGain)<<4 | int32(gain.MixGain)},
+			regWrite{commands.RegTxTopATxRffeIfIOffsetIOffset, int32(gain.OffsetI)},
+			regWrite{commands.RegTxTopATxRffeIfQOffsetQOffset, int32(gain.OffsetQ)},
+		)
+
+		// the SX1255 runs the TX synthesizer at twice the frequency
+		if rf.Type == model.RadioTypeSX1255 {
+			freqHz *= 2
+		}
+
+	default:
+		return fmt.Errorf("unsupported radio type %s", rf.Type)
+	}
+
+	freq := txFreqToReg(freqHz)
+	seq = append(seq,
+		regWrite{commands.RegTxTopATxRffeIfFreqRfHFreqRf, int32(freq >> 16 & 0xFF)},
+		regWrite{commands.RegTxTopATxRffeIfFreqRfMFreqRf, int32(freq >> 8 & 0xFF)},
+		regWrite{commands.RegTxTopATxRffeIfFreqRfLFreqRf, int32(freq & 0xFF)},
+		regWrite{commands.RegTxTopATxStartDelayMsbTxStartDelay, int32(txStartDelay * 32 >> 8 & 0xFF)},
+		regWrite{commands.RegTxTopATxStartDelayLsbTxStartDelay, int32(txStartDelay * 32 & 0xFF)},
+	)
+
+	if pkt.Modulation == model.ModLora {
+		seq = append(seq, d.loraTxRegs(pkt)...)
+	} else {
+		seq = append(seq, d.fskTxRegs(pkt)...)
+	}
+
+	return d.writeTxRegs(pkt.RfChain, seq)
+}
+
+// loraTxRegs returns the settings of the LoRa modulator for pkt
+func (d *Dev) loraTxRegs(pkt model.PktTx) []regWrite {
+	peak1, peak2 := syncWordPeak1Private, syncWordPeak2Private
+	if d.context.BoardConfig.LoRaWanPublic {
+		peak1, peak2 = syncWordPeak1Public, syncWordPeak2Public
+	}
+
+	sf := model.DataRate(pkt.Datarate)
+	bw := model.Bandwith(pkt.Bandwidth)
+
+	// low datarate optimisation for symbols of 16ms and longer
+	ppmOffset := bw == model.Bw125kHz && sf >= model.DrLoraSf11 || bw == model.Bw250kHz && sf == model.DrLoraSf12
+
+	return []regWrite{
+		{commands.RegTxTopATxrxCfg00ModemBw, int32(pkt.Bandwidth)},
+		{commands.RegTxTopATxrxCfg00ModemSf, int32(pkt.Datarate)},
+		{commands.RegTxTopATxrxCfg01CodingRate, int32(pkt.Coderate)},
+		{commands.RegTxTopATxrxCfg01PpmOffset, boolToReg(ppmOffset)},
+		{commands.RegTxTopATxrxCfg11PreambleSymbNbMsb, int32(pkt.Preamble >> 8)},
+		{commands.RegTxTopATxrxCfg12PreambleSymbNbLsb, int32(pkt.Preamble & 0xFF)},
+		{commands.RegTxTopATxrxCfg02FineSynchEn, boolToReg(sf <= model.DrLoraSf6)},
+		{commands.RegTxTopATxrxCfg02ImplicitHeader, boolToReg(pkt.NoHeader)},
+		{commands.RegTxTopATxrxCfg02CrcEn, boolToReg(!pkt.NoCrc)},
+		{commands.RegTxTopATxrxCfg03PayloadLength, int32(pkt.Size)},
+		{commands.RegTxTopATxrxCfg10InvertIq, boolToReg(pkt.InvertPol)},
+		{commands.RegTxTopAFrameSynch0Peak1Pos, peak1},
+		{commands.RegTxTopAFrameSynch1Peak2Pos, peak2},
+		{commands.RegTxTopAGenCfg0ModulationType, txModulationLoRa},
+		{commands.RegTxTopATxRffeIfCtrlTxIfSrc, txIfSrcLoRa},
+	}
+}
+
+// fskTxRegs returns the settings of the FSK modulator for pkt, using the sync word of the FSK channel
+func (d *Dev) fskTxRegs(pkt model.PktTx) []regWrite {
+	conf := d.context.FSKCfg
+	bitRate := fskSampleRate / pkt.Datarate
+	fdev := txFreqToReg(uint32(pkt.FDev) * 1000)
+
+	pktMode := fskPktModeVariable
+	if pkt.NoHeader {
+		pktMode = 0
+	}
+
+	seq := []regWrite{
+		{commands.RegTxTopAFskCfg0PktMode, pktMode},
+		{commands.RegTxTopAFskCfg0CrcEn, boolToReg(!pkt.NoCrc)},
+		{commands.RegTxTopAFskCfg0CrcIbm, 0},
+		{commands.RegTxTopAFskCfg0DcfreeEnc, fskDcfreeWhitening},
+		{commands.RegTxTopAFskCfg0Psize, int32(conf.SyncWordSize - 1)},
+		{commands.RegTxTopAFskPreambleSizeMsbPreambleSize, int32(pkt.Preamble >> 8)},
+		{commands.RegTxTopAFskPreambleSizeLsbPreambleSize, int32(pkt.Preamble & 0xFF)},
+		{commands.RegTxTopAFskBitRateMsbBitRate, int32(bitRate >> 8 & 0xFF)},
+		{commands.RegTxTopAFskBitRateLsbBitRate, int32(bitRate & 0xFF)},
+		{commands.RegTxTopATxRffeIfFreqDevHFreqDev, int32(fdev >> 8 & 0xFF)},
+		{commands.RegTxTopATxRffeIfFreqDevLFreqDev, int32(fdev & 0xFF)},
+		{commands.RegTxTopAFskPktLenPktLen, int32(pkt.Size)},
+		{commands.RegTxTopAGenCfg0ModulationType, txModulationFSK},
+		{commands.RegTxTopATxRffeIfCtrlTxIfSrc, txIfSrcFSK},
+	}
+
+	// the sync word is aligned left in the 8 byte reference pattern
+	pattern := conf.SyncWord << (8 * (8 - uint64(conf.SyncWordSize)))
+	for i := commands.RegID(0); i < 8; i++ {
+		seq = append(seq, regWrite{commands.RegTxTopAFskModFskRefPatternByte0FskRefPattern + i, int32(pattern >> (8 * i) & 0xFF)})
+	}
+
+	return seq
+}
+
+// loadTxBuffer writes payload to the TX buffer of rfChain. An empty payload leaves the buffer untouched
+func (d *Dev) loadTxBuffer(rfChain uint8, payload []byte) error {
+	addr := commands.TxBufferAddrA
+	if rfChain == 1 {
+		addr = commands.TxBufferAddrB
+	}
+
+	if err := d.regs.RegWrite(txReg(rfChain, commands.RegTxTopATxCtrlWriteBuffer), 1); err != nil {
+		return err
+	}
+
+	if len(payload) > 0 {
+		if err := d.com.DevWriteBurst(model.SpiMuxTargetSX1302, addr, payload); err != nil {
+			return err
+		}
+	}
+
+	return d.regs.RegWrite(txReg(rfChain, commands.RegTxTopATxCtrlWriteBuffer), 0)
+}
+
+// triggerTx arms the TX state machine of the RF chain of pkt in its TX mode
+func (d *Dev) triggerTx(pkt model.PktTx) error {
+	var seq []regWrite
+	switch pkt.TxMode {
+	case model.TxModeImmediate:
+		seq = []regWrite{
+			{commands.RegTxTopATxTrigTxTrigImmediate, 0},
+			{commands.RegTxTopATxTrigTxTrigImmediate, 1},
+		}
+
+	case model.TxModeTimestamped:
+		// the trigger compares with the 32MHz counter, the wraps of the microsecond counter shift out
+		trig := pkt.CountUs*32 - txStartDelay*32
+		seq = []regWrite{
+			{commands.RegTxTopATimerTrigByte3TimerDelayedTrig, int32(trig >> 24 & 0xFF)},
+			{commands.RegTxTopATimerTrigByte2TimerDelayedTrig, int32(trig >> 16 & 0xFF)},
+			{commands.RegTxTopATimerTrigByte1TimerDelayedTrig, int32(trig >> 8 & 0xFF)},
+			{commands.RegTxTopATimerTrigByte0TimerDelayedTrig, int32(trig & 0xFF)},
+			{commands.RegTxTopATxTrigTxTrigDelayed, 0},
+			{commands.RegTxTopATxTrigTxTrigDelayed, 1},
+		}
+
+	case model.TxModeOnGPS:
+		seq = []regWrite{
+			{commands.RegTxTopATxTrigTxTrigGps, 0},
+			{commands.RegTxTopATxTrigTxTrigGps, 1},
+		}
+	}
+
+	return d.writeTxRegs(pkt.RfChain, seq)
+}
+
+// writeTxRegs writes seq, given as TX_TOP_A registers, to the TX registers of rfChain
+func (d *Dev) writeTxRegs(rfChain uint8, seq []regWrite) error {
+	for _, w := range seq {
+		if err := d.regs.RegWrite(txReg(rfChain, w.id), w.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// txReg returns the register of rfChain matching the TX_TOP_A register id
+func txReg(rfChain uint8, id commands.RegID) commands.RegID {
+	if rfChain == 1 {
+		return id + txChainOffset
+	}
+
+	return id
+}
+
+// txFreqToReg converts a frequency in Hz to the resolution of the TX frequency registers
+func txFreqToReg(freqHz uint32) uint32 {
+	return uint32(uint64(freqHz) << 18 / txClockFreq)
+}
+
+// txStatus maps the status of the TX state machine to the TX status
+func txStatus(status int32) model.TxStatus {
+	switch status {
+	case 0x80:
+		return model.TxStatusFree
+	case 0x91, 0x92:
+		return model.TxStatusScheduled
+	case 0x30, 0x50, 0x60, 0x70:
+		return model.TxStatusEmitting
+	}
+
+	return model.TxStatusUnknown
+}
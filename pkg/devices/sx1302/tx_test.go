@@ -0,0 +1,132 @@
+package sx1302_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/commands"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/sx1302test"
+)
+
+// newTxDev returns a started device sending on rf chain 0
+func newTxDev(t *testing.T) (*sx1302.Dev, *sx1302test.Sim) {
+	t.Helper()
+
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+	rf.TxEnable = true
+
+	d, sim := newSimDev(t,
+		sx1302.WithRfRxConfig(0, rf),
+		sx1302.WithTxGainLUT(0, &model.TxGainLUT{LUT: []model.TXGain{{RfPower: 14, PwrIdx: 20}}}),
+	)
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	sim.SetReg(commands.RegTxTopATxFsmStatusTxStatus, 0x80)
+	t.Cleanup(func() { stopTx(d, sim) })
+
+	return d, sim
+}
+
+// stopTx ends the emission of rf chain 0 left running by a test, which Stop waits for, and stops d
+func stopTx(d *sx1302.Dev, sim *sx1302test.Sim) {
+	sim.SetReg(commands.RegTxTopATxFsmStatusTxStatus, 0x80)
+	d.Stop()
+}
+
+func TestSendEmptyPayload(t *testing.T) {
+	d, sim := newTxDev(t)
+	sim.ClearWrites()
+
+	pkt := loraPacket(868100000)
+	pkt.Size = 0
+
+	if err := d.Send(pkt); err != nil {
+		t.Fatalf("Send() of an empty payload failed: %v", err)
+	}
+
+	for _, w := range sim.Writes() {
+		if w.Addr >= commands.TxBufferAddrA && int(w.Addr) < int(commands.TxBufferAddrA)+len(pkt.Payload) {
+			t.Errorf("Send() of an empty payload wrote 0x%02X to the tx buffer at 0x%04X", w.Value, w.Addr)
+		}
+	}
+
+	if got := sim.Reg(commands.RegTxTopATxrxCfg03PayloadLength); got != 0 {
+		t.Errorf("payload length = %d, want 0", got)
+	}
+}
+
+func TestTxStatus(t *testing.T) {
+	d, sim := newTxDev(t)
+
+	// status codes of the TX state machine as decoded by the reference HAL
+	tests := []struct {
+		status int32
+		want   model.TxStatus
+	}{
+		{0x80, model.TxStatusFree},
+		{0x91, model.TxStatusScheduled},
+		{0x92, model.TxStatusScheduled},
+		{0x30, model.TxStatusEmitting},
+		{0x50, model.TxStatusEmitting},
+		{0x60, model.TxStatusEmitting},
+		{0x70, model.TxStatusEmitting},
+		{0x00, model.TxStatusUnknown},
+	}
+
+	for _, tt := range tests {
+		sim.SetReg(commands.RegTxTopATxFsmStatusTxStatus, tt.status)
+
+		got, err := d.TxStatus(0)
+		if err != nil {
+			t.Fatalf("TxStatus() with status 0x%02X failed: %v", tt.status, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("TxStatus() with status 0x%02X = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestSendNotifyOnce(t *testing.T) {
+	d, sim := newTxDev(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch, err := d.SendNotify(ctx, loraPacket(868100000))
+	if err != nil {
+		t.Fatalf("SendNotify() failed: %v", err)
+	}
+
+	// the trigger starts the emission, which lasts until the state machine is free again
+	if got, err := d.TxStatus(0); err != nil || got != model.TxStatusEmitting {
+		t.Fatalf("TxStatus() after the trigger = %v, %v, want %v", got, err, model.TxStatusEmitting)
+	}
+
+	select {
+	case result := <-ch:
+		t.Fatalf("SendNotify() delivered %+v while emitting", result)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	sim.SetReg(commands.RegTxTopATxFsmStatusTxStatus, 0x80)
+
+	result, ok := <-ch
+	if !ok {
+		t.Fatal("SendNotify() closed the channel without a result")
+	}
+
+	if result.Err != nil || result.RfChain != 0 || result.RfPower != 14 {
+		t.Errorf("SendNotify() = %+v, want a result of rf chain 0 at 14dBm", result)
+	}
+
+	if result, ok := <-ch; ok {
+		t.Errorf("SendNotify() delivered a second result %+v", result)
+	}
+}
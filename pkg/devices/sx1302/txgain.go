@@ -0,0 +1,122 @@
+package sx1302
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+// Ranges of the TX gain fields
+const (
+	sx125xDigGainMax uint8 = 3
+	sx125xPaGainMax  uint8 = 3
+	sx125xDacGainMax uint8 = 3
+	sx125xMixGainMax uint8 = 15
+	sx1250PaGainMax  uint8 = 1
+	sx1250PwrIdxMax  uint8 = 22
+)
+
+// WithTxGainLUT configures the TX gain LUT of the RF chain rfChain, its entries sorted by ascending RfPower. The
+// LUT is validated against the radio type of the RF chain by Start. Without it, the RF chain uses the default LUT of
+// its radio type, see model.NewTxGainLUTWithDefaults.
+func WithTxGainLUT(rfChain uint8, lut *model.TxGainLUT) SX1302Config {
+	return func(d *Dev) {
+		if d.context.IsStarted {
+			log.Fatal("gateway is already running. Please stop it before changing configuration")
+		}
+
+		if rfChain >= model.MaxRfChains {
+			log.Fatalf("rf-chain %d is not a valid rf-chain number", rfChain)
+		}
+
+		for len(d.context.TxGainLUT) <= int(rfChain) {
+			d.context.TxGainLUT = append(d.context.TxGainLUT, model.TxGainLUT{})
+		}
+		d.context.TxGainLUT[rfChain] = model.TxGainLUT{LUT: append([]model.TXGain(nil), lut.LUT...)}
+		d.txGainLUTSet[rfChain] = true
+
+		log.WithFields(log.Fields{
+			"rf_chain": rfChain,
+			"size":     len(lut.LUT),
+		}).Info("TX gain LUT loaded")
+	}
+}
+
+// checkTxGainLUTs validates the TX gain LUTs of the RF chains enabled for TX
+func (d *Dev) checkTxGainLUTs() error {
+	for i, rf := range d.context.RfChainCfg {
+		if !rf.Enable || !rf.TxEnable {
+			continue
+		}
+
+		if i >= len(d.context.TxGainLUT) {
+			return fmt.Errorf("rf chain %d is enabled for tx but has no tx gain lut, configure it with WithTxGainLUT", i)
+		}
+
+		if err := validateTxGainLUT(d.context.TxGainLUT[i], rf.Type); err != nil {
+			return fmt.Errorf("tx gain lut of rf chain %d: %w, configure it with WithTxGainLUT", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateTxGainLUT checks the size and order of lut and that its entries only use the fields of radioType
+func validateTxGainLUT(lut model.TxGainLUT, radioType model.RadioType) error {
+	if len(lut.LUT) == 0 {
+		return errors.New("the lut is empty")
+	}
+
+	if len(lut.LUT) > model.MaxTxGainLutSize {
+		return fmt.Errorf("%d entries exceed the maximum of %d", len(lut.LUT), model.MaxTxGainLutSize)
+	}
+
+	for i, gain := range lut.LUT {
+		if i > 0 && gain.RfPower <= lut.LUT[i-1].RfPower {
+			return fmt.Errorf("entry %d: rf power %ddBm is not above the %ddBm of the previous entry", i, gain.RfPower, lut.LUT[i-1].RfPower)
+		}
+
+		if err := validateTxGain(gain, radioType); err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateTxGain checks the ranges of the fields used by radioType and that the other fields are not set
+func validateTxGain(gain model.TXGain, radioType model.RadioType) error {
+	switch radioType {
+	case model.RadioTypeSX1255, model.RadioTypeSX1257:
+		switch {
+		case gain.DigGain > sx125xDigGainMax:
+			return fmt.Errorf("dig gain %d exceeds %d", gain.DigGain, sx125xDigGainMax)
+		case gain.PaGain > sx125xPaGainMax:
+			return fmt.Errorf("pa gain %d exceeds %d", gain.PaGain, sx125xPaGainMax)
+		case gain.DacGain > sx125xDacGainMax:
+			return fmt.Errorf("dac gain %d exceeds %d", gain.DacGain, sx125xDacGainMax)
+		case gain.MixGain > sx125xMixGainMax:
+			return fmt.Errorf("mix gain %d exceeds %d", gain.MixGain, sx125xMixGainMax)
+		case gain.PwrIdx != 0:
+			return fmt.Errorf("pwr idx is not used by %s radios", radioType)
+		}
+
+	case model.RadioTypeSX1250:
+		switch {
+		case gain.PaGain > sx1250PaGainMax:
+			return fmt.Errorf("pa gain %d exceeds %d", gain.PaGain, sx1250PaGainMax)
+		case gain.PwrIdx > sx1250PwrIdxMax:
+			return fmt.Errorf("pwr idx %d exceeds %d", gain.PwrIdx, sx1250PwrIdxMax)
+		case gain.DigGain != 0 || gain.DacGain != 0 || gain.MixGain != 0 || gain.OffsetI != 0 || gain.OffsetQ != 0:
+			return fmt.Errorf("dig, dac and mix gain and the i/q offsets are not used by %s radios", radioType)
+		}
+
+	default:
+		return fmt.Errorf("radio type %s does not support tx", radioType)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package sx1302_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302"
+	"github.com/cedi/go_sx1302/pkg/devices/sx1302/model"
+)
+
+func TestValidateTxGainLUT(t *testing.T) {
+	lut := func(gains ...model.TXGain) model.TxGainLUT { return model.TxGainLUT{LUT: gains} }
+
+	tests := []struct {
+		name      string
+		lut       model.TxGainLUT
+		radioType model.RadioType
+		err       string
+	}{
+		{"sx1250 default", model.NewTxGainLUTWithDefaults(model.RadioTypeSX1250), model.RadioTypeSX1250, ""},
+		{"sx1257 default", model.NewTxGainLUTWithDefaults(model.RadioTypeSX1257), model.RadioTypeSX1257, ""},
+		{"sx1255 default", model.NewTxGainLUTWithDefaults(model.RadioTypeSX1255), model.RadioTypeSX1255, ""},
+		{"sx1250", lut(model.TXGain{RfPower: 12, PwrIdx: 15}, model.TXGain{RfPower: 27, PaGain: 1, PwrIdx: 22}), model.RadioTypeSX1250, ""},
+		{"sx1257", lut(model.TXGain{RfPower: -6, MixGain: 8}, model.TXGain{RfPower: 27, DigGain: 3, PaGain: 3, DacGain: 3, MixGain: 15}), model.RadioTypeSX1257, ""},
+		{"empty", lut(), model.RadioTypeSX1250, "empty"},
+		{"too many entries", lut(make([]model.TXGain, model.MaxTxGainLutSize+1)...), model.RadioTypeSX1250, "exceed"},
+		{"equal rf power", lut(model.TXGain{RfPower: 14}, model.TXGain{RfPower: 14}), model.RadioTypeSX1250, "entry 1: rf power 14dBm is not above"},
+		{"decreasing rf power", lut(model.TXGain{RfPower: 14}, model.TXGain{RfPower: 20}, model.TXGain{RfPower: 16}), model.RadioTypeSX1250, "entry 2: rf power 16dBm is not above"},
+		{"sx1250 pa gain", lut(model.TXGain{PaGain: 2}), model.RadioTypeSX1250, "pa gain 2 exceeds 1"},
+		{"sx1250 pwr idx", lut(model.TXGain{PwrIdx: 23}), model.RadioTypeSX1250, "pwr idx 23 exceeds 22"},
+		{"sx1250 mix gain", lut(model.TXGain{MixGain: 10}), model.RadioTypeSX1250, "not used"},
+		{"sx125x default on sx1250", model.NewTxGainLUTWithDefaults(model.RadioTypeSX1257), model.RadioTypeSX1250, "pa gain 2 exceeds 1"},
+		{"sx1257 dac gain", lut(model.TXGain{DacGain: 4}), model.RadioTypeSX1257, "dac gain 4 exceeds 3"},
+		{"sx1257 mix gain", lut(model.TXGain{MixGain: 16}), model.RadioTypeSX1257, "mix gain 16 exceeds 15"},
+		{"sx1257 pwr idx", lut(model.TXGain{PwrIdx: 1}), model.RadioTypeSX1257, "not used"},
+		{"sx1272", lut(model.TXGain{}), model.RadioTypeSX1272, "does not support tx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sx1302.ValidateTxGainLUT(tt.lut, tt.radioType)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("ValidateTxGainLUT() failed: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("ValidateTxGainLUT() = %v, want an error containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestStartDefaultTxGainLUT(t *testing.T) {
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+	rf.TxEnable = true
+
+	d, _ := newSimDev(t, sx1302.WithRfRxConfig(0, rf))
+	if err := d.Start(); err != nil {
+		t.Fatalf("Start() of a sx1250 with the default tx gain lut failed: %v", err)
+	}
+	defer d.Stop()
+
+	want := model.NewTxGainLUTWithDefaults(model.RadioTypeSX1250)
+	if got := d.TxGainLUT(0); len(got.LUT) != 1 || got.LUT[0] != want.LUT[0] {
+		t.Errorf("TxGainLUT() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTxGainLUTOverridesDefault(t *testing.T) {
+	rf := model.NewRxRfConf()
+	rf.FreqHz = 868500000
+	rf.TxEnable = true
+
+	// the lut is kept whatever the order of the options
+	lut := &model.TxGainLUT{LUT: []model.TXGain{{RfPower: 12, PwrIdx: 15}, {RfPower: 14, PwrIdx: 17}}}
+	d, _ := newSimDev(t, sx1302.WithTxGainLUT(0, lut), sx1302.WithRfRxConfig(0, rf))
+
+	if got := d.TxGainLUT(0); len(got.LUT) != 2 || got.LUT[1] != lut.LUT[1] {
+		t.Errorf("TxGainLUT() = %+v, want %+v", got, lut)
+	}
+}
@@ -0,0 +1,191 @@
+// Package gps synchronises the concentrator counter to GPS time. It reads the NMEA and UBX messages of a u-blox
+// receiver and latches the concentrator counter on each PPS.
+package gps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cedi/go_sx1302/pkg/serial"
+)
+
+const (
+	// RefMaxAge is the age after which a reference is no longer used, e.g. because the receiver lost its fix
+	RefMaxAge = 30 * time.Second
+
+	// readTimeout is the read timeout of the serial port, the context is checked in between
+	readTimeout = time.Second
+
+	// idleRetryInterval is the pause before reading again from a source reporting io.EOF, which returns immediately
+	idleRetryInterval = 10 * time.Millisecond
+)
+
+// ErrNoRef is returned by GPS.Ref while there is no reference younger than RefMaxAge
+var ErrNoRef = errors.New("gps: no time reference")
+
+// PPSLatch provides the concentrator counter latched on the last PPS, in microseconds and not wrapping. The SX1302
+// device implements it.
+type PPSLatch interface {
+	PPSCounterUs() (uint64, error)
+}
+
+// Position is the position reported by the receiver
+type Position struct {
+	// Lat and Lon are in degrees, negative to the south and west, Alt in meters above mean sea level
+	Lat float64
+	Lon float64
+	Alt float64
+
+	// Satellites is the number of satellites of the fix
+	Satellites uint8
+}
+
+// GPS keeps the mapping of the concentrator counter to GPS time and UTC
+type GPS struct {
+	r      *Reader
+	latch  PPSLatch
+	closer io.Closer
+
+	mu       sync.Mutex
+	clock    clock
+	leapS    time.Duration
+	leapOK   bool
+	position Position
+	fix      bool
+}
+
+// New returns a GPS reading the messages of the receiver from r and latching the PPS counter from latch
+func New(r io.Reader, latch PPSLatch) *GPS {
+	return &GPS{r: NewReader(r), latch: latch}
+}
+
+// Open opens the u-blox receiver at the tty path with the baud rate baud and enables its NAV-TIMEGPS message
+func Open(path string, baud int, latch PPSLatch) (*GPS, error) {
+	port, err := serial.Open(path, serial.Config{Baud: baud, ReadTimeout: readTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	// CFG-MSG: output NAV-TIMEGPS on every navigation solution
+	if _, err := port.Write(ubxFrame(ubxClassCfg, ubxIDCfgMsg, []byte{ubxClassNav, ubxIDNavTimeGPS, 1})); err != nil {
+		port.Close()
+		return nil, fmt.Errorf("gps: failed to enable NAV-TIMEGPS: %w", err)
+	}
+
+	g := New(port, latch)
+	g.closer = port
+	return g, nil
+}
+
+// Close closes the serial port opened by Open
+func (g *GPS) Close() error {
+	if g.closer == nil {
+		return nil
+	}
+
+	return g.closer.Close()
+}
+
+// Run processes the messages of the receiver until ctx is done or reading fails. Read timeouts, reported as
+// serial.ErrTimeout or as io.EOF by a tty without data, and messages cut by them do not end Run. It returns ctx.Err()
+// once ctx is done.
+func (g *GPS) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		msg, err := g.r.Next()
+		switch {
+		case errors.Is(err, serial.ErrTimeout), errors.Is(err, io.ErrUnexpectedEOF):
+			continue
+		case errors.Is(err, io.EOF):
+			select {
+			case <-ctx.Done():
+			case <-time.After(idleRetryInterval):
+			}
+			continue
+		case err != nil:
+			return err
+		}
+
+		if err := g.handle(msg); err != nil {
+			log.WithError(err).Warn("Failed to synchronise to the gps")
+		}
+	}
+}
+
+// Ref returns the current reference, ErrNoRef if there is none younger than RefMaxAge
+func (g *GPS) Ref() (Ref, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.clock.valid || time.Since(g.clock.ref.Updated) > RefMaxAge {
+		return Ref{}, ErrNoRef
+	}
+
+	return g.clock.ref, nil
+}
+
+// Position returns the last position, ok is false if the receiver has no fix
+func (g *GPS) Position() (pos Position, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.position, g.fix
+}
+
+// handle updates the state with msg, time messages synchronise the reference to the PPS latched last
+func (g *GPS) handle(msg Message) error {
+	var utc time.Time
+	var gps time.Duration
+
+	g.mu.Lock()
+	switch m := msg.(type) {
+	case GGA:
+		g.fix = m.Quality > 0
+		if g.fix {
+			g.position = Position{Lat: m.Lat, Lon: m.Lon, Alt: m.Alt, Satellites: m.Satellites}
+		}
+
+	case RMC:
+		if m.Valid {
+			utc = m.Time
+			if g.leapOK {
+				gps = utc.Sub(gpsEpoch) + g.leapS
+			}
+		}
+
+	case NavTimeGPS:
+		if m.Valid&NavTimeGPSLeapSValid != 0 {
+			g.leapS, g.leapOK = time.Duration(m.LeapS)*time.Second, true
+		}
+
+		if t, ok := m.UTC(); ok {
+			utc = t
+			gps, _ = m.GPSTime()
+		}
+	}
+	g.mu.Unlock()
+
+	if utc.IsZero() {
+		return nil
+	}
+
+	// the message reports the time of the PPS preceding it
+	countUs, err := g.latch.PPSCounterUs()
+	if err != nil {
+		return fmt.Errorf("gps: failed to read the pps counter: %w", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.clock.sync(countUs, utc, gps, time.Now())
+}
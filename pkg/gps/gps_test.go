@@ -0,0 +1,162 @@
+package gps_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/gps"
+	"github.com/cedi/go_sx1302/pkg/serial"
+)
+
+// testdata/ublox.stream is a stream as output by a u-blox receiver with a fix over three seconds starting at
+// 2024-05-01T12:00:00Z: RMC, VTG, GGA and GSV sentences followed by a NAV-TIMEGPS frame every second.
+
+// stream returns the recorded stream split into chunks: a chunk starts every second, and the GGA sentence and the
+// NAV-TIMEGPS frame of each second are cut in two
+func stream(t *testing.T) [][]byte {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/ublox.stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chunks [][]byte
+	for len(data) > 0 {
+		next := bytes.Index(data[1:], []byte("$GNRMC")) + 1
+		if next == 0 {
+			next = len(data)
+		}
+
+		second := data[:next]
+		gga := bytes.Index(second, []byte("$GNGGA")) + 20
+		ubx := bytes.Index(second, []byte{0xB5, 0x62}) + 9
+		chunks = append(chunks, second[:gga], second[gga:ubx], second[ubx:])
+		data = data[next:]
+	}
+
+	return chunks
+}
+
+// replay is a receiver replaying chunks of a stream, it fails with idle before each chunk like a serial port timing
+// out between the bursts of the receiver
+type replay struct {
+	chunks [][]byte
+	idle   error
+	idled  bool
+
+	// pps is called with the index of each chunk before it is read
+	pps func(chunk int)
+	i   int
+}
+
+func (r *replay) Read(b []byte) (int, error) {
+	if r.i == len(r.chunks) || !r.idled {
+		r.idled = true
+		return 0, r.idle
+	}
+
+	if r.pps != nil {
+		r.pps(r.i)
+	}
+
+	n := copy(b, r.chunks[r.i])
+	r.i++
+	r.idled = false
+	return n, nil
+}
+
+// latch is a concentrator counter latching on the PPS
+type latch struct {
+	countUs atomic.Uint64
+}
+
+func (l *latch) PPSCounterUs() (uint64, error) {
+	return l.countUs.Load(), nil
+}
+
+func TestReaderCompletesCutMessages(t *testing.T) {
+	r := gps.NewReader(&replay{chunks: stream(t), idle: serial.ErrTimeout})
+
+	var got []string
+	for len(got) < 9 {
+		msg, err := r.Next()
+		switch {
+		case errors.Is(err, serial.ErrTimeout):
+			continue
+		case err != nil:
+			t.Fatalf("Next() failed after %v: %v", got, err)
+		}
+
+		switch msg.(type) {
+		case gps.RMC:
+			got = append(got, "RMC")
+		case gps.GGA:
+			got = append(got, "GGA")
+		case gps.NavTimeGPS:
+			got = append(got, "NAV-TIMEGPS")
+		}
+	}
+
+	for i := 0; i < 9; i += 3 {
+		if got[i] != "RMC" || got[i+1] != "GGA" || got[i+2] != "NAV-TIMEGPS" {
+			t.Fatalf("Next() returned %v, want RMC, GGA and NAV-TIMEGPS every second", got)
+		}
+	}
+}
+
+func TestRunReadsThroughTimeouts(t *testing.T) {
+	for _, idle := range []error{serial.ErrTimeout, io.EOF} {
+		chunks := stream(t)
+		l := &latch{}
+		l.countUs.Store(5000000)
+
+		// the pps of each second precedes its messages
+		src := &replay{chunks: chunks, idle: idle, pps: func(chunk int) {
+			if chunk > 0 && chunk%3 == 0 {
+				l.countUs.Add(1000000)
+			}
+		}}
+		g := gps.New(src, l)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- g.Run(ctx) }()
+
+		want := time.Date(2024, time.May, 1, 12, 0, 2, 0, time.UTC)
+		deadline := time.Now().Add(time.Second)
+		ref, err := g.Ref()
+		for ; err != nil || !ref.UTC.Equal(want); ref, err = g.Ref() {
+			if time.Now().After(deadline) {
+				t.Fatalf("%v: Ref() = %+v, %v, want a reference at %s", idle, ref, err, want)
+			}
+
+			select {
+			case err := <-done:
+				t.Fatalf("%v: Run() returned %v before it was cancelled", idle, err)
+			case <-time.After(time.Millisecond):
+			}
+		}
+
+		gpsTime := want.Sub(time.Date(1980, time.January, 6, 0, 0, 0, 0, time.UTC)) + 18*time.Second
+		if ref.CountUs != 7000000 || ref.GPS != gpsTime || ref.XtalErr != 1 {
+			t.Errorf("%v: Ref() = %+v, want the counter 7000000 at GPS time %s", idle, ref, gpsTime)
+		}
+
+		pos, ok := g.Position()
+		if want := (gps.Position{Lat: 47.285233166666664, Lon: 8.565265, Alt: 499.6, Satellites: 8}); !ok || pos != want {
+			t.Errorf("%v: Position() = %+v, %v, want %+v", idle, pos, ok, want)
+		}
+
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("%v: Run() = %v, want %v", idle, err, context.Canceled)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package gps
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RMC is the recommended minimum data sentence, it carries the UTC date and time of the last PPS
+type RMC struct {
+	// Time is the UTC time of the fix
+	Time time.Time
+
+	// Valid is false if the receiver has no valid fix
+	Valid bool
+
+	// Lat and Lon are the position in degrees, negative to the south and west
+	Lat float64
+	Lon float64
+}
+
+// GGA is the fix data sentence, it carries the position and fix quality
+type GGA struct {
+	// TimeOfDay is the UTC time of the fix since midnight
+	TimeOfDay time.Duration
+
+	// Lat and Lon are the position in degrees, negative to the south and west, Alt the altitude above mean sea level
+	// in meters
+	Lat float64
+	Lon float64
+	Alt float64
+
+	// Quality is the fix quality, 0 means no fix
+	Quality uint8
+
+	// Satellites is the number of satellites in use
+	Satellites uint8
+}
+
+func (RMC) message() {}
+func (GGA) message() {}
+
+// ErrUnsupported is returned by ParseNMEA for valid sentences of a type not handled by this package
+var ErrUnsupported = errors.New("gps: unsupported sentence")
+
+// ParseNMEA parses an NMEA sentence including the leading '$' and the checksum, without line ending. RMC and GGA
+// sentences of any talker are supported.
+func ParseNMEA(sentence string) (Message, error) {
+	body, ok := strings.CutPrefix(sentence, "$")
+	if !ok {
+		return nil, fmt.Errorf("gps: sentence %q does not start with '$'", sentence)
+	}
+
+	body, sum, ok := strings.Cut(body, "*")
+	if !ok {
+		return nil, fmt.Errorf("gps: sentence %q has no checksum", sentence)
+	}
+
+	want, err := strconv.ParseUint(sum, 16, 8)
+	if err != nil {
+		return nil, fmt.Errorf("gps: sentence %q has an invalid checksum", sentence)
+	}
+
+	var got byte
+	for i := 0; i < len(body); i++ {
+		got ^= body[i]
+	}
+	if got != byte(want) {
+		return nil, fmt.Errorf("gps: checksum mismatch of sentence %q, calculated 0x%02X", sentence, got)
+	}
+
+	fields := strings.Split(body, ",")
+	if len(fields[0]) != 5 {
+		return nil, fmt.Errorf("gps: invalid sentence type %q", fields[0])
+	}
+
+	// the first two characters name the talker, e.g. GP for GPS or GN for combined constellations
+	switch fields[0][2:] {
+	case "RMC":
+		return parseRMC(fields)
+	case "GGA":
+		return parseGGA(fields)
+	}
+
+	return nil, ErrUnsupported
+}
+
+// parseRMC parses the fields of a RMC sentence
+func parseRMC(fields []string) (RMC, error) {
+	if len(fields) < 10 {
+		return RMC{}, fmt.Errorf("gps: RMC sentence with %d fields is too short", len(fields))
+	}
+
+	rmc := RMC{Valid: fields[2] == "A"}
+	if !rmc.Valid {
+		return rmc, nil
+	}
+
+	tod, err := parseTimeOfDay(fields[1])
+	if err != nil {
+		return RMC{}, err
+	}
+
+	date, err := time.Parse("020106", fields[9])
+	if err != nil {
+		return RMC{}, fmt.Errorf("gps: invalid RMC date %q", fields[9])
+	}
+	rmc.Time = date.Add(tod)
+
+	if rmc.Lat, err = parseCoordinate(fields[3], fields[4], 2); err != nil {
+		return RMC{}, err
+	}
+
+	if rmc.Lon, err = parseCoordinate(fields[5], fields[6], 3); err != nil {
+		return RMC{}, err
+	}
+
+	return rmc, nil
+}
+
+// parseGGA parses the fields of a GGA sentence
+func parseGGA(fields []string) (GGA, error) {
+	if len(fields) < 10 {
+		return GGA{}, fmt.Errorf("gps: GGA sentence with %d fields is too short", len(fields))
+	}
+
+	quality, err := strconv.ParseUint(fields[6], 10, 8)
+	if err != nil {
+		return GGA{}, fmt.Errorf("gps: invalid GGA fix quality %q", fields[6])
+	}
+
+	gga := GGA{Quality: uint8(quality)}
+	if gga.Quality == 0 {
+		return gga, nil
+	}
+
+	if gga.TimeOfDay, err = parseTimeOfDay(fields[1]); err != nil {
+		return GGA{}, err
+	}
+
+	if gga.Lat, err = parseCoordinate(fields[2], fields[3], 2); err != nil {
+		return GGA{}, err
+	}
+
+	if gga.Lon, err = parseCoordinate(fields[4], fields[5], 3); err != nil {
+		return GGA{}, err
+	}
+
+	satellites, err := strconv.ParseUint(fields[7], 10, 8)
+	if err != nil {
+		return GGA{}, fmt.Errorf("gps: invalid GGA satellite count %q", fields[7])
+	}
+	gga.Satellites = uint8(satellites)
+
+	if gga.Alt, err = strconv.ParseFloat(fields[9], 64); err != nil {
+		return GGA{}, fmt.Errorf("gps: invalid GGA altitude %q", fields[9])
+	}
+
+	return gga, nil
+}
+
+// parseTimeOfDay parses a time of day formatted as hhmmss.ss
+func parseTimeOfDay(s string) (time.Duration, error) {
+	if len(s) < 6 {
+		return 0, fmt.Errorf("gps: invalid time %q", s)
+	}
+
+	h, errH := strconv.ParseUint(s[0:2], 10, 8)
+	m, errM := strconv.ParseUint(s[2:4], 10, 8)
+	sec, errS := strconv.ParseFloat(s[4:], 64)
+	if errH != nil || errM != nil || errS != nil || h > 23 || m > 59 || sec >= 61 {
+		return 0, fmt.Errorf("gps: invalid time %q", s)
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second)), nil
+}
+
+// parseCoordinate parses a coordinate formatted with degDigits digits of degrees followed by minutes, e.g. ddmm.mmmm
+func parseCoordinate(s string, hemisphere string, degDigits int) (float64, error) {
+	if len(s) < degDigits+2 {
+		return 0, fmt.Errorf("gps: invalid coordinate %q", s)
+	}
+
+	deg, errD := strconv.ParseUint(s[:degDigits], 10, 8)
+	minutes, errM := strconv.ParseFloat(s[degDigits:], 64)
+	if errD != nil || errM != nil || minutes >= 60 {
+		return 0, fmt.Errorf("gps: invalid coordinate %q", s)
+	}
+
+	coord := float64(deg) + minutes/60
+	switch hemisphere {
+	case "N", "E":
+		return coord, nil
+	case "S", "W":
+		return -coord, nil
+	}
+
+	return 0, fmt.Errorf("gps: invalid hemisphere %q", hemisphere)
+}
@@ -0,0 +1,136 @@
+package gps
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// nmeaMaxLength bounds the length of a NMEA sentence, the standard allows 82 characters
+const nmeaMaxLength = 128
+
+// Message is a message of the GPS receiver: RMC, GGA or NavTimeGPS
+type Message interface {
+	message()
+}
+
+// Reader splits the byte stream of a GPS receiver into NMEA sentences and UBX frames
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a Reader reading the stream of r, e.g. a serial port or a recorded stream
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next supported message of the stream. Unsupported and corrupted messages are skipped, errors of
+// the underlying reader are returned as is. A message cut by an error, e.g. a read timeout, is kept in the buffer and
+// completed by the next call.
+func (r *Reader) Next() (Message, error) {
+	for {
+		start, err := r.r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+
+		var msg Message
+		var size int
+		switch start[0] {
+		case '$':
+			msg, size, err = r.nmea()
+		case ubxSync1:
+			msg, size, err = r.ubx()
+		default:
+			r.r.Discard(1)
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// size is the length of the message or of the bytes to skip
+		r.r.Discard(size)
+		if msg != nil {
+			return msg, nil
+		}
+	}
+}
+
+// errCorrupted is returned by parsed for data to be skipped
+var errCorrupted = errors.New("gps: corrupted message")
+
+// nmea peeks at the sentence starting at the '$' at the head of the buffer. It returns the sentence and the number
+// of bytes it takes, a nil message for bytes to skip.
+func (r *Reader) nmea() (Message, int, error) {
+	for n := 2; ; n++ {
+		buf, err := r.r.Peek(n)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch buf[n-1] {
+		case '\n':
+			msg, err := parsed(ParseNMEA(strings.TrimRight(string(buf[:n-1]), "\r")))
+			if err != nil {
+				return nil, n, nil
+			}
+			return msg, n, nil
+
+		case '$', ubxSync1:
+			// the line ending was lost, resynchronise on the new message
+			return nil, n - 1, nil
+		}
+
+		if n >= nmeaMaxLength {
+			return nil, n, nil
+		}
+	}
+}
+
+// ubx peeks at the frame starting at the sync chars at the head of the buffer. It returns the frame and the number of
+// bytes it takes, a nil message for bytes to skip.
+func (r *Reader) ubx() (Message, int, error) {
+	header, err := r.r.Peek(ubxHeaderSize)
+	if err != nil {
+		// a stream ending right after the first sync char is no frame either
+		if len(header) > 1 && header[1] != ubxSync2 {
+			return nil, 1, nil
+		}
+		return nil, 0, err
+	}
+
+	size := int(binary.LittleEndian.Uint16(header[4:]))
+	if header[1] != ubxSync2 || size > ubxMaxPayload {
+		return nil, 1, nil
+	}
+
+	frame, err := r.r.Peek(ubxHeaderSize + size + 2)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	msg, err := parsed(ParseUBX(frame))
+	if err != nil {
+		return nil, 1, nil
+	}
+	return msg, len(frame), nil
+}
+
+// parsed drops unsupported messages and turns parse errors into errCorrupted
+func parsed(msg Message, err error) (Message, error) {
+	switch {
+	case errors.Is(err, ErrUnsupported):
+		return nil, nil
+	case err != nil:
+		log.WithError(err).Debug("Skipped corrupted gps message")
+		return nil, errCorrupted
+	}
+
+	return msg, nil
+}
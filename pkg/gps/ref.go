@@ -0,0 +1,122 @@
+package gps
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// xtalErrMax bounds the deviation of the concentrator crystal from GPS time, larger deviations are taken as a
+	// mismatch of PPS and time message, e.g. after a lost message
+	xtalErrMax = 10e-6
+
+	// xtalInitAvg is the number of PPS the crystal error is averaged over initially, afterwards it is filtered with
+	// xtalFilterCoef as in the packet forwarder of the reference HAL
+	xtalInitAvg    = 16
+	xtalFilterCoef = 256
+)
+
+// Ref maps the concentrator counter to UTC and GPS time, based on the counter latched on a PPS
+type Ref struct {
+	// CountUs is the concentrator counter in microseconds latched on the PPS
+	CountUs uint64
+
+	// UTC and GPS are the time of the PPS, GPS since the GPS epoch. GPS is 0 until the receiver reported it.
+	UTC time.Time
+	GPS time.Duration
+
+	// XtalErr is the measured rate of the concentrator counter relative to GPS time, 1.0 for a perfect crystal
+	XtalErr float64
+
+	// Updated is the local time of the update of the reference
+	Updated time.Time
+}
+
+// CountToUTC converts the concentrator counter countUs to UTC
+func (r Ref) CountToUTC(countUs uint64) time.Time {
+	return r.UTC.Add(r.countToDuration(countUs))
+}
+
+// UTCToCount converts utc to the concentrator counter in microseconds
+func (r Ref) UTCToCount(utc time.Time) uint64 {
+	return r.durationToCount(utc.Sub(r.UTC))
+}
+
+// CountToGPS converts the concentrator counter countUs to GPS time since the GPS epoch
+func (r Ref) CountToGPS(countUs uint64) time.Duration {
+	return r.GPS + r.countToDuration(countUs)
+}
+
+// GPSToCount converts the GPS time gps since the GPS epoch to the concentrator counter in microseconds
+func (r Ref) GPSToCount(gps time.Duration) uint64 {
+	return r.durationToCount(gps - r.GPS)
+}
+
+// countToDuration returns the time from the reference to countUs
+func (r Ref) countToDuration(countUs uint64) time.Duration {
+	ticks := float64(int64(countUs - r.CountUs))
+	return time.Duration(math.Round(ticks / r.XtalErr * float64(time.Microsecond)))
+}
+
+// durationToCount returns the counter value d after the reference
+func (r Ref) durationToCount(d time.Duration) uint64 {
+	ticks := math.Round(float64(d) / float64(time.Microsecond) * r.XtalErr)
+	return r.CountUs + uint64(int64(ticks))
+}
+
+// clock maintains the reference from the PPS latches and time messages
+type clock struct {
+	ref   Ref
+	valid bool
+
+	// samples and sum average the crystal error until xtalInitAvg samples were taken
+	samples int
+	sum     float64
+}
+
+// sync updates the reference with the time of the PPS latched at countUs. A message repeating the time of the current
+// reference only completes it, a crystal error out of range restarts the reference.
+func (c *clock) sync(countUs uint64, utc time.Time, gps time.Duration, now time.Time) error {
+	if c.valid && countUs == c.ref.CountUs {
+		if c.ref.GPS == 0 {
+			c.ref.GPS = gps
+		}
+		return nil
+	}
+
+	prev := c.ref
+	c.ref = Ref{CountUs: countUs, UTC: utc, GPS: gps, XtalErr: 1, Updated: now}
+	if !c.valid {
+		c.valid = true
+		return nil
+	}
+
+	elapsed := utc.Sub(prev.UTC)
+	if elapsed <= 0 {
+		c.restart()
+		return fmt.Errorf("gps: time went back from %s to %s", prev.UTC, utc)
+	}
+
+	xtalErr := float64(countUs-prev.CountUs) * float64(time.Microsecond) / float64(elapsed)
+	if math.Abs(xtalErr-1) > xtalErrMax {
+		c.restart()
+		return fmt.Errorf("gps: counter advanced %dus in %s, the pps and time messages are out of step", countUs-prev.CountUs, elapsed)
+	}
+
+	if c.samples < xtalInitAvg {
+		c.samples++
+		c.sum += xtalErr
+		c.ref.XtalErr = c.sum / float64(c.samples)
+	} else {
+		c.ref.XtalErr = prev.XtalErr + (xtalErr-prev.XtalErr)/xtalFilterCoef
+	}
+
+	return nil
+}
+
+// restart discards the crystal error, the current reference starts a new measurement
+func (c *clock) restart() {
+	c.samples = 0
+	c.sum = 0
+}
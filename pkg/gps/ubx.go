@@ -0,0 +1,133 @@
+package gps
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// UBX frames start with the sync chars, followed by class, id, the little endian payload length, the payload and a
+// two byte checksum
+const (
+	ubxSync1      byte = 0xB5
+	ubxSync2      byte = 0x62
+	ubxHeaderSize      = 6
+	ubxMaxPayload      = 1024
+)
+
+// UBX message classes and ids
+const (
+	ubxClassNav      byte = 0x01
+	ubxClassCfg      byte = 0x06
+	ubxIDNavTimeGPS  byte = 0x20
+	ubxIDCfgMsg      byte = 0x01
+	ubxNavTimeGPSLen      = 16
+)
+
+// Validity flags of NavTimeGPS
+const (
+	NavTimeGPSTowValid   uint8 = 0x01
+	NavTimeGPSWeekValid  uint8 = 0x02
+	NavTimeGPSLeapSValid uint8 = 0x04
+)
+
+// gpsEpoch is the start of the GPS time scale
+var gpsEpoch = time.Date(1980, time.January, 6, 0, 0, 0, 0, time.UTC)
+
+// NavTimeGPS is the UBX NAV-TIMEGPS message, it carries the GPS time of the last PPS
+type NavTimeGPS struct {
+	// ITOW is the GPS time of week in milliseconds, FTOW its fractional part in nanoseconds (-500000..500000)
+	ITOW uint32
+	FTOW int32
+
+	// Week is the GPS week number since the GPS epoch
+	Week int16
+
+	// LeapS is the number of leap seconds GPS time is ahead of UTC
+	LeapS int8
+
+	// Valid holds the NavTimeGPS*Valid flags
+	Valid uint8
+
+	// TAcc is the time accuracy estimate in nanoseconds
+	TAcc uint32
+}
+
+func (NavTimeGPS) message() {}
+
+// GPSTime returns the GPS time since the GPS epoch, ok is false unless time of week and week are valid
+func (n NavTimeGPS) GPSTime() (gps time.Duration, ok bool) {
+	if n.Valid&NavTimeGPSTowValid == 0 || n.Valid&NavTimeGPSWeekValid == 0 {
+		return 0, false
+	}
+
+	week := time.Duration(n.Week) * 7 * 24 * time.Hour
+	return week + time.Duration(n.ITOW)*time.Millisecond + time.Duration(n.FTOW), true
+}
+
+// UTC returns the UTC time, ok is false unless the GPS time and the leap seconds are valid
+func (n NavTimeGPS) UTC() (utc time.Time, ok bool) {
+	gps, ok := n.GPSTime()
+	if !ok || n.Valid&NavTimeGPSLeapSValid == 0 {
+		return time.Time{}, false
+	}
+
+	return gpsEpoch.Add(gps - time.Duration(n.LeapS)*time.Second), true
+}
+
+// ParseUBX parses a complete UBX frame including sync chars and checksum. NAV-TIMEGPS messages are supported.
+func ParseUBX(frame []byte) (Message, error) {
+	if len(frame) < ubxHeaderSize+2 || frame[0] != ubxSync1 || frame[1] != ubxSync2 {
+		return nil, fmt.Errorf("gps: invalid ubx frame of %d bytes", len(frame))
+	}
+
+	size := int(binary.LittleEndian.Uint16(frame[4:]))
+	if len(frame) != ubxHeaderSize+size+2 {
+		return nil, fmt.Errorf("gps: ubx frame of %d bytes does not match its payload length %d", len(frame), size)
+	}
+
+	ckA, ckB := ubxChecksum(frame[2 : ubxHeaderSize+size])
+	if ckA != frame[len(frame)-2] || ckB != frame[len(frame)-1] {
+		return nil, fmt.Errorf("gps: checksum mismatch of ubx message 0x%02X 0x%02X", frame[2], frame[3])
+	}
+
+	payload := frame[ubxHeaderSize : ubxHeaderSize+size]
+	switch {
+	case frame[2] == ubxClassNav && frame[3] == ubxIDNavTimeGPS:
+		if size != ubxNavTimeGPSLen {
+			return nil, fmt.Errorf("gps: NAV-TIMEGPS payload of %d bytes, expected %d", size, ubxNavTimeGPSLen)
+		}
+
+		return NavTimeGPS{
+			ITOW:  binary.LittleEndian.Uint32(payload[0:]),
+			FTOW:  int32(binary.LittleEndian.Uint32(payload[4:])),
+			Week:  int16(binary.LittleEndian.Uint16(payload[8:])),
+			LeapS: int8(payload[10]),
+			Valid: payload[11],
+			TAcc:  binary.LittleEndian.Uint32(payload[12:]),
+		}, nil
+	}
+
+	return nil, ErrUnsupported
+}
+
+// ubxFrame builds the UBX frame of a message
+func ubxFrame(class, id byte, payload []byte) []byte {
+	frame := []byte{ubxSync1, ubxSync2, class, id}
+	frame = binary.LittleEndian.AppendUint16(frame, uint16(len(payload)))
+	frame = append(frame, payload...)
+
+	ckA, ckB := ubxChecksum(frame[2:])
+	return append(frame, ckA, ckB)
+}
+
+// ubxChecksum returns the 8 bit Fletcher checksum of the class, id, length and payload of a UBX frame
+func ubxChecksum(data []byte) (byte, byte) {
+	var ckA, ckB byte
+	for _, b := range data {
+		ckA += b
+		ckB += ckA
+	}
+
+	return ckA, ckB
+}
@@ -0,0 +1,69 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrTimeout is returned by Read if no data arrived within the read timeout of the port
+var ErrTimeout = errors.New("serial: read timeout")
+
+// Config configures a serial port
+type Config struct {
+	// Baud rate of the port, e.g. 115200
+	Baud int
+
+	// ReadTimeout after which a Read without any received data fails with ErrTimeout.
+	// It is rounded to 100ms steps, 0 blocks until data arrives
+	ReadTimeout time.Duration
+}
+
+// Port is a serial port (tty) configured in raw mode
+type Port struct {
+	f       *os.File
+	path    string
+	timeout bool
+}
+
+// Open opens the tty at path in raw 8N1 mode as configured by conf
+func Open(path string, conf Config) (*Port, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|noCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configure(f, conf); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("serial: failed to configure %s: %w", path, err)
+	}
+
+	return &Port{f: f, path: path, timeout: conf.ReadTimeout > 0}, nil
+}
+
+// Read reads up to len(b) bytes from the port. It fails with ErrTimeout if no data arrived within the read timeout
+func (p *Port) Read(b []byte) (int, error) {
+	// an expired read timeout ends the read without data, which os.File reports as io.EOF
+	n, err := p.f.Read(b)
+	if n == 0 && p.timeout && len(b) > 0 && (err == nil || errors.Is(err, io.EOF)) {
+		return 0, ErrTimeout
+	}
+
+	return n, err
+}
+
+// Write writes b to the port
+func (p *Port) Write(b []byte) (int, error) {
+	return p.f.Write(b)
+}
+
+// Close closes the port
+func (p *Port) Close() error {
+	return p.f.Close()
+}
+
+func (p *Port) String() string {
+	return p.path
+}
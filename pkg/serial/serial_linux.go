@@ -0,0 +1,62 @@
+package serial
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const noCTTY = unix.O_NOCTTY
+
+// baudRates maps the supported baud rates to their termios speed flags
+var baudRates = map[int]uint32{
+	9600:   unix.B9600,
+	19200:  unix.B19200,
+	38400:  unix.B38400,
+	57600:  unix.B57600,
+	115200: unix.B115200,
+	230400: unix.B230400,
+	460800: unix.B460800,
+	921600: unix.B921600,
+}
+
+// configure puts the tty f into raw 8N1 mode
+func configure(f *os.File, conf Config) error {
+	speed, ok := baudRates[conf.Baud]
+	if !ok {
+		return fmt.Errorf("unsupported baud rate %d", conf.Baud)
+	}
+
+	fd := int(f.Fd())
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return err
+	}
+
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB | unix.CSTOPB | unix.CRTSCTS | unix.CBAUD
+	t.Cflag |= unix.CS8 | unix.CLOCAL | unix.CREAD | speed
+	t.Ispeed = speed
+	t.Ospeed = speed
+
+	// a read returns as soon as a byte is available or the timeout expired
+	deciseconds := (conf.ReadTimeout + 99*time.Millisecond) / (100 * time.Millisecond)
+	if deciseconds > 255 {
+		deciseconds = 255
+	}
+	t.Cc[unix.VMIN] = 0
+	t.Cc[unix.VTIME] = uint8(deciseconds)
+	if conf.ReadTimeout == 0 {
+		t.Cc[unix.VMIN] = 1
+	}
+
+	if err := unix.IoctlSetTermios(fd, unix.TCSETS, t); err != nil {
+		return err
+	}
+
+	return unix.IoctlSetInt(fd, unix.TCFLSH, unix.TCIOFLUSH)
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package serial
+
+import (
+	"errors"
+	"os"
+)
+
+const noCTTY = 0
+
+// configure is only supported on linux
+func configure(f *os.File, conf Config) error {
+	return errors.New("serial ports are only supported on linux")
+}
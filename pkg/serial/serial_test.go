@@ -0,0 +1,54 @@
+package serial_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cedi/go_sx1302/pkg/serial"
+	"github.com/cedi/go_sx1302/pkg/serial/serialtest"
+)
+
+func openPTY(t *testing.T, timeout time.Duration) (*serial.Port, func([]byte)) {
+	t.Helper()
+
+	master, path, err := serialtest.OpenPTY()
+	if err != nil {
+		t.Skipf("no pseudo-terminal: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	port, err := serial.Open(path, serial.Config{Baud: 115200, ReadTimeout: timeout})
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	t.Cleanup(func() { port.Close() })
+
+	return port, func(b []byte) {
+		if _, err := master.Write(b); err != nil {
+			t.Fatalf("failed to write to the pty: %v", err)
+		}
+	}
+}
+
+func TestReadTimeout(t *testing.T) {
+	port, write := openPTY(t, 100*time.Millisecond)
+
+	start := time.Now()
+	n, err := port.Read(make([]byte, 8))
+	if !errors.Is(err, serial.ErrTimeout) || n != 0 {
+		t.Fatalf("Read() on a quiet port = %d, %v, want 0, ErrTimeout", n, err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Read() timed out after %s, want about 100ms", elapsed)
+	}
+
+	// the port keeps working after a timeout
+	write([]byte{0x01, 0x02})
+	buf := make([]byte, 8)
+	n, err = port.Read(buf)
+	if err != nil || n != 2 || buf[0] != 0x01 || buf[1] != 0x02 {
+		t.Errorf("Read() after a timeout = % X, %v, want 01 02", buf[:n], err)
+	}
+}
@@ -0,0 +1,33 @@
+// Package serialtest provides pseudo-terminals to test code talking to serial ports, the peer of the code under test
+// is emulated on the master side.
+package serialtest
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// OpenPTY opens a pseudo-terminal. The peer emulation reads and writes master, the code under test opens the tty at
+// path, e.g. with serial.Open.
+func OpenPTY() (master *os.File, path string, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fd := int(master.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		return nil, "", fmt.Errorf("serialtest: failed to unlock the pty: %w", err)
+	}
+
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		return nil, "", fmt.Errorf("serialtest: failed to get the pty number: %w", err)
+	}
+
+	return master, fmt.Sprintf("/dev/pts/%d", n), nil
+}
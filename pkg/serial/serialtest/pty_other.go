@@ -0,0 +1,15 @@
+//go:build !linux
+
+// Package serialtest provides pseudo-terminals to test code talking to serial ports, the peer of the code under test
+// is emulated on the master side.
+package serialtest
+
+import (
+	"errors"
+	"os"
+)
+
+// OpenPTY is only supported on linux
+func OpenPTY() (master *os.File, path string, err error) {
+	return nil, "", errors.New("serialtest: pseudo-terminals are only supported on linux")
+}